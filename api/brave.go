@@ -7,12 +7,18 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"mrktr/types"
 )
 
+const (
+	braveResultsPerPage = 20
+	braveMaxPages       = 10 // Brave accepts offsets 0-9
+)
+
 // BraveProvider implements SearchProvider via Brave Search.
 type BraveProvider struct {
 	apiKey    string
@@ -44,45 +50,76 @@ func (p *BraveProvider) Configured() bool {
 	return p != nil && p.apiKey != ""
 }
 
-func (p *BraveProvider) Search(ctx context.Context, query string) ([]types.Listing, error) {
+// Search pages through Brave results until the request depth is met, the
+// page cap is reached or Brave reports no further results.
+func (p *BraveProvider) Search(ctx context.Context, req SearchRequest) ([]types.Listing, error) {
 	if !p.Configured() {
 		return nil, fmt.Errorf("BRAVE_API_KEY not set")
 	}
 
-	searchQuery := fmt.Sprintf(
-		"%s price (site:ebay.com OR site:mercari.com OR site:amazon.com)",
-		query,
-	)
+	req = req.normalized()
+	pages := min(req.MaxRequests, braveMaxPages)
+
+	listings := make([]types.Listing, 0, req.Depth)
+	seen := map[string]struct{}{}
+	for page := 0; page < pages; page++ {
+		batch, more, err := p.fetchPage(ctx, req, page)
+		if err != nil {
+			if len(listings) > 0 {
+				break
+			}
+			return nil, err
+		}
+		listings = appendNewListings(listings, seen, batch)
+		req.reportProgress(p.Name(), len(listings), page+1)
+		if len(listings) >= req.Depth || !more {
+			break
+		}
+	}
+
+	return listings, nil
+}
+
+func (p *BraveProvider) fetchPage(ctx context.Context, search SearchRequest, page int) ([]types.Listing, bool, error) {
+	searchQuery := fmt.Sprintf("%s price", search.providerQuery())
 
 	searchURL, err := url.Parse(p.searchURL)
 	if err != nil {
-		return nil, fmt.Errorf("parse brave search URL: %w", err)
+		return nil, false, fmt.Errorf("parse brave search URL: %w", err)
 	}
 
 	params := searchURL.Query()
 	params.Set("q", searchQuery)
-	params.Set("count", "20")
+	params.Set("count", strconv.Itoa(braveResultsPerPage))
+	params.Set("offset", strconv.Itoa(page))
+	params.Set("extra_snippets", "true")
+	params.Set("country", search.Market.Country)
+	params.Set("search_lang", braveLanguage(search.Market.Language))
+	if freshness, ok := braveFreshness[search.freshness()]; ok {
+		params.Set("freshness", freshness)
+	}
+	params.Set("goggles", marketplaceGoggle(search.domains()))
 	searchURL.RawQuery = params.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("create brave request: %w", err)
+		return nil, false, fmt.Errorf("create brave request: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("X-Subscription-Token", p.apiKey)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request brave: %w", err)
+		return nil, false, fmt.Errorf("request brave: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read brave response: %w", err)
+		return nil, false, fmt.Errorf("read brave response: %w", err)
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, &HTTPStatusError{
+		return nil, false, &HTTPStatusError{
 			Provider: "Brave",
 			Status:   resp.StatusCode,
 			Body:     summarizeHTTPBody(body),
@@ -90,14 +127,107 @@ func (p *BraveProvider) Search(ctx context.Context, query string) ([]types.Listi
 	}
 
 	var result struct {
+		Query struct {
+			MoreResultsAvailable bool `json:"more_results_available"`
+		} `json:"query"`
 		Web struct {
-			Results []SearchResult `json:"results"`
+			Results []braveWebResult `json:"results"`
 		} `json:"web"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("decode brave response: %w", err)
+		return nil, false, fmt.Errorf("decode brave response: %w", err)
+	}
+
+	data := make([]SearchResult, len(result.Web.Results))
+	for i, r := range result.Web.Results {
+		data[i] = r.searchResult(search.Market.Currency)
+	}
+
+	return ParseSearchResultsWith(data, search.parseOptions()), result.Query.MoreResultsAvailable, nil
+}
+
+var braveFreshness = map[string]string{
+	freshnessDay:   "pd",
+	freshnessWeek:  "pw",
+	freshnessMonth: "pm",
+	freshnessYear:  "py",
+}
+
+// braveSearchLanguages maps ISO 639-1 codes to Brave's search_lang codes
+// where the two differ.
+var braveSearchLanguages = map[string]string{
+	"ja": "jp",
+}
+
+func braveLanguage(code string) string {
+	if lang, ok := braveSearchLanguages[code]; ok {
+		return lang
+	}
+	return code
+}
+
+type braveWebResult struct {
+	URL            string         `json:"url"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Age            string         `json:"age"`
+	PageAge        string         `json:"page_age"`
+	ExtraSnippets  []string       `json:"extra_snippets"`
+	Product        *braveProduct  `json:"product"`
+	ProductCluster []braveProduct `json:"product_cluster"`
+}
+
+type braveProduct struct {
+	Name   string       `json:"name"`
+	Price  string       `json:"price"`
+	Offers []braveOffer `json:"offers"`
+}
+
+type braveOffer struct {
+	URL           string `json:"url"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+}
+
+func (r braveWebResult) searchResult(currency string) SearchResult {
+	out := SearchResult{
+		URL:           r.URL,
+		Title:         r.Title,
+		Description:   r.Description,
+		ExtraSnippets: r.ExtraSnippets,
+		Age:           r.PageAge,
+	}
+	if out.Age == "" {
+		out.Age = r.Age
 	}
 
-	return ParseSearchResults(result.Web.Results), nil
+	products := r.ProductCluster
+	if r.Product != nil {
+		products = append([]braveProduct{*r.Product}, products...)
+	}
+	for _, product := range products {
+		if len(product.Offers) == 0 {
+			if price, ok := parseStructuredPrice(product.Price, "", currency); ok {
+				out.Prices = append(out.Prices, price)
+			}
+			continue
+		}
+		for _, offer := range product.Offers {
+			if price, ok := parseStructuredPrice(offer.Price, offer.PriceCurrency, currency); ok {
+				out.Prices = append(out.Prices, price)
+			}
+		}
+	}
+	return out
+}
+
+// marketplaceGoggle builds an inline Brave Goggle that keeps only the given domains.
+func marketplaceGoggle(domains []string) string {
+	lines := make([]string, 0, len(domains)+1)
+	lines = append(lines, "$discard")
+	for _, domain := range domains {
+		lines = append(lines, "$site="+domain)
+	}
+	return strings.Join(lines, "\n")
 }
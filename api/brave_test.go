@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSearchBraveChecksHTTPStatus(t *testing.T) {
@@ -21,7 +23,7 @@ func TestSearchBraveChecksHTTPStatus(t *testing.T) {
 
 	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
 
-	_, err := provider.Search(context.Background(), "ps5")
+	_, err := provider.Search(context.Background(), SearchRequest{Query: "ps5"})
 	if err == nil {
 		t.Fatal("expected error for non-2xx brave response")
 	}
@@ -33,6 +35,8 @@ func TestSearchBraveChecksHTTPStatus(t *testing.T) {
 func TestSearchBraveBuildsQueryAndParsesResults(t *testing.T) {
 	var gotPath string
 	var gotQuery string
+	var gotGoggles string
+	var gotSnippets string
 	var gotToken string
 	var gotEncoding string
 
@@ -40,6 +44,8 @@ func TestSearchBraveBuildsQueryAndParsesResults(t *testing.T) {
 		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
 			gotPath = req.URL.Path
 			gotQuery = req.URL.Query().Get("q")
+			gotGoggles = req.URL.Query().Get("goggles")
+			gotSnippets = req.URL.Query().Get("extra_snippets")
 			gotToken = req.Header.Get("X-Subscription-Token")
 			gotEncoding = req.Header.Get("Accept-Encoding")
 
@@ -65,7 +71,7 @@ func TestSearchBraveBuildsQueryAndParsesResults(t *testing.T) {
 
 	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
 
-	results, err := provider.Search(context.Background(), "switch")
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch"})
 	if err != nil {
 		t.Fatalf("expected successful brave search, got %v", err)
 	}
@@ -73,8 +79,14 @@ func TestSearchBraveBuildsQueryAndParsesResults(t *testing.T) {
 	if gotPath != "/res/v1/web/search" {
 		t.Fatalf("expected path %q, got %q", "/res/v1/web/search", gotPath)
 	}
-	if !strings.Contains(gotQuery, "site:ebay.com") {
-		t.Fatalf("expected marketplace site filters in query, got %q", gotQuery)
+	if strings.Contains(gotQuery, "site:") {
+		t.Fatalf("expected no site operators in query text, got %q", gotQuery)
+	}
+	if !strings.Contains(gotGoggles, "$site=ebay.com") || !strings.HasPrefix(gotGoggles, "$discard") {
+		t.Fatalf("expected marketplace goggle restricting domains, got %q", gotGoggles)
+	}
+	if gotSnippets != "true" {
+		t.Fatalf("expected extra_snippets=true, got %q", gotSnippets)
 	}
 	if gotToken != "brave-key" {
 		t.Fatalf("expected subscription token header, got %q", gotToken)
@@ -92,3 +104,224 @@ func TestSearchBraveBuildsQueryAndParsesResults(t *testing.T) {
 		t.Fatalf("expected parsed price 299.99, got %.2f", results[0].Price)
 	}
 }
+
+func TestSearchBraveUsesExtraSnippetsAndProductOffers(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{
+				"web": {
+					"results": [
+						{
+							"url": "https://www.ebay.com/itm/1",
+							"title": "Nintendo Switch OLED",
+							"description": "White console",
+							"extra_snippets": ["Buy It Now $275.00", "Free shipping"]
+						},
+						{
+							"url": "https://www.amazon.com/dp/B098RKWHHZ",
+							"title": "Nintendo Switch OLED Model",
+							"description": "Console with dock",
+							"product": {
+								"name": "Nintendo Switch OLED",
+								"offers": [
+									{"price": "349.99", "priceCurrency": "USD"},
+									{"price": "329.00", "priceCurrency": "USD"},
+									{"price": "299.00", "priceCurrency": "GBP"}
+								]
+							}
+						}
+					]
+				}
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
+
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch oled"})
+	if err != nil {
+		t.Fatalf("expected successful brave search, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 parsed listings, got %d", len(results))
+	}
+	if results[0].Price != 275 {
+		t.Fatalf("expected price from extra snippet 275, got %.2f", results[0].Price)
+	}
+	if results[1].Price != 329 {
+		t.Fatalf("expected lowest USD offer price 329, got %.2f", results[1].Price)
+	}
+}
+
+func TestSearchBravePagesUntilDepthAndReportsProgress(t *testing.T) {
+	var offsets []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			offset := req.URL.Query().Get("offset")
+			offsets = append(offsets, offset)
+
+			body := fmt.Sprintf(`{
+				"query": {"more_results_available": true},
+				"web": {
+					"results": [
+						{"url": "https://www.ebay.com/itm/%[1]s1", "title": "Switch $250", "description": "used"},
+						{"url": "https://www.ebay.com/itm/%[1]s2", "title": "Switch $260", "description": "used"},
+						{"url": "https://www.ebay.com/itm/%[1]s3", "title": "Switch", "description": "no price"}
+					]
+				}
+			}`, offset)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
+
+	var progress []SearchProgress
+	results, err := provider.Search(context.Background(), SearchRequest{
+		Query:       "switch",
+		Depth:       5,
+		MaxRequests: 5,
+		Progress: func(p SearchProgress) {
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected successful brave search, got %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected paging to stop once depth 5 is reached with 6 listings, got %d", len(results))
+	}
+	if strings.Join(offsets, ",") != "0,1,2" {
+		t.Fatalf("expected offsets 0,1,2, got %v", offsets)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected one progress report per page, got %d", len(progress))
+	}
+	last := progress[len(progress)-1]
+	if last.Provider != "Brave" || last.Listings != 6 || last.Page != 3 {
+		t.Fatalf("unexpected final progress report: %+v", last)
+	}
+}
+
+func TestSearchBraveRespectsPageCapAndMoreResultsFlag(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			body := `{
+				"query": {"more_results_available": false},
+				"web": {"results": [{"url": "https://www.ebay.com/itm/1", "title": "Switch $250", "description": "used"}]}
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
+
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch", Depth: 50, MaxRequests: 4})
+	if err != nil {
+		t.Fatalf("expected successful brave search, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected paging to stop when no more results are available, got %d calls", calls)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 listing, got %d", len(results))
+	}
+}
+
+func TestSearchBraveAppliesQuerySyntax(t *testing.T) {
+	var gotQuery string
+	var gotGoggles string
+	var gotCountry string
+	var gotLang string
+
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			gotQuery = req.URL.Query().Get("q")
+			gotGoggles = req.URL.Query().Get("goggles")
+			gotCountry = req.URL.Query().Get("country")
+			gotLang = req.URL.Query().Get("search_lang")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"web":{"results":[]}}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
+	req := SearchRequest{
+		Query:    "switch",
+		Phrases:  []string{"oled model"},
+		Exclude:  []string{"broken"},
+		Platform: "Mercari",
+	}
+	if _, err := provider.Search(context.Background(), req); err != nil {
+		t.Fatalf("expected successful brave search, got %v", err)
+	}
+
+	if gotQuery != `switch "oled model" -broken price` {
+		t.Fatalf("expected phrase and exclusion in query text, got %q", gotQuery)
+	}
+	if gotGoggles != "$discard\n$site=mercari.com" {
+		t.Fatalf("expected goggle restricted to selected platform, got %q", gotGoggles)
+	}
+	if gotCountry != "US" || gotLang != "en" {
+		t.Fatalf("expected default market locale, got country=%q lang=%q", gotCountry, gotLang)
+	}
+}
+
+func TestSearchBraveSendsFreshnessAndReadsPageAge(t *testing.T) {
+	var gotFreshness string
+
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			gotFreshness = req.URL.Query().Get("freshness")
+			body := `{
+				"web": {
+					"results": [
+						{
+							"url": "https://ebay.com/itm/1",
+							"title": "Switch OLED $250",
+							"age": "3 days ago",
+							"page_age": "2026-03-01T09:00:00"
+						}
+					]
+				}
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewBraveProvider("brave-key", "https://brave.test/res/v1/web/search", client)
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch", MaxAge: 7 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("expected successful brave search, got %v", err)
+	}
+
+	if gotFreshness != "pw" {
+		t.Fatalf("expected past-week freshness, got %q", gotFreshness)
+	}
+	want := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if len(results) != 1 || !results[0].Date.Equal(want) {
+		t.Fatalf("expected listing dated from page_age %v, got %+v", want, results)
+	}
+}
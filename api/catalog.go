@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CatalogEntryError reports one invalid entry in a catalog file.
+type CatalogEntryError struct {
+	Path  string
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *CatalogEntryError) Error() string {
+	where := fmt.Sprintf("%s[%d]", filepath.Base(e.Path), e.Index)
+	if e.Name != "" {
+		where += fmt.Sprintf(" %q", e.Name)
+	}
+	return where + ": " + e.Err.Error()
+}
+
+func (e *CatalogEntryError) Unwrap() error {
+	return e.Err
+}
+
+// LoadCatalogFile reads a JSON array of product entries. Invalid entries are
+// skipped and reported one error each; err is set only when the file cannot
+// be read or decoded. A missing file is an empty catalog.
+func LoadCatalogFile(path string) (entries []ProductEntry, entryErrs []error, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("read catalog: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, nil, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("decode catalog %s: %w", path, err)
+	}
+
+	seen := map[string]int{}
+	for i, message := range raw {
+		var entry ProductEntry
+		if err := json.Unmarshal(message, &entry); err != nil {
+			entryErrs = append(entryErrs, &CatalogEntryError{Path: path, Index: i, Err: err})
+			continue
+		}
+		entry = sanitizeEntry(entry)
+		if err := validateCatalogEntry(entry); err != nil {
+			entryErrs = append(entryErrs, &CatalogEntryError{Path: path, Index: i, Name: entry.Name, Err: err})
+			continue
+		}
+		key := strings.ToLower(entry.Name)
+		if first, ok := seen[key]; ok {
+			entryErrs = append(entryErrs, &CatalogEntryError{Path: path, Index: i, Name: entry.Name, Err: fmt.Errorf("duplicates entry %d", first)})
+			continue
+		}
+		seen[key] = i
+		entries = append(entries, entry)
+	}
+	return entries, entryErrs, nil
+}
+
+func validateCatalogEntry(entry ProductEntry) error {
+	if entry.Name == "" {
+		return errors.New("name is required")
+	}
+	if entry.Disabled {
+		return nil
+	}
+	if len(tokenize(entry.Name)) == 0 && len(tokenize(strings.Join(entry.Synonyms, " "))) == 0 {
+		return errors.New("name and synonyms have no searchable words")
+	}
+	if err := validateRetail(entry); err != nil {
+		return err
+	}
+	if err := validateTerms(entry); err != nil {
+		return err
+	}
+	return validateIdentifiers(entry.Identifiers)
+}
+
+// MergeCatalogs layers catalogs in order. An entry replaces any earlier entry
+// with the same name (case-insensitive); a disabled entry removes it.
+func MergeCatalogs(layers ...[]ProductEntry) []ProductEntry {
+	var out []ProductEntry
+	position := map[string]int{}
+	for _, layer := range layers {
+		for _, entry := range layer {
+			key := strings.ToLower(strings.TrimSpace(entry.Name))
+			if key == "" {
+				continue
+			}
+			i, exists := position[key]
+			switch {
+			case entry.Disabled && exists:
+				out[i].Disabled = true
+			case entry.Disabled:
+			case exists:
+				out[i] = entry
+			default:
+				position[key] = len(out)
+				out = append(out, entry)
+			}
+		}
+	}
+
+	merged := out[:0]
+	for _, entry := range out {
+		if !entry.Disabled {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// SaveCatalogEntry adds entry to the catalog file at path, replacing an
+// entry with the same name.
+func SaveCatalogEntry(path string, entry ProductEntry) error {
+	entry = sanitizeEntry(entry)
+	if err := validateCatalogEntry(entry); err != nil {
+		return err
+	}
+
+	var existing []ProductEntry
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil && strings.TrimSpace(string(data)) != "":
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("decode catalog %s: %w", path, err)
+		}
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("read catalog: %w", err)
+	}
+
+	replaced := false
+	for i := range existing {
+		if strings.EqualFold(strings.TrimSpace(existing[i].Name), entry.Name) {
+			existing[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		existing = append(existing, entry)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create catalog directory: %w", err)
+	}
+	body, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode catalog: %w", err)
+	}
+	body = append(body, '\n')
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return fmt.Errorf("write catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	return path
+}
+
+func TestLoadCatalogFileReportsInvalidEntries(t *testing.T) {
+	path := writeCatalog(t, `[
+  {"name": "Pyrex 443 Primary Bowl", "category": "Kitchen", "synonyms": ["pyrex 443", "pyrex primary"]},
+  {"category": "Kitchen", "synonyms": ["nameless"]},
+  {"name": "LEGO 10497", "synonyms": "galaxy explorer"},
+  {"name": "pyrex 443 primary bowl", "synonyms": ["dup"]},
+  {"name": "!!!"}
+]`)
+
+	entries, entryErrs, err := LoadCatalogFile(path)
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "Pyrex 443 Primary Bowl" {
+		t.Fatalf("expected only the valid entry, got %+v", entries)
+	}
+	if len(entryErrs) != 4 {
+		t.Fatalf("expected 4 entry errors, got %v", entryErrs)
+	}
+	for i, want := range []string{
+		"catalog.json[1]: name is required",
+		"catalog.json[2]: json:",
+		`catalog.json[3] "pyrex 443 primary bowl": duplicates entry 0`,
+		`catalog.json[4] "!!!": name and synonyms have no searchable words`,
+	} {
+		if !strings.HasPrefix(entryErrs[i].Error(), want) {
+			t.Fatalf("error %d: expected prefix %q, got %q", i, want, entryErrs[i])
+		}
+	}
+}
+
+func TestLoadCatalogFileMissingAndMalformed(t *testing.T) {
+	entries, entryErrs, err := LoadCatalogFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || entries != nil || entryErrs != nil {
+		t.Fatalf("expected missing catalog to be empty, got %v %v %v", entries, entryErrs, err)
+	}
+	if _, _, err := LoadCatalogFile(writeCatalog(t, `{"name": "not an array"}`)); err == nil {
+		t.Fatal("expected malformed catalog to fail")
+	}
+}
+
+func TestMergeCatalogsOverridesAndDisables(t *testing.T) {
+	base := []ProductEntry{
+		{Name: "Nintendo Switch OLED", Synonyms: []string{"switch"}},
+		{Name: "Xbox Series S", Synonyms: []string{"xbox s"}},
+	}
+	user := []ProductEntry{
+		{Name: "nintendo switch oled", Synonyms: []string{"switch oled"}},
+		{Name: "Xbox Series S", Disabled: true},
+		{Name: "Air Jordan 1 Chicago", Synonyms: []string{"jordan 1 chicago"}},
+	}
+
+	got := MergeCatalogs(base, user)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 products, got %+v", got)
+	}
+	if got[0].Name != "nintendo switch oled" || got[0].Synonyms[0] != "switch oled" {
+		t.Fatalf("expected user entry to replace the embedded one in place, got %+v", got[0])
+	}
+	if got[1].Name != "Air Jordan 1 Chicago" {
+		t.Fatalf("expected new user product appended, got %+v", got[1])
+	}
+}
+
+func TestNewProductIndexLayersCatalogFiles(t *testing.T) {
+	path := writeCatalog(t, `[
+  {"name": "Pyrex 443 Primary Bowl", "category": "Kitchen", "synonyms": ["pyrex 443", "pyrex primary"]},
+  {"name": "PlayStation 5 Console", "disabled": true},
+  {"synonyms": ["broken"]}
+]`)
+
+	idx := NewProductIndex(path)
+	if got := idx.Expand("pyrex 443"); got != "Pyrex 443 Primary Bowl" {
+		t.Fatalf("expected user product expansion, got %q", got)
+	}
+	for _, suggestion := range idx.Suggest("playstation 5") {
+		if suggestion == "PlayStation 5 Console" {
+			t.Fatalf("expected disabled product to be gone, got %v", idx.Suggest("playstation 5"))
+		}
+	}
+	if errs := idx.CatalogErrors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "name is required") {
+		t.Fatalf("expected one catalog error, got %v", errs)
+	}
+	if idx.Len() != NewProductIndex().Len() {
+		t.Fatalf("expected one added and one disabled product, got %d vs %d", idx.Len(), NewProductIndex().Len())
+	}
+}
+
+func TestSaveCatalogEntryReplacesByName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "catalog.json")
+	if err := SaveCatalogEntry(path, ProductEntry{Name: "LEGO 10497", Synonyms: []string{"galaxy explorer"}}); err != nil {
+		t.Fatalf("save entry: %v", err)
+	}
+	if err := SaveCatalogEntry(path, ProductEntry{Name: "lego 10497", Synonyms: []string{"lego galaxy explorer", " "}}); err != nil {
+		t.Fatalf("replace entry: %v", err)
+	}
+	if err := SaveCatalogEntry(path, ProductEntry{Name: " "}); err == nil {
+		t.Fatal("expected entry without a name to be rejected")
+	}
+
+	entries, entryErrs, err := LoadCatalogFile(path)
+	if err != nil || len(entryErrs) != 0 {
+		t.Fatalf("reload catalog: %v %v", err, entryErrs)
+	}
+	if len(entries) != 1 || entries[0].Name != "lego 10497" || len(entries[0].Synonyms) != 1 {
+		t.Fatalf("expected replaced entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+// uncategorized names the category of products that have none.
+const uncategorized = "Uncategorized"
+
+// Category is a catalog category and how many products it holds.
+type Category struct {
+	Name     string
+	Products int
+}
+
+// Categories returns the catalog categories alphabetically,
+// case-insensitively merged. Products without a category are listed under
+// "Uncategorized".
+func (idx *ProductIndex) Categories() []Category {
+	if idx == nil {
+		return nil
+	}
+	var out []Category
+	position := map[string]int{}
+	for _, product := range idx.products {
+		name := categoryName(product.entry)
+		key := strings.ToLower(name)
+		i, ok := position[key]
+		if !ok {
+			i = len(out)
+			position[key] = i
+			out = append(out, Category{Name: name})
+		}
+		out[i].Products++
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out
+}
+
+// CategoryProducts returns the products in category, case-insensitively,
+// in catalog order.
+func (idx *ProductIndex) CategoryProducts(category string) []ProductEntry {
+	if idx == nil {
+		return nil
+	}
+	category = strings.TrimSpace(category)
+	var out []ProductEntry
+	for _, product := range idx.products {
+		if strings.EqualFold(categoryName(product.entry), category) {
+			out = append(out, product.entry)
+		}
+	}
+	return out
+}
+
+func categoryName(entry ProductEntry) string {
+	if entry.Category == "" {
+		return uncategorized
+	}
+	return entry.Category
+}
@@ -0,0 +1,34 @@
+package api
+
+import "testing"
+
+func TestProductIndexCategories(t *testing.T) {
+	idx := newProductIndexFromEntries([]ProductEntry{
+		{Name: "Nintendo Switch OLED", Category: "Gaming", Synonyms: []string{"switch oled"}},
+		{Name: "AirPods Pro 2", Category: "Audio"},
+		{Name: "Steam Deck OLED", Category: "gaming"},
+		{Name: "Pyrex 443 Primary Bowl"},
+	})
+
+	got := idx.Categories()
+	want := []Category{{Name: "Audio", Products: 1}, {Name: "Gaming", Products: 2}, {Name: uncategorized, Products: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("category %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	products := idx.CategoryProducts("GAMING")
+	if len(products) != 2 || products[0].Name != "Nintendo Switch OLED" || products[1].Name != "Steam Deck OLED" {
+		t.Fatalf("expected gaming products in catalog order, got %+v", products)
+	}
+	if products := idx.CategoryProducts(uncategorized); len(products) != 1 || products[0].Name != "Pyrex 443 Primary Bowl" {
+		t.Fatalf("expected uncategorized product, got %+v", products)
+	}
+	if products := idx.CategoryProducts("Kitchen"); len(products) != 0 {
+		t.Fatalf("expected no products, got %+v", products)
+	}
+}
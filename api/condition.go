@@ -0,0 +1,45 @@
+package api
+
+import (
+	"regexp"
+
+	"mrktr/types"
+)
+
+// conditionRule maps a snippet phrase to a condition. Rules are checked in
+// order, so phrases that override others ("new screen, for parts") come first.
+type conditionRule struct {
+	pattern    *regexp.Regexp
+	condition  string
+	confidence float64
+}
+
+var conditionRules = []conditionRule{
+	{regexp.MustCompile(`\b(?:for\s+parts|parts\s+only|not\s+working|doesn'?t\s+work|does\s+not\s+work|broken|as[\s-]is)\b`), types.ConditionForParts, 0.9},
+	{regexp.MustCompile(`\buntested\b`), types.ConditionForParts, 0.6},
+	{regexp.MustCompile(`\b(?:certified\s+refurbished|manufacturer\s+refurbished|certified\s+renewed)\b`), types.ConditionCertifiedRefurbished, 0.95},
+	{regexp.MustCompile(`\b(?:seller\s+refurbished|refurbished|refurb)\b`), types.ConditionRefurbished, 0.85},
+	{regexp.MustCompile(`\bopen(?:ed)?[\s-]box\b`), types.ConditionOpenBox, 0.9},
+	{regexp.MustCompile(`\b(?:like\s+new|mint\s+condition|near\s+mint)\b`), types.ConditionLikeNew, 0.85},
+	{regexp.MustCompile(`\b(?:factory\s+sealed|sealed|nib|new\s+in\s+box)\b`), types.ConditionSealed, 0.85},
+	{regexp.MustCompile(`\b(?:brand\s+new)\b`), types.ConditionNew, 0.85},
+	{regexp.MustCompile(`\bnew\b`), types.ConditionNew, 0.6},
+	{regexp.MustCompile(`\b(?:very\s+good|good)\b`), types.ConditionGood, 0.6},
+	{regexp.MustCompile(`\b(?:fair|acceptable|heavily\s+used)\b`), types.ConditionFair, 0.6},
+	{regexp.MustCompile(`\b(?:pre-owned|preowned|used)\b`), types.ConditionUsed, 0.8},
+}
+
+// defaultConditionConfidence applies when no phrase names a condition and
+// the listing is assumed to be used.
+const defaultConditionConfidence = 0.3
+
+// classifyCondition returns the condition named in lower-cased snippet text
+// and how confident the match is.
+func classifyCondition(textLower string) (string, float64) {
+	for _, rule := range conditionRules {
+		if rule.pattern.MatchString(textLower) {
+			return rule.condition, rule.confidence
+		}
+	}
+	return types.ConditionUsed, defaultConditionConfidence
+}
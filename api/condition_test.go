@@ -0,0 +1,41 @@
+package api
+
+import "testing"
+
+func TestClassifyCondition(t *testing.T) {
+	tests := []struct {
+		text          string
+		want          string
+		minConfidence float64
+	}{
+		{text: "ps5 slim for parts, new screen", want: "For Parts", minConfidence: 0.9},
+		{text: "console not working, powers on", want: "For Parts", minConfidence: 0.9},
+		{text: "untested, sold as found", want: "For Parts", minConfidence: 0.5},
+		{text: "certified refurbished with warranty", want: "Certified Refurbished", minConfidence: 0.9},
+		{text: "seller refurbished ps5", want: "Refurbished", minConfidence: 0.8},
+		{text: "open box, never used", want: "Open Box", minConfidence: 0.9},
+		{text: "like new condition", want: "Like New", minConfidence: 0.8},
+		{text: "factory sealed new in box", want: "Sealed", minConfidence: 0.8},
+		{text: "brand new ps5", want: "New", minConfidence: 0.8},
+		{text: "very good condition", want: "Good", minConfidence: 0.5},
+		{text: "acceptable, heavy scratches", want: "Fair", minConfidence: 0.5},
+		{text: "pre-owned console", want: "Used", minConfidence: 0.8},
+	}
+
+	for _, tc := range tests {
+		got, confidence := classifyCondition(tc.text)
+		if got != tc.want {
+			t.Fatalf("classifyCondition(%q): expected %q, got %q", tc.text, tc.want, got)
+		}
+		if confidence < tc.minConfidence || confidence > 1 {
+			t.Fatalf("classifyCondition(%q): expected confidence >= %.2f, got %.2f", tc.text, tc.minConfidence, confidence)
+		}
+	}
+}
+
+func TestClassifyConditionDefaultsToLowConfidenceUsed(t *testing.T) {
+	got, confidence := classifyCondition("ps5 slim 1tb $420")
+	if got != "Used" || confidence != defaultConditionConfidence {
+		t.Fatalf("expected Used at default confidence, got %q %.2f", got, confidence)
+	}
+}
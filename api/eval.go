@@ -0,0 +1,220 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CorpusLabel is the user-verified truth for one captured search result.
+type CorpusLabel struct {
+	Price     float64 `json:"price"`
+	Condition string  `json:"condition,omitempty"`
+	Status    string  `json:"status,omitempty"`
+}
+
+// CorpusEntry is one labeled provider payload in the parser corpus.
+type CorpusEntry struct {
+	Result     SearchResult `json:"result"`
+	Currency   string       `json:"currency,omitempty"` // market currency the result was parsed in
+	CapturedAt time.Time    `json:"captured_at"`
+	Label      CorpusLabel  `json:"label"`
+}
+
+// ID identifies the entry across corpus edits and evaluation runs.
+func (e CorpusEntry) ID() string {
+	if key := IdentifyListing(e.Result.URL).Key(); key != "" {
+		return key
+	}
+	return strings.TrimSpace(e.Result.URL)
+}
+
+// FieldScore counts extraction outcomes for one field.
+type FieldScore struct {
+	Labeled   int `json:"labeled"`   // entries labeled with a value
+	Predicted int `json:"predicted"` // entries the parser produced a value for
+	Correct   int `json:"correct"`   // predictions matching the label
+}
+
+// Precision is the share of predictions that were correct.
+func (s FieldScore) Precision() float64 {
+	if s.Predicted == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Predicted)
+}
+
+// Recall is the share of labeled values the parser got right.
+func (s FieldScore) Recall() float64 {
+	if s.Labeled == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Labeled)
+}
+
+// EntryOutcome records what the parser produced for one corpus entry.
+type EntryOutcome struct {
+	ID           string  `json:"id"`
+	Price        float64 `json:"price"`
+	PricePattern string  `json:"price_pattern,omitempty"` // source/pattern of the chosen candidate
+	Condition    string  `json:"condition,omitempty"`
+	Status       string  `json:"status,omitempty"`
+	PriceOK      bool    `json:"price_ok"`
+	ConditionOK  bool    `json:"condition_ok"`
+	StatusOK     bool    `json:"status_ok"`
+}
+
+// EvalReport summarizes parser accuracy over a corpus.
+type EvalReport struct {
+	Entries   int                   `json:"entries"`
+	Price     FieldScore            `json:"price"`
+	Condition FieldScore            `json:"condition"`
+	Status    FieldScore            `json:"status"`
+	Patterns  map[string]FieldScore `json:"patterns"` // price scores per source/pattern
+	Outcomes  []EntryOutcome        `json:"outcomes"`
+}
+
+// PatternNames returns the report's price pattern keys in sorted order.
+func (r EvalReport) PatternNames() []string {
+	names := make([]string, 0, len(r.Patterns))
+	for name := range r.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EvaluateCorpus re-parses every corpus entry and scores price, condition
+// and status against the labels. For price patterns, Predicted counts how
+// often the pattern's candidate was chosen and Labeled how often the pattern
+// found the labeled price among its candidates. Rules, when non-nil, are
+// applied as they would be during a search.
+func EvaluateCorpus(entries []CorpusEntry, rules *ParseRules) EvalReport {
+	report := EvalReport{
+		Entries:  len(entries),
+		Patterns: map[string]FieldScore{},
+		Outcomes: make([]EntryOutcome, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		outcome := EntryOutcome{ID: entry.ID()}
+		label := entry.Label
+		if label.Price > 0 {
+			report.Price.Labeled++
+		}
+		if label.Condition != "" {
+			report.Condition.Labeled++
+		}
+		if label.Status != "" {
+			report.Status.Labeled++
+		}
+
+		parsed := ParseSearchResultsWith([]SearchResult{entry.Result}, ParseOptions{
+			Currency: entry.Currency,
+			Now:      entry.CapturedAt,
+			Rules:    rules,
+		})
+		if len(parsed) == 0 {
+			report.Outcomes = append(report.Outcomes, outcome)
+			continue
+		}
+		listing := parsed[0]
+
+		outcome.Price = listing.Price
+		outcome.Condition = listing.Condition
+		outcome.Status = listing.Status
+		outcome.PriceOK = label.Price > 0 && samePrice(listing.Price, label.Price)
+		outcome.ConditionOK = label.Condition != "" && strings.EqualFold(listing.Condition, label.Condition)
+		outcome.StatusOK = label.Status != "" && strings.EqualFold(listing.Status, label.Status)
+
+		report.Price.Predicted++
+		report.Condition.Predicted++
+		report.Status.Predicted++
+		if outcome.PriceOK {
+			report.Price.Correct++
+		}
+		if outcome.ConditionOK {
+			report.Condition.Correct++
+		}
+		if outcome.StatusOK {
+			report.Status.Correct++
+		}
+
+		found := map[string]bool{}
+		for _, candidate := range listing.PriceCandidates {
+			name := candidate.Source + "/" + candidate.Pattern
+			if outcome.PricePattern == "" && samePrice(candidate.Amount, listing.Price) {
+				outcome.PricePattern = name
+			}
+			if label.Price > 0 && samePrice(candidate.Amount, label.Price) {
+				found[name] = true
+			}
+		}
+		for name := range found {
+			score := report.Patterns[name]
+			score.Labeled++
+			report.Patterns[name] = score
+		}
+		if outcome.PricePattern != "" {
+			score := report.Patterns[outcome.PricePattern]
+			score.Predicted++
+			if outcome.PriceOK {
+				score.Correct++
+			}
+			report.Patterns[outcome.PricePattern] = score
+		}
+
+		report.Outcomes = append(report.Outcomes, outcome)
+	}
+
+	return report
+}
+
+func samePrice(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+// EvalChange is one field whose correctness changed between two runs.
+type EvalChange struct {
+	ID     string
+	Field  string // "price", "condition" or "status"
+	Before string
+	After  string
+	Fixed  bool // true when the field went from wrong to right
+}
+
+// DiffReports lists fields that became right or wrong since the previous
+// report. Entries missing from either report are skipped.
+func DiffReports(previous, current EvalReport) []EvalChange {
+	before := make(map[string]EntryOutcome, len(previous.Outcomes))
+	for _, outcome := range previous.Outcomes {
+		before[outcome.ID] = outcome
+	}
+
+	var changes []EvalChange
+	for _, after := range current.Outcomes {
+		prev, ok := before[after.ID]
+		if !ok {
+			continue
+		}
+		if prev.PriceOK != after.PriceOK {
+			changes = append(changes, EvalChange{ID: after.ID, Field: "price", Before: formatEvalPrice(prev.Price), After: formatEvalPrice(after.Price), Fixed: after.PriceOK})
+		}
+		if prev.ConditionOK != after.ConditionOK {
+			changes = append(changes, EvalChange{ID: after.ID, Field: "condition", Before: prev.Condition, After: after.Condition, Fixed: after.ConditionOK})
+		}
+		if prev.StatusOK != after.StatusOK {
+			changes = append(changes, EvalChange{ID: after.ID, Field: "status", Before: prev.Status, After: after.Status, Fixed: after.StatusOK})
+		}
+	}
+	return changes
+}
+
+func formatEvalPrice(price float64) string {
+	if price <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%.2f", price)
+}
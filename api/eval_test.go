@@ -0,0 +1,72 @@
+package api
+
+import "testing"
+
+func TestEvaluateCorpusScoresFieldsAndPatterns(t *testing.T) {
+	entries := []CorpusEntry{
+		{
+			Result: SearchResult{URL: "https://www.ebay.com/itm/111111111111", Title: "Switch OLED", Description: "Sold for $250.00 used"},
+			Label:  CorpusLabel{Price: 250, Condition: "Used", Status: "Sold"},
+		},
+		{
+			// The parser picks the shipping cost; the label says the asking price.
+			Result: SearchResult{URL: "https://www.ebay.com/itm/222222222222", Title: "Switch OLED", Description: "price 300, shipping $15"},
+			Label:  CorpusLabel{Price: 300, Condition: "Used", Status: "Active"},
+		},
+		{
+			Result: SearchResult{URL: "https://www.ebay.com/itm/333333333333", Title: "Switch OLED", Description: "no amount"},
+			Label:  CorpusLabel{Price: 280, Status: "Active"},
+		},
+	}
+
+	report := EvaluateCorpus(entries, nil)
+	if report.Entries != 3 {
+		t.Fatalf("expected 3 entries, got %d", report.Entries)
+	}
+	if report.Price != (FieldScore{Labeled: 3, Predicted: 2, Correct: 1}) {
+		t.Fatalf("unexpected price score: %+v", report.Price)
+	}
+	if got := report.Price.Precision(); got != 0.5 {
+		t.Fatalf("expected price precision 0.5, got %v", got)
+	}
+	if report.Status != (FieldScore{Labeled: 3, Predicted: 2, Correct: 2}) {
+		t.Fatalf("unexpected status score: %+v", report.Status)
+	}
+	if report.Condition.Labeled != 2 {
+		t.Fatalf("expected 2 condition labels, got %+v", report.Condition)
+	}
+
+	symbol := report.Patterns["snippet/symbol-prefix"]
+	if symbol.Predicted != 2 || symbol.Correct != 1 {
+		t.Fatalf("unexpected symbol-prefix score: %+v", symbol)
+	}
+	context := report.Patterns["snippet/context"]
+	if context.Labeled != 1 || context.Predicted != 0 {
+		t.Fatalf("expected context pattern to have found the missed price, got %+v", context)
+	}
+	if names := report.PatternNames(); len(names) != 2 || names[0] != "snippet/context" {
+		t.Fatalf("unexpected pattern names: %v", names)
+	}
+}
+
+func TestDiffReportsListsFixesAndRegressions(t *testing.T) {
+	previous := EvalReport{Outcomes: []EntryOutcome{
+		{ID: "ebay:1", Price: 15, PriceOK: false, Status: "Sold", StatusOK: true},
+		{ID: "ebay:2", Price: 80, PriceOK: true},
+	}}
+	current := EvalReport{Outcomes: []EntryOutcome{
+		{ID: "ebay:1", Price: 300, PriceOK: true, Status: "Active", StatusOK: false},
+		{ID: "ebay:3", Price: 10},
+	}}
+
+	changes := DiffReports(previous, current)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Field != "price" || !changes[0].Fixed || changes[0].Before != "15.00" || changes[0].After != "300.00" {
+		t.Fatalf("unexpected price change: %+v", changes[0])
+	}
+	if changes[1].Field != "status" || changes[1].Fixed {
+		t.Fatalf("unexpected status change: %+v", changes[1])
+	}
+}
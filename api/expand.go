@@ -0,0 +1,197 @@
+package api
+
+import (
+	"strings"
+)
+
+// maxExpansionCandidates is how many scored products Explain reports.
+const maxExpansionCandidates = 4
+
+// ExpansionRule pins how one query is expanded: never, or always to Target.
+type ExpansionRule struct {
+	Query  string `json:"query"`
+	Never  bool   `json:"never,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// ExpansionCandidate is a product Expand considered for a query.
+type ExpansionCandidate struct {
+	Name  string
+	Score float64
+}
+
+// Expansion explains what Expand did with a query.
+type Expansion struct {
+	Query      string
+	Expanded   string               // what is searched; Query when left alone
+	Rule       *ExpansionRule       // the pinned rule that decided, if any
+	ByCode     bool                 // the query was a product code resolved by Lookup
+	Candidates []ExpansionCandidate // best-scoring products, best first
+}
+
+// Changed reports whether the query was rewritten.
+func (e Expansion) Changed() bool {
+	return e.Expanded != e.Query
+}
+
+// ExpansionKey normalizes a query for matching expansion rules: case and
+// spacing are ignored.
+func ExpansionKey(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// SetExpansionRules replaces the rules Expand consults before scoring. Rules
+// without a query, or with neither Never nor a Target, are ignored.
+func (idx *ProductIndex) SetExpansionRules(rules []ExpansionRule) {
+	if idx == nil {
+		return
+	}
+	idx.rules = make(map[string]ExpansionRule, len(rules))
+	for _, rule := range rules {
+		key := ExpansionKey(rule.Query)
+		rule.Target = strings.TrimSpace(rule.Target)
+		if key == "" || (!rule.Never && rule.Target == "") {
+			continue
+		}
+		idx.rules[key] = rule
+	}
+}
+
+// Explain expands query like Expand and reports the rule that applied and
+// the products that were scored, so the rewrite can be shown and undone.
+func (idx *ProductIndex) Explain(query string) Expansion {
+	trimmed := strings.TrimSpace(query)
+	out := Expansion{Query: trimmed, Expanded: trimmed}
+	if idx == nil || trimmed == "" {
+		return out
+	}
+
+	// Codes resolve before scoring, unless a rule pins the query.
+	if _, pinned := idx.rules[ExpansionKey(trimmed)]; !pinned {
+		if name, ok := idx.Lookup(trimmed); ok {
+			out.Expanded = name
+			out.ByCode = true
+			return out
+		}
+	}
+
+	scored := idx.scoreExpansion(trimmed, max(2, maxExpansionCandidates))
+	out.Candidates = scored[:min(len(scored), maxExpansionCandidates)]
+
+	if rule, ok := idx.rules[ExpansionKey(trimmed)]; ok {
+		out.Rule = &rule
+		if !rule.Never {
+			out.Expanded = rule.Target
+		}
+		return out
+	}
+
+	if len(scored) == 0 || scored[0].Score < minExpandScore {
+		return out
+	}
+	second := 0.0
+	if len(scored) > 1 {
+		second = scored[1].Score
+	}
+	if scored[0].Score-second < minExpandSeparation {
+		return out
+	}
+	if strings.EqualFold(trimmed, scored[0].Name) {
+		return out
+	}
+	out.Expanded = scored[0].Name
+	return out
+}
+
+// scoreExpansion scores every product against query by TF-IDF similarity,
+// with bonuses for an exact name or synonym and for a synonym the query
+// starts, and returns the best limit. Products scoring zero are left out.
+func (idx *ProductIndex) scoreExpansion(query string, limit int) []ExpansionCandidate {
+	if len(idx.products) == 0 {
+		return nil
+	}
+	tokens := tokenize(query)
+	if len(tokens) == 0 || len(tokens) > maxExpandTokens {
+		return nil
+	}
+
+	terms, corrected := idx.correctTerms(tokens, false)
+	queryVector := normalizeVector(weightedVector(termWeights(terms), idx.idf))
+	if len(queryVector) == 0 {
+		return nil
+	}
+
+	queryLower := strings.ToLower(query)
+	correctedKey := ""
+	if corrected {
+		correctedKey = correctedQueryKey(terms)
+	}
+
+	// Bonuses need the query to be a name or start a synonym; every other
+	// product that scores shares a term with the query.
+	scores := idx.acquireScores()
+	defer idx.releaseScores(scores)
+	idx.similarities(queryVector, scores)
+	idx.prefixCandidates(queryLower, false, scores)
+
+	type scored struct {
+		ExpansionCandidate
+		product int
+	}
+	// Ties keep catalog order.
+	better := func(a, b scored) bool {
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.product < b.product
+	}
+	ranked := make([]scored, 0, limit)
+	for _, i := range scores.touched {
+		score := scores.score(i)
+		product := &idx.products[i]
+		// A name or synonym the query equals or starts was marked by
+		// prefixCandidates; otherwise only a corrected query can match one.
+		if scores.marked(i)&prefixMark != 0 || correctedKey != "" {
+			score += expansionBonus(product, queryLower, correctedKey)
+		}
+		if score <= 0 {
+			continue
+		}
+		c := scored{ExpansionCandidate{Name: product.entry.Name, Score: score}, i}
+		if len(ranked) == limit {
+			if !better(c, ranked[limit-1]) {
+				continue
+			}
+			ranked = ranked[:limit-1]
+		}
+		pos := len(ranked)
+		ranked = append(ranked, c)
+		for ; pos > 0 && better(ranked[pos], ranked[pos-1]); pos-- {
+			ranked[pos], ranked[pos-1] = ranked[pos-1], ranked[pos]
+		}
+	}
+
+	out := make([]ExpansionCandidate, len(ranked))
+	for i, candidate := range ranked {
+		out[i] = candidate.ExpansionCandidate
+	}
+	return out
+}
+
+// expansionBonus rewards a query that is the product's name or one of its
+// synonyms, or that starts a synonym.
+func expansionBonus(product *productDocument, queryLower, correctedKey string) float64 {
+	bonus := 0.0
+	if queryLower == product.nameLower {
+		bonus += 0.50
+	}
+	for i, synonym := range product.synonymsLower {
+		if queryLower == synonym || (correctedKey != "" && correctedKey == product.synonymKeys[i]) {
+			return bonus + 0.50
+		}
+		if len(queryLower) >= 3 && strings.HasPrefix(synonym, queryLower) {
+			return bonus + 0.10
+		}
+	}
+	return bonus
+}
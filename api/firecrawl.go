@@ -13,6 +13,8 @@ import (
 	"mrktr/types"
 )
 
+const firecrawlMaxResults = 100
+
 // FirecrawlProvider implements SearchProvider via Firecrawl.
 type FirecrawlProvider struct {
 	apiKey    string
@@ -43,31 +45,41 @@ func (p *FirecrawlProvider) Configured() bool {
 	return p != nil && p.apiKey != ""
 }
 
-func (p *FirecrawlProvider) Search(ctx context.Context, query string) ([]types.Listing, error) {
+// Search issues one Firecrawl request. Firecrawl has no result offsets, so
+// depth only raises the result limit up to the API ceiling.
+func (p *FirecrawlProvider) Search(ctx context.Context, req SearchRequest) ([]types.Listing, error) {
 	if !p.Configured() {
 		return nil, fmt.Errorf("FIRECRAWL_API_KEY not set")
 	}
 
-	searchQuery := fmt.Sprintf("%s price site:ebay.com OR site:mercari.com OR site:amazon.com", query)
+	req = req.normalized()
+
+	// Firecrawl search has no domain parameter, so the site filter stays in the query.
+	searchQuery := fmt.Sprintf("%s price %s", req.providerQuery(), siteFilterClause(req.domains()))
 
 	reqBody := map[string]any{
-		"query": searchQuery,
-		"limit": 20,
+		"query":   searchQuery,
+		"limit":   min(req.Depth, firecrawlMaxResults),
+		"country": strings.ToLower(req.Market.Country),
+		"lang":    req.Market.Language,
+	}
+	if freshness, ok := firecrawlFreshness[req.freshness()]; ok {
+		reqBody["tbs"] = freshness
 	}
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshal firecrawl request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.searchURL, bytes.NewReader(jsonBody))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.searchURL, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("create firecrawl request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(req)
+	resp, err := p.client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request firecrawl: %w", err)
 	}
@@ -93,5 +105,23 @@ func (p *FirecrawlProvider) Search(ctx context.Context, query string) ([]types.L
 		return nil, fmt.Errorf("decode firecrawl response: %w", err)
 	}
 
-	return ParseSearchResults(result.Data), nil
+	listings := ParseSearchResultsWith(result.Data, req.parseOptions())
+	req.reportProgress(p.Name(), len(listings), 1)
+	return listings, nil
+}
+
+// firecrawlFreshness maps freshness windows to Google-style tbs values.
+var firecrawlFreshness = map[string]string{
+	freshnessDay:   "qdr:d",
+	freshnessWeek:  "qdr:w",
+	freshnessMonth: "qdr:m",
+	freshnessYear:  "qdr:y",
+}
+
+func siteFilterClause(domains []string) string {
+	sites := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		sites = append(sites, "site:"+domain)
+	}
+	return strings.Join(sites, " OR ")
 }
@@ -27,7 +27,7 @@ func TestSearchFirecrawlChecksHTTPStatus(t *testing.T) {
 
 	provider := NewFirecrawlProvider("firecrawl-key", "https://firecrawl.test/v1/search", client)
 
-	_, err := provider.Search(context.Background(), "ps5")
+	_, err := provider.Search(context.Background(), SearchRequest{Query: "ps5"})
 	if err == nil {
 		t.Fatal("expected error for non-2xx firecrawl response")
 	}
@@ -59,7 +59,7 @@ func TestSearchFirecrawlParsesSuccessResponse(t *testing.T) {
 	}
 
 	provider := NewFirecrawlProvider("firecrawl-key", "https://firecrawl.test/v1/search", client)
-	results, err := provider.Search(context.Background(), "switch")
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch"})
 	if err != nil {
 		t.Fatalf("expected successful firecrawl response, got %v", err)
 	}
@@ -85,7 +85,7 @@ func TestSearchFirecrawlHandlesEmptyResults(t *testing.T) {
 		}),
 	}
 	provider := NewFirecrawlProvider("firecrawl-key", "https://firecrawl.test/v1/search", client)
-	results, err := provider.Search(context.Background(), "switch")
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch"})
 	if err != nil {
 		t.Fatalf("expected no error for empty data, got %v", err)
 	}
@@ -105,7 +105,7 @@ func TestSearchFirecrawlMalformedJSON(t *testing.T) {
 		}),
 	}
 	provider := NewFirecrawlProvider("firecrawl-key", "https://firecrawl.test/v1/search", client)
-	_, err := provider.Search(context.Background(), "switch")
+	_, err := provider.Search(context.Background(), SearchRequest{Query: "switch"})
 	if err == nil {
 		t.Fatal("expected decode error for malformed json")
 	}
@@ -125,7 +125,7 @@ func TestSearchFirecrawlContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, err := provider.Search(ctx, "switch")
+	_, err := provider.Search(ctx, SearchRequest{Query: "switch"})
 	if err == nil {
 		t.Fatal("expected context cancellation error")
 	}
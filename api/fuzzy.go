@@ -0,0 +1,225 @@
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// minFuzzyTokenLength keeps short tokens such as "ps5" or "pro" exact;
+	// at that length one edit turns them into a different product.
+	minFuzzyTokenLength = 4
+	// maxFuzzyMatches caps the vocabulary tokens a misspelling may stand for.
+	maxFuzzyMatches = 8
+)
+
+// fuzzyIndex finds catalog vocabulary tokens close to a misspelled query
+// token. Candidates come from shared character trigrams and are confirmed
+// with a bounded edit distance, so a lookup touches only the few tokens that
+// look alike instead of the whole vocabulary.
+type fuzzyIndex struct {
+	tokens   []string // sorted
+	trigrams map[string][]int32
+}
+
+// fuzzyMatch is a vocabulary token and its edit distance from the query.
+type fuzzyMatch struct {
+	token    string
+	distance int
+}
+
+func newFuzzyIndex(vocabulary []string) *fuzzyIndex {
+	f := &fuzzyIndex{
+		tokens:   append([]string(nil), vocabulary...),
+		trigrams: map[string][]int32{},
+	}
+	sort.Strings(f.tokens)
+	for i, token := range f.tokens {
+		for _, gram := range trigrams(token, true) {
+			f.trigrams[gram] = append(f.trigrams[gram], int32(i))
+		}
+	}
+	return f
+}
+
+// contains reports whether token is in the vocabulary.
+func (f *fuzzyIndex) contains(token string) bool {
+	i := sort.SearchStrings(f.tokens, token)
+	return i < len(f.tokens) && f.tokens[i] == token
+}
+
+// hasPrefix reports whether some vocabulary token starts with prefix.
+func (f *fuzzyIndex) hasPrefix(prefix string) bool {
+	i := sort.SearchStrings(f.tokens, prefix)
+	return i < len(f.tokens) && strings.HasPrefix(f.tokens[i], prefix)
+}
+
+// match returns the vocabulary tokens nearest to token, all at the same
+// smallest edit distance. With prefix set, token is compared against the
+// start of each vocabulary token, for a word that is still being typed.
+func (f *fuzzyIndex) match(token string, prefix bool) []fuzzyMatch {
+	maxDistance := maxFuzzyDistance(token)
+	if maxDistance == 0 {
+		return nil
+	}
+
+	grams := trigrams(token, !prefix)
+	// Each edit changes at most four trigrams (three for a substitution,
+	// four for a transposition), so a token within maxDistance edits shares
+	// at least this many with the query.
+	minShared := len(grams) - 4*maxDistance
+	if minShared < 1 {
+		minShared = 1
+	}
+
+	shared := map[int32]int{}
+	for _, gram := range grams {
+		for _, id := range f.trigrams[gram] {
+			shared[id]++
+		}
+	}
+
+	limit := maxDistance + 1
+	best := limit
+	var out []fuzzyMatch
+	for id, count := range shared {
+		if count < minShared {
+			continue
+		}
+		candidate := f.tokens[id]
+		var distance int
+		if prefix {
+			distance = prefixEditDistance(token, candidate, limit)
+		} else {
+			if abs(len(candidate)-len(token)) > best {
+				continue
+			}
+			distance = editDistance(token, candidate, limit)
+		}
+		switch {
+		case distance < best:
+			best = distance
+			out = append(out[:0], fuzzyMatch{token: candidate, distance: distance})
+		case distance == best && distance < limit:
+			out = append(out, fuzzyMatch{token: candidate, distance: distance})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].token) != len(out[j].token) {
+			return len(out[i].token) < len(out[j].token)
+		}
+		return out[i].token < out[j].token
+	})
+	if len(out) > maxFuzzyMatches {
+		out = out[:maxFuzzyMatches]
+	}
+	return out
+}
+
+// maxFuzzyDistance is the number of typos tolerated in a token of this
+// length: none for short tokens, one up to seven letters, then two.
+func maxFuzzyDistance(token string) int {
+	switch {
+	case len(token) < minFuzzyTokenLength:
+		return 0
+	case len(token) < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// trigrams splits token into overlapping three-letter grams. The token is
+// padded with "$" at the start and, when closed, at the end, so that short
+// tokens still produce grams and prefixes can be matched.
+func trigrams(token string, closed bool) []string {
+	padded := "$" + token
+	if closed {
+		padded += "$"
+	}
+	if len(padded) < 3 {
+		return []string{padded}
+	}
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		gram := padded[i : i+3]
+		if _, ok := seen[gram]; ok {
+			continue
+		}
+		seen[gram] = struct{}{}
+		out = append(out, gram)
+	}
+	return out
+}
+
+// editDistance is the optimal string alignment distance between a and b
+// (insertions, deletions, substitutions and adjacent transpositions). It
+// stops early and returns limit once every alignment costs at least limit.
+func editDistance(a, b string, limit int) int {
+	rows := editDistanceRows(a, b, limit)
+	if rows == nil {
+		return limit
+	}
+	if d := rows[len(b)]; d < limit {
+		return d
+	}
+	return limit
+}
+
+// prefixEditDistance is the smallest edit distance between a and any prefix
+// of b, capped at limit.
+func prefixEditDistance(a, b string, limit int) int {
+	rows := editDistanceRows(a, b, limit)
+	if rows == nil {
+		return limit
+	}
+	best := limit
+	for _, d := range rows {
+		if d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// editDistanceRows returns the distances between all of a and each prefix
+// of b, or nil when no alignment can finish under limit.
+func editDistanceRows(a, b string, limit int) []int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, prevPrev[j-2]+1)
+			}
+			curr[j] = d
+			rowMin = min(rowMin, d)
+		}
+		if rowMin >= limit {
+			return nil
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// IdentifierKind names the kind of product code a query was recognized as.
+type IdentifierKind string
+
+const (
+	IdentifierGTIN IdentifierKind = "UPC/EAN" // UPC-A, EAN-8, EAN-13 or GTIN-14
+	IdentifierASIN IdentifierKind = "ASIN"
+	IdentifierMPN  IdentifierKind = "MPN"        // manufacturer part number, e.g. WH-1000XM5
+	IdentifierSet  IdentifierKind = "set number" // e.g. LEGO 75192 or 75192-1
+)
+
+// maxIdentifierTitleWords bounds the product name learned for a code.
+const maxIdentifierTitleWords = 8
+
+var (
+	asinPattern = regexp.MustCompile(`^B0[0-9A-Z]{8}$`)
+	// Four-digit set numbers need the variant suffix; alone they read as
+	// years or model numbers ("2024", "3080").
+	setNumberPattern = regexp.MustCompile(`^(?:\d{5,6}|\d{4,6}-\d{1,2})$`)
+	// MPNs are letter-and-digit groups joined by dashes or slashes; see
+	// hasMixedGroup.
+	mpnPattern         = regexp.MustCompile(`^[0-9A-Z]+(?:[-/][0-9A-Z]+)+$`)
+	identifierStrip    = strings.NewReplacer(" ", "", "-", "")
+	marketplaceSuffix  = regexp.MustCompile(`(?i)\s+[|:\-–]\s+(?:ebay|mercari|amazon(?:\.[a-z.]+)?|facebook(?: marketplace)?|walmart|target|best buy)\b.*$`)
+	titlePunctuationRe = regexp.MustCompile(`^[^\pL\pN]+|[^\pL\pN]+$`)
+)
+
+// LearnedIdentifier maps a code that is not in the catalog to the product
+// title learned from the listings a search for it returned.
+type LearnedIdentifier struct {
+	Code    string    `json:"code"`
+	Title   string    `json:"title"`
+	Learned time.Time `json:"learned"`
+}
+
+// NormalizeIdentifier uppercases a code and drops spaces and dashes. Numeric
+// GTINs are zero-padded to 14 digits, so a UPC-A and its EAN-13 form match.
+func NormalizeIdentifier(code string) string {
+	normalized := strings.ToUpper(identifierStrip.Replace(strings.TrimSpace(code)))
+	if isDigits(normalized) && isGTINLength(len(normalized)) {
+		return strings.Repeat("0", 14-len(normalized)) + normalized
+	}
+	return normalized
+}
+
+// ClassifyIdentifier reports whether query is a single scanned or typed
+// product code: a UPC/EAN/GTIN with a valid check digit, an ASIN, a set
+// number, or an MPN mixing letters and digits.
+func ClassifyIdentifier(query string) (IdentifierKind, bool) {
+	trimmed := strings.ToUpper(strings.TrimSpace(query))
+	code := identifierStrip.Replace(trimmed)
+	switch {
+	case isDigits(code) && isGTINLength(len(code)) && ValidGTIN(code):
+		return IdentifierGTIN, true
+	case asinPattern.MatchString(code):
+		return IdentifierASIN, true
+	case setNumberPattern.MatchString(trimmed):
+		return IdentifierSet, true
+	case mpnPattern.MatchString(trimmed) && hasMixedGroup(trimmed):
+		return IdentifierMPN, true
+	default:
+		return "", false
+	}
+}
+
+// hasMixedGroup reports whether a dash- or slash-separated group of code
+// mixes letters and digits, which tells "CFI-1215A" from "2-PACK".
+func hasMixedGroup(code string) bool {
+	for _, group := range strings.FieldsFunc(code, func(r rune) bool { return r == '-' || r == '/' }) {
+		if strings.ContainsAny(group, "0123456789") && strings.Trim(group, "0123456789") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// LooksLikeGTIN reports whether query has the shape of a UPC/EAN, whether or
+// not its check digit is right.
+func LooksLikeGTIN(query string) bool {
+	code := identifierStrip.Replace(strings.TrimSpace(query))
+	return isDigits(code) && isGTINLength(len(code))
+}
+
+// ValidGTIN checks the GS1 check digit of an 8, 12, 13 or 14 digit code.
+func ValidGTIN(code string) bool {
+	if !isDigits(code) || !isGTINLength(len(code)) {
+		return false
+	}
+	sum := 0
+	// Weights alternate 3, 1 from the digit next to the check digit.
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	check := (10 - sum%10) % 10
+	return int(code[len(code)-1]-'0') == check
+}
+
+func isGTINLength(n int) bool {
+	return n == 8 || n == 12 || n == 13 || n == 14
+}
+
+// validateIdentifiers rejects catalog codes that look like a UPC/EAN but have
+// the wrong check digit, the usual sign of a mistyped barcode.
+func validateIdentifiers(identifiers []string) error {
+	for _, identifier := range identifiers {
+		if LooksLikeGTIN(identifier) && !ValidGTIN(identifierStrip.Replace(identifier)) {
+			return fmt.Errorf("identifier %q has an invalid UPC/EAN check digit", identifier)
+		}
+	}
+	return nil
+}
+
+// SetLearnedIdentifiers replaces the learned codes Lookup falls back to when
+// a code is not in the catalog.
+func (idx *ProductIndex) SetLearnedIdentifiers(learned []LearnedIdentifier) {
+	if idx == nil {
+		return
+	}
+	idx.learned = make(map[string]string, len(learned))
+	for _, entry := range learned {
+		code := NormalizeIdentifier(entry.Code)
+		title := strings.TrimSpace(entry.Title)
+		if code != "" && title != "" {
+			idx.learned[code] = title
+		}
+	}
+}
+
+// Lookup resolves a product code typed or scanned into the search box to a
+// product name: catalog identifiers first, then codes learned from earlier
+// searches.
+func (idx *ProductIndex) Lookup(code string) (string, bool) {
+	if idx == nil {
+		return "", false
+	}
+	key := NormalizeIdentifier(code)
+	if key == "" {
+		return "", false
+	}
+	if i, ok := idx.identifiers[key]; ok {
+		return idx.products[i].entry.Name, true
+	}
+	if title, ok := idx.learned[key]; ok {
+		return title, true
+	}
+	return "", false
+}
+
+// IdentifierTitle derives a product name for code from the titles of the
+// listings a search for it returned: the words most titles share, in the
+// order of the title that has the most of them. Marketplace suffixes and the
+// code itself are left out. It returns "" when there is nothing to learn.
+func IdentifierTitle(code string, titles []string) string {
+	key := NormalizeIdentifier(code)
+	// Titles name a set by its number alone, without the "-1" suffix.
+	base := key
+	if kind, _ := ClassifyIdentifier(code); kind == IdentifierSet {
+		number, _, _ := strings.Cut(strings.TrimSpace(code), "-")
+		base = NormalizeIdentifier(number)
+	}
+	var cleaned [][]string
+	for _, title := range titles {
+		title = marketplaceSuffix.ReplaceAllString(strings.TrimSpace(title), "")
+		var words []string
+		for _, word := range strings.Fields(title) {
+			word = titlePunctuationRe.ReplaceAllString(word, "")
+			if normalized := NormalizeIdentifier(word); word == "" || normalized == key || normalized == base {
+				continue
+			}
+			words = append(words, word)
+		}
+		if len(words) > 0 {
+			cleaned = append(cleaned, words)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ""
+	}
+
+	threshold := (len(cleaned) + 1) / 2
+	if len(cleaned) > 1 {
+		threshold = max(2, threshold)
+	}
+	best := commonTitleWords(cleaned, threshold)
+	if len(best) < 2 {
+		return ""
+	}
+	if len(best) > maxIdentifierTitleWords {
+		best = best[:maxIdentifierTitleWords]
+	}
+	return strings.Join(best, " ")
+}
+
+// commonTitleWords returns the words at least threshold titles share, in the
+// order of the title that has the most of them.
+func commonTitleWords(titles [][]string, threshold int) []string {
+	frequency := map[string]int{}
+	for _, words := range titles {
+		seen := map[string]struct{}{}
+		for _, word := range words {
+			lower := strings.ToLower(word)
+			if _, ok := seen[lower]; ok {
+				continue
+			}
+			seen[lower] = struct{}{}
+			frequency[lower]++
+		}
+	}
+
+	var best []string
+	for _, words := range titles {
+		var common []string
+		seen := map[string]struct{}{}
+		for _, word := range words {
+			lower := strings.ToLower(word)
+			if _, ok := seen[lower]; ok || frequency[lower] < threshold {
+				continue
+			}
+			seen[lower] = struct{}{}
+			common = append(common, word)
+		}
+		if len(common) > len(best) {
+			best = common
+		}
+	}
+	return best
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidGTIN(t *testing.T) {
+	for _, code := range []string{"036000291452", "4006381333931", "73513537", "00036000291452"} {
+		if !ValidGTIN(code) {
+			t.Fatalf("expected %s to have a valid check digit", code)
+		}
+	}
+	for _, code := range []string{"036000291453", "4006381333932", "12345", "03600029145X"} {
+		if ValidGTIN(code) {
+			t.Fatalf("expected %s to be rejected", code)
+		}
+	}
+}
+
+func TestClassifyIdentifier(t *testing.T) {
+	tests := []struct {
+		query string
+		kind  IdentifierKind
+		ok    bool
+	}{
+		{"036000291452", IdentifierGTIN, true},
+		{" 4006381-333931 ", IdentifierGTIN, true},
+		{"b08n5wrwnw", IdentifierASIN, true},
+		{"036000291453", "", false},
+		{"switch oled", "", false},
+		{"10497", IdentifierSet, true},
+		{"75192-1", IdentifierSet, true},
+		{"3080", "", false},
+		{"2024", "", false},
+		{"wh-1000xm5", IdentifierMPN, true},
+		{"MQD83AM/A", IdentifierMPN, true},
+		{"rtx-3080 ti", "", false},
+		{"wi-fi", "", false},
+		{"2-pack", "", false},
+	}
+	for _, tt := range tests {
+		kind, ok := ClassifyIdentifier(tt.query)
+		if kind != tt.kind || ok != tt.ok {
+			t.Fatalf("ClassifyIdentifier(%q) = %q, %v; want %q, %v", tt.query, kind, ok, tt.kind, tt.ok)
+		}
+	}
+	if NormalizeIdentifier("036000291452") != NormalizeIdentifier("0036000291452") {
+		t.Fatal("expected UPC-A and its EAN-13 form to normalize alike")
+	}
+}
+
+func TestProductIndexLookupResolvesIdentifiers(t *testing.T) {
+	path := writeCatalog(t, `[
+  {"name": "LEGO Galaxy Explorer 10497", "category": "Toys", "identifiers": ["10497", "673419377096"]},
+  {"name": "Bad Barcode", "identifiers": ["673419377093"]}
+]`)
+
+	idx := NewProductIndex(path)
+	for _, code := range []string{"10497", "0673419377096", "673419377096"} {
+		if got, ok := idx.Lookup(code); !ok || got != "LEGO Galaxy Explorer 10497" {
+			t.Fatalf("Lookup(%q) = %q, %v", code, got, ok)
+		}
+	}
+	if errs := idx.CatalogErrors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "invalid UPC/EAN check digit") {
+		t.Fatalf("expected check digit error, got %v", errs)
+	}
+
+	got := idx.Explain("673419377096")
+	if !got.ByCode || got.Expanded != "LEGO Galaxy Explorer 10497" {
+		t.Fatalf("expected code lookup in expansion, got %+v", got)
+	}
+
+	idx.SetLearnedIdentifiers([]LearnedIdentifier{{Code: "036000291452", Title: "Kleenex Tissues"}})
+	if got, ok := idx.Lookup("0036000291452"); !ok || got != "Kleenex Tissues" {
+		t.Fatalf("expected learned code, got %q, %v", got, ok)
+	}
+	idx.SetExpansionRules([]ExpansionRule{{Query: "036000291452", Never: true}})
+	if got := idx.Expand("036000291452"); got != "036000291452" {
+		t.Fatalf("expected never rule to skip the code lookup, got %q", got)
+	}
+}
+
+func TestIdentifierTitle(t *testing.T) {
+	titles := []string{
+		"Nintendo Switch OLED Console White 045496882174 | eBay",
+		"NEW Nintendo Switch OLED Model - White - eBay",
+		"Nintendo Switch OLED White Joy-Con : Amazon.com: Video Games",
+	}
+	if got := IdentifierTitle("045496882174", titles); got != "Nintendo Switch OLED White" {
+		t.Fatalf("expected shared title words, got %q", got)
+	}
+	if got := IdentifierTitle("045496882174", []string{"045496882174"}); got != "" {
+		t.Fatalf("expected nothing to learn from the bare code, got %q", got)
+	}
+	sets := []string{"LEGO Star Wars 75192 Millennium Falcon", "LEGO 75192 Millennium Falcon UCS sealed"}
+	if got := IdentifierTitle("75192-1", sets); got != "LEGO Millennium Falcon" {
+		t.Fatalf("expected the set number left out of the learned title, got %q", got)
+	}
+}
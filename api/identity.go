@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ListingIdentity is a marketplace's stable handle for one listing.
+type ListingIdentity struct {
+	Platform string // "eBay", "Amazon", "Mercari", "Facebook" or "Other"
+	ItemID   string // eBay item number, ASIN, Mercari or Facebook item ID; "" if unknown
+	URL      string // canonical URL with tracking stripped and host normalized
+}
+
+// Key returns "platform:itemid", or "" when no item ID was found.
+func (id ListingIdentity) Key() string {
+	if id.ItemID == "" {
+		return ""
+	}
+	return strings.ToLower(id.Platform) + ":" + id.ItemID
+}
+
+var (
+	ebayItemPath     = regexp.MustCompile(`^/itm/(?:[^/]+/)?(\d{9,15})(?:/|$)`)
+	ebayItemID       = regexp.MustCompile(`^\d{9,15}$`)
+	amazonASINPath   = regexp.MustCompile(`/(?:dp|gp/product|gp/aw/d|exec/obidos/asin)/([A-Z0-9]{10})(?:[/?]|$)`)
+	mercariItemPath  = regexp.MustCompile(`/item/(m\d{8,14})(?:/|$)`)
+	facebookItemPath = regexp.MustCompile(`^/marketplace/item/(\d{6,20})(?:/|$)`)
+)
+
+// Tracking query parameters dropped from every URL, matched case-insensitively.
+var (
+	trackingParams = map[string]bool{
+		"fbclid": true, "gclid": true, "msclkid": true, "srsltid": true,
+		"tracking_id": true, "referral_code": true,
+	}
+	trackingParamPrefixes = []string{"utm_", "mc_"}
+)
+
+// Marketplace tracking parameters, dropped only from that marketplace's
+// URLs: on other hosts names like "ref", "tag" or "hash" are often real.
+var (
+	marketplaceTrackingParams = map[string]map[string]bool{
+		"eBay": {
+			"mkcid": true, "mkevt": true, "mkrid": true, "campid": true,
+			"customid": true, "toolid": true, "hash": true,
+		},
+		"Amazon": {"ref": true, "tag": true, "linkcode": true},
+	}
+	marketplaceTrackingPrefixes = map[string][]string{
+		"eBay":   {"_trk"},
+		"Amazon": {"pf_rd_", "pd_rd_", "ref_"},
+	}
+)
+
+// IdentifyListing canonicalizes a listing URL. Known marketplaces get an
+// item ID and a minimal item URL; other hosts only lose tracking parameters
+// and fragments. Unparseable input is returned trimmed with no ID.
+func IdentifyListing(rawURL string) ListingIdentity {
+	trimmed := strings.TrimSpace(rawURL)
+	id := ListingIdentity{Platform: detectPlatform(trimmed), URL: trimmed}
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.Host == "" {
+		return id
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if id.Platform != "Other" {
+		host = normalizeListingHost(host)
+	}
+	switch id.Platform {
+	case "eBay":
+		if match := ebayItemPath.FindStringSubmatch(parsed.Path); match != nil {
+			id.ItemID = match[1]
+		} else if item := parsed.Query().Get("item"); ebayItemID.MatchString(item) {
+			id.ItemID = item
+		}
+		if id.ItemID != "" {
+			id.URL = "https://" + host + "/itm/" + id.ItemID
+			return id
+		}
+	case "Amazon":
+		if match := amazonASINPath.FindStringSubmatch(parsed.Path); match != nil {
+			id.ItemID = match[1]
+			id.URL = "https://" + host + "/dp/" + id.ItemID
+			return id
+		}
+	case "Mercari":
+		if match := mercariItemPath.FindStringSubmatch(parsed.Path); match != nil {
+			id.ItemID = match[1]
+			prefix := "/item/"
+			if strings.HasPrefix(parsed.Path, "/us/") {
+				prefix = "/us/item/"
+			}
+			id.URL = "https://" + host + prefix + id.ItemID + "/"
+			return id
+		}
+	case "Facebook":
+		if match := facebookItemPath.FindStringSubmatch(parsed.Path); match != nil {
+			id.ItemID = match[1]
+			id.URL = "https://www.facebook.com/marketplace/item/" + id.ItemID + "/"
+			return id
+		}
+	}
+
+	parsed.Host = host
+	if parsed.Port() != "" {
+		parsed.Host = host + ":" + parsed.Port()
+	}
+	parsed.Fragment = ""
+	parsed.RawQuery = stripTrackingParams(parsed.Query(), id.Platform).Encode()
+	id.URL = parsed.String()
+	return id
+}
+
+// normalizeListingHost maps a marketplace's bare, mobile and alternate web
+// hosts such as ebay.com, m.ebay.com or web.facebook.com onto www.
+func normalizeListingHost(host string) string {
+	for _, prefix := range []string{"m.", "mobile.", "web.", "touch.", "cgi."} {
+		if strings.HasPrefix(host, prefix) {
+			return "www." + strings.TrimPrefix(host, prefix)
+		}
+	}
+	if strings.Count(host, ".") == 1 || isRegionalRoot(host) {
+		return "www." + host
+	}
+	return host
+}
+
+// isRegionalRoot reports bare registrable hosts like "ebay.co.uk".
+func isRegionalRoot(host string) bool {
+	for _, suffix := range []string{".co.uk", ".com.au", ".co.jp"} {
+		if strings.HasSuffix(host, suffix) {
+			return strings.Count(strings.TrimSuffix(host, suffix), ".") == 0
+		}
+	}
+	return false
+}
+
+func stripTrackingParams(values url.Values, platform string) url.Values {
+	for key := range values {
+		if isTrackingParam(strings.ToLower(key), platform) {
+			values.Del(key)
+		}
+	}
+	return values
+}
+
+func isTrackingParam(key, platform string) bool {
+	if trackingParams[key] || marketplaceTrackingParams[platform][key] {
+		return true
+	}
+	for _, prefixes := range [][]string{trackingParamPrefixes, marketplaceTrackingPrefixes[platform]} {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package api
+
+import "testing"
+
+func TestIdentifyListing(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		key     string
+		wantURL string
+	}{
+		{
+			name:    "ebay tracking and mobile host",
+			raw:     "https://m.ebay.com/itm/256012345678?hash=item3b9a&_trkparms=abc&mkcid=1",
+			key:     "ebay:256012345678",
+			wantURL: "https://www.ebay.com/itm/256012345678",
+		},
+		{
+			name:    "ebay slug path",
+			raw:     "https://www.ebay.co.uk/itm/Sony-PlayStation-5/256012345678",
+			key:     "ebay:256012345678",
+			wantURL: "https://www.ebay.co.uk/itm/256012345678",
+		},
+		{
+			name:    "ebay legacy item param",
+			raw:     "https://cgi.ebay.com/ws/eBayISAPI.dll?ViewItem&item=256012345678",
+			key:     "ebay:256012345678",
+			wantURL: "https://www.ebay.com/itm/256012345678",
+		},
+		{
+			name:    "amazon slug dp",
+			raw:     "https://amazon.com/Sony-PlayStation-5/dp/B0CL61F39H/ref=sr_1_1?tag=aff-20&qid=1",
+			key:     "amazon:B0CL61F39H",
+			wantURL: "https://www.amazon.com/dp/B0CL61F39H",
+		},
+		{
+			name:    "amazon gp product",
+			raw:     "https://www.amazon.co.jp/gp/product/B0CL61F39H?psc=1",
+			key:     "amazon:B0CL61F39H",
+			wantURL: "https://www.amazon.co.jp/dp/B0CL61F39H",
+		},
+		{
+			name:    "mercari us",
+			raw:     "https://mercari.com/us/item/m81234567890/?ref=search",
+			key:     "mercari:m81234567890",
+			wantURL: "https://www.mercari.com/us/item/m81234567890/",
+		},
+		{
+			name:    "mercari japan",
+			raw:     "https://jp.mercari.com/item/m81234567890",
+			key:     "mercari:m81234567890",
+			wantURL: "https://jp.mercari.com/item/m81234567890/",
+		},
+		{
+			name:    "facebook marketplace",
+			raw:     "https://web.facebook.com/marketplace/item/1234567890123/?ref=share&fbclid=xyz",
+			key:     "facebook:1234567890123",
+			wantURL: "https://www.facebook.com/marketplace/item/1234567890123/",
+		},
+		{
+			name:    "other host keeps query minus tracking",
+			raw:     "https://Shop.Example.com/p/42?id=7&utm_source=news#reviews",
+			wantURL: "https://shop.example.com/p/42?id=7",
+		},
+		{
+			name:    "other host keeps marketplace tracking names",
+			raw:     "https://shop.example.com/item?ref=main&tag=sneakers&hash=9f2c&gclid=x",
+			wantURL: "https://shop.example.com/item?hash=9f2c&ref=main&tag=sneakers",
+		},
+		{
+			name:    "amazon page without an asin drops affiliate tags",
+			raw:     "https://www.amazon.com/s?k=ps5&tag=aff-20&ref=nb_sb_noss",
+			wantURL: "https://www.amazon.com/s?k=ps5",
+		},
+		{
+			name:    "ebay search page has no item",
+			raw:     "https://www.ebay.com/sch/i.html?_nkw=ps5&utm_medium=x",
+			wantURL: "https://www.ebay.com/sch/i.html?_nkw=ps5",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IdentifyListing(tc.raw)
+			if got.Key() != tc.key {
+				t.Fatalf("expected key %q, got %q", tc.key, got.Key())
+			}
+			if got.URL != tc.wantURL {
+				t.Fatalf("expected URL %q, got %q", tc.wantURL, got.URL)
+			}
+		})
+	}
+}
+
+func TestParseSearchResultsDedupesAcrossTrackingVariants(t *testing.T) {
+	listings := ParseSearchResults([]SearchResult{
+		{URL: "https://www.ebay.com/itm/256012345678?_trksid=a", Title: "PS5 $400"},
+		{URL: "https://m.ebay.com/itm/256012345678", Title: "PS5 $400"},
+	})
+	merged := appendNewListings(nil, map[string]struct{}{}, listings)
+	if len(merged) != 1 || merged[0].ID != "ebay:256012345678" {
+		t.Fatalf("expected tracking variants to collapse to one item, got %+v", merged)
+	}
+}
@@ -0,0 +1,445 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// indexCacheMagic starts every index cache file; bump the version when the
+// layout or the way documents are built changes.
+const indexCacheMagic = "MRKTRIX2"
+
+// OpenProductIndex returns the index NewProductIndex would build for
+// catalogPaths, reading it from the cache file at cachePath when that was
+// written for the same embedded catalog and catalog file contents, and
+// rewriting the cache otherwise. Tokenizing and weighting a large catalog is
+// the slow part of building an index; loading skips both. A cache that
+// cannot be read or written is rebuilt or skipped silently.
+func OpenProductIndex(cachePath string, catalogPaths ...string) *ProductIndex {
+	if strings.TrimSpace(cachePath) == "" {
+		return NewProductIndex(catalogPaths...)
+	}
+	hash := catalogHash(catalogPaths)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		if idx, err := decodeProductIndex(data, hash); err == nil {
+			return idx
+		}
+	}
+
+	idx := NewProductIndex(catalogPaths...)
+	_ = writeIndexCache(cachePath, encodeProductIndex(idx, hash))
+	return idx
+}
+
+// catalogHash identifies the catalog an index is built from: the embedded
+// products and the contents of each catalog file, in order.
+func catalogHash(catalogPaths []string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(indexCacheMagic))
+	writeHashPart(h, embeddedProductCatalog)
+	for _, path := range catalogPaths {
+		writeHashPart(h, []byte(path))
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			h.Write([]byte{1})
+			writeHashPart(h, data)
+		case os.IsNotExist(err):
+			h.Write([]byte{0})
+		default:
+			h.Write([]byte{2})
+		}
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func writeHashPart(h interface{ Write([]byte) (int, error) }, part []byte) {
+	h.Write(binary.AppendUvarint(nil, uint64(len(part))))
+	h.Write(part)
+}
+
+func writeIndexCache(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create index cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create index cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write index cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write index cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write index cache: %w", err)
+	}
+	return nil
+}
+
+// encodeProductIndex serializes the products, their words and TF-IDF
+// vectors, the sorted prefix lookups, and the catalog errors. Every word is
+// stored once in a string table and referenced by number. The remaining
+// lookups are rebuilt on load.
+func encodeProductIndex(idx *ProductIndex, hash [sha256.Size]byte) []byte {
+	var e indexEncoder
+	e.buf = append(e.buf, indexCacheMagic...)
+	e.buf = append(e.buf, hash[:]...)
+
+	words := map[string]uint64{}
+	var table []string
+	intern := func(word string) {
+		if _, ok := words[word]; !ok {
+			words[word] = uint64(len(table))
+			table = append(table, word)
+		}
+	}
+	for token := range idx.idf {
+		intern(token)
+	}
+	for _, product := range idx.products {
+		for _, token := range product.nameTokens {
+			intern(token)
+		}
+		for _, tokens := range product.synonymTokens {
+			for _, token := range tokens {
+				intern(token)
+			}
+		}
+	}
+	e.uint(uint64(len(table)))
+	for _, word := range table {
+		e.string(word)
+	}
+	e.uint(uint64(len(idx.idf)))
+	for token, idf := range idx.idf {
+		e.uint(words[token])
+		e.float(idf)
+	}
+
+	e.uint(uint64(len(idx.catalogErrors)))
+	for _, err := range idx.catalogErrors {
+		e.string(err.Error())
+	}
+
+	e.uint(uint64(len(idx.products)))
+	for _, product := range idx.products {
+		entry := product.entry
+		e.string(entry.Name)
+		e.string(entry.Category)
+		e.strings(entry.Synonyms)
+		e.strings(entry.Identifiers)
+		e.float(entry.MSRP)
+		e.string(entry.Released)
+		e.bool(entry.Discontinued)
+		e.string(entry.FeeClass)
+		e.strings(entry.Require)
+		e.strings(entry.Exclude)
+		e.bool(entry.Disabled)
+
+		e.words(product.nameTokens, words)
+		for _, tokens := range product.synonymTokens {
+			e.words(tokens, words)
+		}
+		e.uint(uint64(len(product.vector)))
+		for token, weight := range product.vector {
+			e.uint(words[token])
+			e.float(weight)
+		}
+	}
+
+	// Phrases refer to their product's name (0) or synonym (n+1).
+	e.uint(uint64(len(idx.phrases)))
+	for _, p := range idx.phrases {
+		product := idx.products[p.product]
+		e.uint(uint64(p.product))
+		e.uint(uint64(phraseSource(product, p.text)))
+	}
+	e.uint(uint64(len(idx.words)))
+	for i, word := range idx.words {
+		e.uint(words[word])
+		e.uint(uint64(len(idx.wordProducts[i])))
+		previous := int32(0)
+		for _, product := range idx.wordProducts[i] {
+			e.uint(uint64(product - previous))
+			previous = product
+		}
+	}
+	return e.buf
+}
+
+// decodeProductIndex reads an index written by encodeProductIndex for the
+// catalog identified by hash.
+func decodeProductIndex(data []byte, hash [sha256.Size]byte) (*ProductIndex, error) {
+	if !bytes.HasPrefix(data, []byte(indexCacheMagic)) {
+		return nil, errors.New("index cache: unknown format")
+	}
+	data = data[len(indexCacheMagic):]
+	if len(data) < len(hash) || !bytes.Equal(data[:len(hash)], hash[:]) {
+		return nil, errors.New("index cache: catalog changed")
+	}
+	d := indexDecoder{buf: data[len(hash):]}
+
+	table := make([]string, d.count())
+	for i := range table {
+		table[i] = d.string()
+	}
+	word := func() string {
+		i := d.uint()
+		if i >= uint64(len(table)) {
+			d.fail()
+			return ""
+		}
+		return table[i]
+	}
+
+	vocabulary := d.count()
+	idx := &ProductIndex{idf: make(map[string]float64, vocabulary)}
+	for ; vocabulary > 0 && d.err == nil; vocabulary-- {
+		token := word()
+		idx.idf[token] = d.float()
+	}
+
+	for n := d.count(); n > 0 && d.err == nil; n-- {
+		idx.catalogErrors = append(idx.catalogErrors, errors.New(d.string()))
+	}
+
+	products := d.count()
+	idx.products = make([]productDocument, 0, products)
+	for ; products > 0 && d.err == nil; products-- {
+		var entry ProductEntry
+		entry.Name = d.string()
+		entry.Category = d.string()
+		entry.Synonyms = d.strings()
+		entry.Identifiers = d.strings()
+		entry.MSRP = d.float()
+		entry.Released = d.string()
+		entry.Discontinued = d.bool()
+		entry.FeeClass = d.string()
+		entry.Require = d.strings()
+		entry.Exclude = d.strings()
+		entry.Disabled = d.bool()
+
+		doc := productDocument{entry: entry, nameLower: strings.ToLower(entry.Name)}
+		doc.nameTokens = d.words(word)
+		for _, synonym := range entry.Synonyms {
+			tokens := d.words(word)
+			doc.synonymsLower = append(doc.synonymsLower, strings.ToLower(synonym))
+			doc.synonymTokens = append(doc.synonymTokens, tokens)
+			doc.synonymKeys = append(doc.synonymKeys, strings.Join(withoutStopWords(tokens), " "))
+		}
+		terms := d.count()
+		doc.vector = make(map[string]float64, terms)
+		for ; terms > 0 && d.err == nil; terms-- {
+			token := word()
+			doc.vector[token] = d.float()
+		}
+		idx.products = append(idx.products, doc)
+	}
+
+	phrases := d.count()
+	idx.phrases = make([]phrase, 0, phrases)
+	for ; phrases > 0 && d.err == nil; phrases-- {
+		product, source := d.uint(), d.uint()
+		if product >= uint64(len(idx.products)) || source > uint64(len(idx.products[product].synonymsLower)) {
+			d.fail()
+			break
+		}
+		text := idx.products[product].nameLower
+		if source > 0 {
+			text = idx.products[product].synonymsLower[source-1]
+		}
+		idx.phrases = append(idx.phrases, phrase{text: text, product: int32(product)})
+	}
+	wordCount := d.count()
+	idx.words = make([]string, 0, wordCount)
+	idx.wordProducts = make([][]int32, 0, wordCount)
+	for ; wordCount > 0 && d.err == nil; wordCount-- {
+		idx.words = append(idx.words, word())
+		ids := make([]int32, d.count())
+		previous := uint64(0)
+		for i := range ids {
+			previous += d.uint()
+			if previous >= uint64(len(idx.products)) {
+				d.fail()
+				break
+			}
+			ids[i] = int32(previous)
+		}
+		idx.wordProducts = append(idx.wordProducts, ids)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.buf) != 0 {
+		return nil, errors.New("index cache: trailing data")
+	}
+
+	idx.buildTermLookups()
+	return idx, nil
+}
+
+// phraseSource numbers text among the product's phrases: 0 for its name,
+// n+1 for synonym n.
+func phraseSource(product productDocument, text string) int {
+	if text == product.nameLower {
+		return 0
+	}
+	for i, synonym := range product.synonymsLower {
+		if synonym == text {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func withoutStopWords(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if _, skip := stopWords[token]; !skip {
+			out = append(out, token)
+		}
+	}
+	return out
+}
+
+type indexEncoder struct {
+	buf []byte
+}
+
+func (e *indexEncoder) uint(v uint64) { e.buf = binary.AppendUvarint(e.buf, v) }
+
+func (e *indexEncoder) float(v float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+func (e *indexEncoder) bool(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *indexEncoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *indexEncoder) strings(values []string) {
+	e.uint(uint64(len(values)))
+	for _, s := range values {
+		e.string(s)
+	}
+}
+
+func (e *indexEncoder) words(tokens []string, table map[string]uint64) {
+	e.uint(uint64(len(tokens)))
+	for _, token := range tokens {
+		e.uint(table[token])
+	}
+}
+
+// indexDecoder reads what indexEncoder wrote. The first error sticks and
+// every later read returns a zero value.
+type indexDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *indexDecoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("index cache: truncated or corrupt")
+	}
+	d.buf = nil
+}
+
+func (d *indexDecoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count reads a length, which can be no more than the bytes left since
+// every counted item takes at least one.
+func (d *indexDecoder) count() int {
+	v := d.uint()
+	if v > uint64(len(d.buf)) {
+		d.fail()
+		return 0
+	}
+	return int(v)
+}
+
+func (d *indexDecoder) float() float64 {
+	if d.err != nil || len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *indexDecoder) bool() bool {
+	if d.err != nil || len(d.buf) < 1 {
+		d.fail()
+		return false
+	}
+	v := d.buf[0] != 0
+	d.buf = d.buf[1:]
+	return v
+}
+
+func (d *indexDecoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func (d *indexDecoder) strings() []string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	out := make([]string, n)
+	for i := range out {
+		out[i] = d.string()
+	}
+	return out
+}
+
+func (d *indexDecoder) words(word func() string) []string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	out := make([]string, n)
+	for i := range out {
+		out[i] = word()
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOpenProductIndexReusesCacheUntilCatalogChanges(t *testing.T) {
+	path := writeCatalog(t, `[
+  {"name": "Pyrex 443 Primary Bowl", "category": "Kitchen", "synonyms": ["pyrex 443", "pyrex primary"], "identifiers": ["071160044302"], "msrp": 39.5, "released": "1947", "require": ["pyrex"], "exclude": ["lid only"]},
+  {"category": "Kitchen", "synonyms": ["nameless"]}
+]`)
+	cachePath := filepath.Join(t.TempDir(), "cache", "catalog-index.bin")
+
+	built := OpenProductIndex(cachePath, path)
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("expected the index to be cached: %v", err)
+	}
+	if _, err := decodeProductIndex(data, catalogHash([]string{path})); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+
+	cached := OpenProductIndex(cachePath, path)
+	if cached.Len() != built.Len() {
+		t.Fatalf("expected %d products from cache, got %d", built.Len(), cached.Len())
+	}
+	for _, prefix := range []string{"pyr", "pyrex 44", "playstaion", "swi"} {
+		if got, want := cached.Suggest(prefix), built.Suggest(prefix); !slices.Equal(got, want) {
+			t.Fatalf("Suggest(%q): cached %v, built %v", prefix, got, want)
+		}
+	}
+	for _, query := range []string{"pyrex primary", "ps5", "airpod pro"} {
+		got, want := cached.Explain(query), built.Explain(query)
+		if got.Expanded != want.Expanded || !slices.Equal(got.Candidates, want.Candidates) {
+			t.Fatalf("Explain(%q): cached %+v, built %+v", query, got, want)
+		}
+	}
+	if name, ok := cached.Lookup("071160044302"); !ok || name != "Pyrex 443 Primary Bowl" {
+		t.Fatalf("expected code lookup from cache, got %q %v", name, ok)
+	}
+	if product, ok := cached.Product("pyrex 443 primary bowl"); !ok || product.MSRP != 39.5 || product.Released != "1947" ||
+		!slices.Equal(product.Require, []string{"pyrex"}) || !slices.Equal(product.Exclude, []string{"lid only"}) {
+		t.Fatalf("expected retail metadata and terms from cache, got %+v", product)
+	}
+	errs := cached.CatalogErrors()
+	if len(errs) != 1 || errs[0].Error() != built.CatalogErrors()[0].Error() {
+		t.Fatalf("expected catalog errors from cache, got %v", errs)
+	}
+
+	body, _ := json.Marshal([]ProductEntry{{Name: "Yeti Rambler 20 oz", Category: "Kitchen", Synonyms: []string{"rambler 20"}}})
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		t.Fatalf("rewrite catalog: %v", err)
+	}
+	changed := OpenProductIndex(cachePath, path)
+	if _, ok := changed.Product("yeti rambler 20 oz"); !ok {
+		t.Fatal("expected an edited catalog to invalidate the cache")
+	}
+	if _, ok := changed.Product("pyrex 443 primary bowl"); ok {
+		t.Fatal("expected the removed product to be gone")
+	}
+	if len(changed.CatalogErrors()) != 0 {
+		t.Fatalf("expected no catalog errors, got %v", changed.CatalogErrors())
+	}
+}
+
+func TestOpenProductIndexRebuildsCorruptCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "catalog-index.bin")
+	want := NewProductIndex().Len()
+	OpenProductIndex(cachePath)
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("read cache: %v", err)
+	}
+	if err := os.WriteFile(cachePath, data[:len(data)/2], 0o644); err != nil {
+		t.Fatalf("truncate cache: %v", err)
+	}
+	if _, err := decodeProductIndex(data[:len(data)/2], catalogHash(nil)); err == nil {
+		t.Fatal("expected a truncated cache to fail to decode")
+	}
+	if got := OpenProductIndex(cachePath).Len(); got != want {
+		t.Fatalf("expected %d products after rebuilding, got %d", want, got)
+	}
+}
+
+func BenchmarkOpenProductIndexCached(b *testing.B) {
+	dir := b.TempDir()
+	path := filepath.Join(dir, "catalog.json")
+	body, err := json.Marshal(benchmarkCatalog(benchmarkCatalogSize))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	cachePath := filepath.Join(dir, "catalog-index.bin")
+	OpenProductIndex(cachePath, path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OpenProductIndex(cachePath, path)
+	}
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mrktr/types"
+)
+
+//go:embed data/places.csv
+var embeddedPlaces []byte
+
+// usStates maps lower-case state names and codes to two-letter codes.
+var usStates = func() map[string]string {
+	names := map[string]string{
+		"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas", "CA": "California",
+		"CO": "Colorado", "CT": "Connecticut", "DE": "Delaware", "DC": "District of Columbia",
+		"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho", "IL": "Illinois",
+		"IN": "Indiana", "IA": "Iowa", "KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana",
+		"ME": "Maine", "MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+		"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+		"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
+		"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma", "OR": "Oregon",
+		"PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina", "SD": "South Dakota",
+		"TN": "Tennessee", "TX": "Texas", "UT": "Utah", "VT": "Vermont", "VA": "Virginia",
+		"WA": "Washington", "WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming",
+	}
+	out := make(map[string]string, 2*len(names))
+	for code, name := range names {
+		out[strings.ToLower(code)] = code
+		out[strings.ToLower(name)] = code
+	}
+	return out
+}()
+
+// urlPlaceAliases maps marketplace city slugs that are not plain city names.
+var urlPlaceAliases = map[string]string{
+	"nyc":          "new york|ny",
+	"newyork":      "new york|ny",
+	"la":           "los angeles|ca",
+	"sfbay":        "san francisco|ca",
+	"sf":           "san francisco|ca",
+	"dc":           "washington|dc",
+	"washingtondc": "washington|dc",
+	"philly":       "philadelphia|pa",
+	"stlouis":      "st. louis|mo",
+	"slc":          "salt lake city|ut",
+	"saltlakecity": "salt lake city|ut",
+	"twincities":   "minneapolis|mn",
+	"inlandempire": "riverside|ca",
+	"orangecounty": "santa ana|ca",
+}
+
+var (
+	// "Austin, TX", "Austin, Texas 78701"; city words are capitalized.
+	locationCityState = regexp.MustCompile(`\b([A-Z][A-Za-z.'-]+(?: [A-Z][A-Za-z.'-]+){0,2}),\s*([A-Z]{2}|[A-Z][a-z]+(?: [A-Z][a-z]+)?)\b(?:\s+(\d{5})(?:-\d{4})?\b)?`)
+	// "zip 78701", "near 78701", "in 78701"; bare numbers are too ambiguous.
+	locationZIP       = regexp.MustCompile(`(?i)\b(?:zip(?: code)?|near|in)\s*:?\s*(\d{5})(?:-\d{4})?\b`)
+	locationBareZIP   = regexp.MustCompile(`^\s*(\d{5})(?:-\d{4})?\s*$`)
+	facebookPlacePath = regexp.MustCompile(`^/marketplace/([a-z][a-z-]+)(?:/|$)`)
+)
+
+type placeTable struct {
+	byCityState map[string]types.Location   // "austin|tx"
+	byCity      map[string][]types.Location // "austin"; ambiguous names list several
+	byZIP3      map[string]types.Location
+}
+
+var (
+	placesOnce sync.Once
+	places     placeTable
+)
+
+func loadPlaces() placeTable {
+	placesOnce.Do(func() {
+		places = parsePlaces(embeddedPlaces)
+	})
+	return places
+}
+
+// parsePlaces reads "city,state,lat,lon,zip3 zip3" rows; bad rows are skipped.
+func parsePlaces(data []byte) placeTable {
+	table := placeTable{
+		byCityState: map[string]types.Location{},
+		byCity:      map[string][]types.Location{},
+		byZIP3:      map[string]types.Location{},
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 5
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return table
+	}
+
+	for _, row := range rows {
+		lat, latErr := strconv.ParseFloat(row[2], 64)
+		lon, lonErr := strconv.ParseFloat(row[3], 64)
+		if latErr != nil || lonErr != nil {
+			continue
+		}
+		place := types.Location{City: row[0], State: row[1], Lat: lat, Lon: lon}
+		city := strings.ToLower(place.City)
+		table.byCityState[city+"|"+strings.ToLower(place.State)] = place
+		table.byCity[city] = append(table.byCity[city], place)
+		for _, zip3 := range strings.Fields(row[4]) {
+			table.byZIP3[zip3] = place
+		}
+	}
+	return table
+}
+
+// LookupLocation resolves a home location written as a ZIP code ("78701"),
+// "City, ST", "City, State" or an unambiguous city name.
+func LookupLocation(text string) (types.Location, bool) {
+	text = strings.TrimSpace(text)
+	if match := locationBareZIP.FindStringSubmatch(text); match != nil {
+		return locationForZIP(match[1])
+	}
+	if city, state, ok := strings.Cut(text, ","); ok {
+		return locationForCityState(city, state, "")
+	}
+	return locationForCity(text)
+}
+
+// localPickupHosts are host labels of marketplaces where buyers collect in
+// person. Listings elsewhere ship, so a seller's city is not a pickup spot.
+var localPickupHosts = []string{"facebook", "fb", "craigslist", "offerup"}
+
+// isLocalPickup reports whether rawURL is on a local-pickup marketplace.
+func isLocalPickup(rawURL string) bool {
+	host := ""
+	if parsed, err := url.Parse(strings.TrimSpace(rawURL)); err == nil {
+		host = parsed.Hostname()
+	}
+	for _, label := range localPickupHosts {
+		if hostHasLabel(host, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractLocation finds a pickup location in snippet text, falling back to
+// city slugs in Craigslist and Facebook Marketplace URLs. A city missing
+// from the table is kept without coordinates only when a ZIP backs it up.
+func extractLocation(text, rawURL string) types.Location {
+	for _, match := range locationCityState.FindAllStringSubmatch(text, -1) {
+		// The pattern can swallow leading capitalized words ("Pickup Austin"),
+		// so try the shorter city names too.
+		words := strings.Fields(match[1])
+		for i := range words {
+			if location, ok := locationForCityState(strings.Join(words[i:], " "), match[2], match[3]); ok {
+				return location
+			}
+		}
+		if match[3] != "" {
+			if location, _ := locationForCityState(match[1], match[2], match[3]); !location.IsZero() {
+				return location
+			}
+		}
+	}
+	if match := locationZIP.FindStringSubmatch(text); match != nil {
+		if location, ok := locationForZIP(match[1]); ok {
+			return location
+		}
+	}
+	if location, ok := locationFromURL(rawURL); ok {
+		return location
+	}
+	return types.Location{}
+}
+
+func locationForZIP(zip string) (types.Location, bool) {
+	if len(zip) < 3 {
+		return types.Location{}, false
+	}
+	place, ok := loadPlaces().byZIP3[zip[:3]]
+	if !ok {
+		return types.Location{ZIP: zip}, false
+	}
+	place.ZIP = zip
+	return place, true
+}
+
+// locationForCityState resolves a place; an unknown city in a valid state is
+// returned without coordinates, and an invalid state yields nothing.
+func locationForCityState(city, state, zip string) (types.Location, bool) {
+	code, ok := usStates[strings.ToLower(strings.TrimSpace(state))]
+	if !ok {
+		return types.Location{}, false
+	}
+	city = strings.TrimSpace(city)
+	if place, ok := loadPlaces().byCityState[strings.ToLower(city)+"|"+strings.ToLower(code)]; ok {
+		place.ZIP = zip
+		return place, true
+	}
+	if zip != "" {
+		if place, ok := locationForZIP(zip); ok && place.State == code {
+			place.City = city
+			return place, true
+		}
+	}
+	return types.Location{City: city, State: code, ZIP: zip}, false
+}
+
+func locationForCity(city string) (types.Location, bool) {
+	matches := loadPlaces().byCity[strings.ToLower(strings.TrimSpace(city))]
+	if len(matches) != 1 {
+		return types.Location{}, false
+	}
+	return matches[0], true
+}
+
+// locationFromURL reads the city from hosts like austin.craigslist.org and
+// paths like /marketplace/austin/.
+func locationFromURL(rawURL string) (types.Location, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return types.Location{}, false
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	var slug string
+	switch {
+	case strings.HasSuffix(host, ".craigslist.org"):
+		slug = strings.TrimSuffix(host, ".craigslist.org")
+	case hostHasLabel(host, "facebook"):
+		if match := facebookPlacePath.FindStringSubmatch(strings.ToLower(parsed.Path)); match != nil && match[1] != "item" {
+			slug = match[1]
+		}
+	}
+	if slug == "" || strings.Contains(slug, ".") {
+		return types.Location{}, false
+	}
+	return locationForSlug(slug)
+}
+
+func locationForSlug(slug string) (types.Location, bool) {
+	slug = strings.ReplaceAll(slug, "-", "")
+	if alias, ok := urlPlaceAliases[slug]; ok {
+		city, state, _ := strings.Cut(alias, "|")
+		return locationForCityState(city, state, "")
+	}
+	var found []types.Location
+	for name, candidates := range loadPlaces().byCity {
+		if strings.NewReplacer(" ", "", ".", "").Replace(name) == slug {
+			found = append(found, candidates...)
+		}
+	}
+	if len(found) != 1 {
+		return types.Location{}, false
+	}
+	return found[0], true
+}
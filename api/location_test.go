@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+
+	"mrktr/types"
+)
+
+func TestExtractLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		url       string
+		wantCity  string
+		wantState string
+		wantZIP   string
+		wantKnown bool
+	}{
+		{name: "city and state code", text: "Switch OLED pickup Austin, TX only", wantCity: "Austin", wantState: "TX", wantKnown: true},
+		{name: "state name and zip", text: "Located in Seattle, Washington 98101", wantCity: "Seattle", wantState: "WA", wantZIP: "98101", wantKnown: true},
+		{name: "leading capitalized words", text: "Local Pickup Denver, CO", wantCity: "Denver", wantState: "CO", wantKnown: true},
+		{name: "unknown city backed by zip", text: "Round Rock, TX 78664", wantCity: "Round Rock", wantState: "TX", wantZIP: "78664", wantKnown: true},
+		{name: "zip keyword", text: "meet near 30303", wantCity: "Atlanta", wantState: "GA", wantZIP: "30303", wantKnown: true},
+		{name: "craigslist host", text: "Switch for sale", url: "https://austin.craigslist.org/vgm/d/switch/7712345678.html", wantCity: "Austin", wantState: "TX", wantKnown: true},
+		{name: "facebook city path", text: "Switch", url: "https://www.facebook.com/marketplace/nyc/search?query=switch", wantCity: "New York", wantState: "NY", wantKnown: true},
+		{name: "not a state", text: "Nintendo Switch, Used", url: "https://www.ebay.com/itm/123456789012"},
+		{name: "bare number is not a zip", text: "Model 12345 console $200"},
+		{name: "unknown city without zip", text: "Tested, OK works great"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractLocation(tt.text, tt.url)
+			if got.City != tt.wantCity || got.State != tt.wantState || got.ZIP != tt.wantZIP {
+				t.Fatalf("expected %s/%s/%s, got %+v", tt.wantCity, tt.wantState, tt.wantZIP, got)
+			}
+			if got.Known() != tt.wantKnown {
+				t.Fatalf("expected known=%v, got %+v", tt.wantKnown, got)
+			}
+		})
+	}
+}
+
+func TestLookupLocation(t *testing.T) {
+	for _, input := range []string{"78701", "Austin, TX", "austin, texas", "Austin"} {
+		got, ok := LookupLocation(input)
+		if !ok || got.City != "Austin" || got.State != "TX" {
+			t.Fatalf("LookupLocation(%q) = %+v, %v; want Austin, TX", input, got, ok)
+		}
+	}
+	if _, ok := LookupLocation("Portland"); ok {
+		t.Fatal("expected ambiguous city name to need a state")
+	}
+	if _, ok := LookupLocation("Atlantis, ZZ"); ok {
+		t.Fatal("expected unknown state to fail")
+	}
+}
+
+func TestPlacesTableHasUniqueZIPPrefixes(t *testing.T) {
+	table := parsePlaces(embeddedPlaces)
+	if len(table.byCityState) < 100 {
+		t.Fatalf("expected bundled places table, got %d places", len(table.byCityState))
+	}
+
+	reader := csv.NewReader(bytes.NewReader(embeddedPlaces))
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("read places: %v", err)
+	}
+	owner := map[string]string{}
+	for _, row := range rows {
+		for _, zip3 := range strings.Fields(row[4]) {
+			if prev, ok := owner[zip3]; ok {
+				t.Fatalf("zip prefix %s listed for %s and %s", zip3, prev, row[0])
+			}
+			owner[zip3] = row[0]
+		}
+	}
+
+	dallas := table.byCityState["dallas|tx"]
+	fortWorth := table.byCityState["fort worth|tx"]
+	miles, ok := types.DistanceMiles(dallas, fortWorth)
+	if !ok || math.Abs(miles-30) > 5 {
+		t.Fatalf("expected Dallas to Fort Worth about 30 miles, got %.1f", miles)
+	}
+}
+
+func TestParseSearchResultsExtractsLocation(t *testing.T) {
+	got := ParseSearchResults([]SearchResult{{
+		URL:         "https://www.facebook.com/marketplace/item/123456789/",
+		Title:       "Nintendo Switch",
+		Description: "$180 · Houston, TX",
+	}})
+	if len(got) != 1 {
+		t.Fatalf("expected one listing, got %d", len(got))
+	}
+	if got[0].Location.City != "Houston" || !got[0].Location.Known() {
+		t.Fatalf("expected Houston location, got %+v", got[0].Location)
+	}
+}
+
+func TestParseSearchResultsSkipsLocationForShippedListings(t *testing.T) {
+	got := ParseSearchResults([]SearchResult{{
+		URL:         "https://www.ebay.com/itm/123456789012",
+		Title:       "Nintendo Switch OLED",
+		Description: "$250 · Located in Austin, Texas, United States",
+	}})
+	if len(got) != 1 {
+		t.Fatalf("expected one listing, got %d", len(got))
+	}
+	if !got[0].Location.IsZero() {
+		t.Fatalf("expected no pickup location for a shipped eBay listing, got %+v", got[0].Location)
+	}
+
+	seattle := types.Location{City: "Seattle", State: "WA", Lat: 47.6062, Lon: -122.3321}
+	if kept := types.ApplyFilter(got, types.ResultFilter{Home: seattle, MaxDistance: 50}); len(kept) != 1 {
+		t.Fatalf("expected shipped eBay listing to survive the distance filter, got %+v", kept)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	lotQuantityPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:lot|set|bundle|box|case|pack)\s+of\s+(\d{1,3})\b`),
+		regexp.MustCompile(`\b(\d{1,3})\s*(?:-\s*)?(?:pack|pk|count|ct|pcs|pieces|units)\b`),
+		regexp.MustCompile(`\b(\d{1,3})\s*(?:x\s+|×\s*)[a-z]`),
+		regexp.MustCompile(`\bqty\s*:?\s*(\d{1,3})\b`),
+		regexp.MustCompile(`\b(\d{1,3})\s+(?:booster\s+packs|sealed\s+packs|cards|games|controllers)\s+lot\b`),
+	}
+	// bundleExtrasPattern needs a counted extra ("with 2 games") or two
+	// listed ones ("comes with controller and games"), so a single item that
+	// "comes with original box" is not a bundle.
+	bundleExtrasPattern = regexp.MustCompile(`(?:\s\+\s*\d*\s*[a-z]|\b(?:w/|with)\s+(?:\d+|extra|two|three)\s+` + bundleExtras + `\b|\bbundle\b|\bcomes\s+with\s+(?:[a-z]+\s+){0,2}?` + bundleExtras + `\s*(?:,|\band\b|&)\s*(?:[a-z]+\s+){0,2}?` + bundleExtras + `\b)`)
+)
+
+const bundleExtras = `(?:controllers?|games?|accessories|cases?|chargers?|lenses|batteries|docks?)`
+
+// maxLotQuantity bounds parsed quantities; larger numbers are usually model
+// numbers or card counts inside a single product.
+const maxLotQuantity = 500
+
+// extractLot reads a lot quantity and whether the listing bundles extras from
+// lower-cased listing text. A quantity of 1 means a single unit.
+func extractLot(textLower string) (int, bool) {
+	quantity := 1
+	for _, pattern := range lotQuantityPatterns {
+		match := pattern.FindStringSubmatch(textLower)
+		if match == nil {
+			continue
+		}
+		value, err := strconv.Atoi(match[1])
+		if err != nil || value < 2 || value > maxLotQuantity {
+			continue
+		}
+		quantity = value
+		break
+	}
+
+	bundle := false
+	if quantity == 1 {
+		bundle = bundleExtrasPattern.MatchString(textLower)
+	}
+	return quantity, bundle
+}
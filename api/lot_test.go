@@ -0,0 +1,33 @@
+package api
+
+import "testing"
+
+func TestExtractLot(t *testing.T) {
+	tests := []struct {
+		text     string
+		quantity int
+		bundle   bool
+	}{
+		{text: "lot of 5 pokemon booster packs", quantity: 5},
+		{text: "pokemon scarlet violet booster 10-pack", quantity: 10},
+		{text: "3x nintendo switch joy-con", quantity: 3},
+		{text: "aa batteries 24 count", quantity: 24},
+		{text: "ps5 + 2 controllers + 3 games", quantity: 1, bundle: true},
+		{text: "switch oled with 2 games", quantity: 1, bundle: true},
+		{text: "xbox series x holiday bundle", quantity: 1, bundle: true},
+		{text: "switch oled comes with pro controller and two games", quantity: 1, bundle: true},
+		{text: "ps5 slim comes with 2 controllers", quantity: 1, bundle: true},
+		{text: "airpods pro comes with original box", quantity: 1},
+		{text: "macbook air m2 comes with charger", quantity: 1},
+		{text: "ps5 slim 1tb disc edition", quantity: 1},
+		{text: "lot of 1 controller", quantity: 1},
+		{text: "lot of 9999 cards", quantity: 1},
+	}
+
+	for _, tc := range tests {
+		quantity, bundle := extractLot(tc.text)
+		if quantity != tc.quantity || bundle != tc.bundle {
+			t.Fatalf("extractLot(%q): expected (%d, %v), got (%d, %v)", tc.text, tc.quantity, tc.bundle, quantity, bundle)
+		}
+	}
+}
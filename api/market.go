@@ -0,0 +1,116 @@
+package api
+
+import "strings"
+
+// DefaultMarketCode is the market used when none is configured.
+const DefaultMarketCode = "US"
+
+// Market describes a regional marketplace locale: where providers search,
+// which language they prefer and which currency listings are priced in.
+type Market struct {
+	Code        string // mrktr market code, also the fee region
+	Country     string // ISO 3166 country code sent to Brave and Firecrawl
+	CountryName string // Tavily expects lower-case country names
+	Language    string // ISO 639-1 language code, e.g. "ja"; see braveLanguage
+	Currency    string // ISO 4217 currency expected by the parser
+	Domains     []string
+}
+
+var markets = []Market{
+	{
+		Code:        "US",
+		Country:     "US",
+		CountryName: "united states",
+		Language:    "en",
+		Currency:    "USD",
+		Domains:     defaultMarketplaceDomains,
+	},
+	{
+		Code:        "UK",
+		Country:     "GB",
+		CountryName: "united kingdom",
+		Language:    "en",
+		Currency:    "GBP",
+		Domains:     []string{"ebay.co.uk", "amazon.co.uk"},
+	},
+	{
+		Code:        "CA",
+		Country:     "CA",
+		CountryName: "canada",
+		Language:    "en",
+		Currency:    "CAD",
+		Domains:     []string{"ebay.ca", "amazon.ca"},
+	},
+	{
+		Code:        "DE",
+		Country:     "DE",
+		CountryName: "germany",
+		Language:    "de",
+		Currency:    "EUR",
+		Domains:     []string{"ebay.de", "amazon.de"},
+	},
+	{
+		Code:        "AU",
+		Country:     "AU",
+		CountryName: "australia",
+		Language:    "en",
+		Currency:    "AUD",
+		Domains:     []string{"ebay.com.au", "amazon.com.au"},
+	},
+	{
+		Code:        "JP",
+		Country:     "JP",
+		CountryName: "japan",
+		Language:    "ja",
+		Currency:    "JPY",
+		Domains:     []string{"jp.mercari.com", "amazon.co.jp"},
+	},
+}
+
+// Markets returns the supported markets in display order.
+func Markets() []Market {
+	out := make([]Market, len(markets))
+	copy(out, markets)
+	return out
+}
+
+// LookupMarket finds a market by code, case-insensitively. "GB" is accepted
+// as an alias for "UK".
+func LookupMarket(code string) (Market, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "GB" {
+		code = "UK"
+	}
+	for _, market := range markets {
+		if market.Code == code {
+			return market, true
+		}
+	}
+	return Market{}, false
+}
+
+// DefaultMarket returns the US market.
+func DefaultMarket() Market {
+	market, _ := LookupMarket(DefaultMarketCode)
+	return market
+}
+
+// NextMarket returns the market after code in display order, wrapping around.
+func NextMarket(code string) Market {
+	for i, market := range markets {
+		if market.Code == code {
+			return markets[(i+1)%len(markets)]
+		}
+	}
+	return DefaultMarket()
+}
+
+// domainFor returns the market's domain for a platform such as "eBay".
+func (m Market) domainFor(platform string) (string, bool) {
+	for _, domain := range m.Domains {
+		if detectPlatform(domain) == platform {
+			return domain, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLookupMarket(t *testing.T) {
+	market, ok := LookupMarket(" gb ")
+	if !ok || market.Code != "UK" || market.Currency != "GBP" {
+		t.Fatalf("expected GB alias to resolve to UK market, got %+v (ok=%v)", market, ok)
+	}
+	if _, ok := LookupMarket("FR"); ok {
+		t.Fatal("expected unsupported market lookup to fail")
+	}
+}
+
+func TestNextMarketWraps(t *testing.T) {
+	all := Markets()
+	if got := NextMarket(all[len(all)-1].Code); got.Code != all[0].Code {
+		t.Fatalf("expected wrap to %s, got %s", all[0].Code, got.Code)
+	}
+	if got := NextMarket("??"); got.Code != DefaultMarketCode {
+		t.Fatalf("expected unknown code to reset to default, got %s", got.Code)
+	}
+}
+
+func TestSearchRequestDomainsFollowMarket(t *testing.T) {
+	de, _ := LookupMarket("DE")
+
+	req := SearchRequest{Query: "ps5", Market: de}.normalized()
+	if domains := req.domains(); len(domains) != 2 || domains[0] != "ebay.de" {
+		t.Fatalf("expected German marketplace domains, got %v", domains)
+	}
+
+	req.Platform = "Amazon"
+	if domains := req.domains(); len(domains) != 1 || domains[0] != "amazon.de" {
+		t.Fatalf("expected regional Amazon domain, got %v", domains)
+	}
+
+	req.Platform = "Mercari"
+	if domains := req.domains(); len(domains) != 1 || domains[0] != "mercari.com" {
+		t.Fatalf("expected fallback to US Mercari domain, got %v", domains)
+	}
+
+	if got := (SearchRequest{}).normalized().Market.Code; got != DefaultMarketCode {
+		t.Fatalf("expected default market, got %q", got)
+	}
+}
+
+func TestJapaneseMarketLanguagePerProvider(t *testing.T) {
+	jp, _ := LookupMarket("JP")
+	var braveLang string
+	var firecrawlBody map[string]any
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{"web":{"results":[]}}`
+			if req.URL.Host == "firecrawl.test" {
+				if err := json.NewDecoder(req.Body).Decode(&firecrawlBody); err != nil {
+					t.Fatalf("decode firecrawl body: %v", err)
+				}
+				body = `{"data":[]}`
+			} else {
+				braveLang = req.URL.Query().Get("search_lang")
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	req := SearchRequest{Query: "switch", Market: jp}
+	if _, err := NewBraveProvider("key", "https://brave.test/res/v1/web/search", client).Search(context.Background(), req); err != nil {
+		t.Fatalf("brave search: %v", err)
+	}
+	if _, err := NewFirecrawlProvider("key", "https://firecrawl.test/v1/search", client).Search(context.Background(), req); err != nil {
+		t.Fatalf("firecrawl search: %v", err)
+	}
+	if braveLang != "jp" || firecrawlBody["lang"] != "ja" {
+		t.Fatalf("expected Brave jp and Firecrawl ja, got %q and %v", braveLang, firecrawlBody["lang"])
+	}
+}
@@ -0,0 +1,457 @@
+package api
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultMineSupport is how many distinct titles a cluster needs before
+	// it is proposed as a product.
+	DefaultMineSupport = 3
+	// DefaultMineSimilarity is the cosine similarity a title needs to join
+	// a cluster, and a query to become one of its synonyms.
+	DefaultMineSimilarity = 0.55
+	// minedCategory is the category given to proposed products, so they are
+	// easy to find when reviewing the catalog.
+	minedCategory = "Mined"
+	// nameShare is the share of a cluster's titles a word must appear in to
+	// be part of the proposed name.
+	nameShare = 0.6
+	// maxMinedSynonyms bounds the synonyms proposed for one product.
+	maxMinedSynonyms = 6
+)
+
+// listingNoise are words listing titles use about the sale rather than the
+// product; they never become part of a mined name.
+var listingNoise = map[string]struct{}{
+	"free": {}, "shipping": {}, "ship": {}, "fast": {}, "lot": {}, "tested": {},
+	"working": {}, "works": {}, "great": {}, "good": {}, "excellent": {},
+	"condition": {}, "mint": {}, "authentic": {}, "genuine": {}, "brand": {},
+	"preowned": {}, "pre": {}, "owned": {}, "refurbished": {}, "open": {},
+	"read": {}, "please": {}, "only": {}, "look": {}, "rare": {}, "htf": {},
+	"nib": {}, "nwt": {}, "includes": {}, "included": {}, "w": {}, "obo": {},
+}
+
+// MinedTitle is one listing title to mine products from, with the query
+// that found it and the product code it was learned for, when known.
+type MinedTitle struct {
+	Title      string
+	Query      string
+	Identifier string
+}
+
+// MineOptions tunes MineCatalog. Zero values use the defaults.
+type MineOptions struct {
+	MinSupport int
+	Similarity float64
+	Category   string
+	// Queries are past searches offered as synonyms of the cluster they
+	// are most similar to.
+	Queries []string
+	// Known is the catalog proposals are checked against; clusters that
+	// already resolve to one of its products are marked Existing.
+	Known *ProductIndex
+}
+
+// CatalogCandidate is a product proposed from a cluster of similar titles.
+type CatalogCandidate struct {
+	Entry    ProductEntry
+	Support  int    // distinct titles in the cluster
+	Sample   string // a representative title
+	Existing string // catalog product the cluster already resolves to
+}
+
+type minedDocument struct {
+	title  MinedTitle
+	words  []string // title words without punctuation or listing noise
+	vector map[string]float64
+}
+
+type titleCluster struct {
+	members  []int
+	centroid map[string]float64
+	norm     float64
+}
+
+// MineCatalog clusters listing titles by TF-IDF cosine similarity, the same
+// weighting the product index uses, and proposes a catalog product for each
+// cluster with enough titles: a canonical name from the words most of its
+// titles share, and synonyms from the queries that match it. Candidates are
+// ordered by support.
+func MineCatalog(titles []MinedTitle, opts MineOptions) []CatalogCandidate {
+	if opts.MinSupport <= 0 {
+		opts.MinSupport = DefaultMineSupport
+	}
+	if opts.Similarity <= 0 {
+		opts.Similarity = DefaultMineSimilarity
+	}
+	if strings.TrimSpace(opts.Category) == "" {
+		opts.Category = minedCategory
+	}
+
+	docs, idf := minedDocuments(titles)
+	clusters := clusterTitles(docs, opts.Similarity)
+
+	var out []CatalogCandidate
+	byCluster := map[int]int{}
+	for c, cluster := range clusters {
+		if len(cluster.members) < opts.MinSupport {
+			continue
+		}
+		candidate, ok := proposeProduct(docs, cluster, idf, opts)
+		if !ok {
+			continue
+		}
+		byCluster[c] = len(out)
+		out = append(out, candidate)
+	}
+
+	queries := append([]string(nil), opts.Queries...)
+	for _, doc := range docs {
+		queries = append(queries, doc.title.Query)
+	}
+	seen := map[string]struct{}{}
+	for _, query := range queries {
+		key := strings.Join(tokenize(query), " ")
+		if _, ok := seen[key]; ok || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		c, ok := bestCluster(clusters, titleVector(tokenize(query), idf), opts.Similarity)
+		if !ok {
+			continue
+		}
+		if i, ok := byCluster[c]; ok {
+			out[i].Entry.Synonyms = addSynonym(out[i].Entry, strings.ToLower(strings.Join(strings.Fields(query), " ")))
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Support != out[j].Support {
+			return out[i].Support > out[j].Support
+		}
+		return out[i].Entry.Name < out[j].Entry.Name
+	})
+	return out
+}
+
+// minedDocuments cleans and deduplicates titles and weights their tokens
+// by inverse document frequency across all of them.
+func minedDocuments(titles []MinedTitle) ([]minedDocument, map[string]float64) {
+	var docs []minedDocument
+	seen := map[string]int{}
+	df := map[string]int{}
+	tokens := [][]string{}
+	for _, title := range titles {
+		words := minedWords(title.Title)
+		key := strings.ToLower(strings.Join(words, " "))
+		if key == "" {
+			continue
+		}
+		if i, ok := seen[key]; ok {
+			if docs[i].title.Identifier == "" {
+				docs[i].title.Identifier = title.Identifier
+			}
+			continue
+		}
+		titleTokens := tokenize(strings.Join(words, " "))
+		if len(titleTokens) == 0 {
+			continue
+		}
+		seen[key] = len(docs)
+		docs = append(docs, minedDocument{title: title, words: words})
+		tokens = append(tokens, titleTokens)
+		unique := map[string]struct{}{}
+		for _, token := range titleTokens {
+			if _, ok := unique[token]; !ok {
+				unique[token] = struct{}{}
+				df[token]++
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	idf := make(map[string]float64, len(df))
+	for token, count := range df {
+		idf[token] = math.Log((1.0+n)/(1.0+float64(count))) + 1.0
+	}
+	for i := range docs {
+		docs[i].vector = titleVector(tokens[i], idf)
+	}
+	return docs, idf
+}
+
+// minedWords splits a listing title into words, leaving out marketplace
+// suffixes, prices, punctuation and words about the sale.
+func minedWords(title string) []string {
+	title = marketplaceSuffix.ReplaceAllString(strings.TrimSpace(title), "")
+	var words []string
+	for _, word := range strings.Fields(title) {
+		if strings.HasPrefix(word, "$") {
+			continue
+		}
+		word = titlePunctuationRe.ReplaceAllString(word, "")
+		tokens := tokenize(word)
+		if len(tokens) == 0 {
+			continue
+		}
+		noise := true
+		for _, token := range tokens {
+			if _, ok := listingNoise[token]; !ok {
+				noise = false
+			}
+		}
+		if !noise {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func titleVector(tokens []string, idf map[string]float64) map[string]float64 {
+	counts := map[string]float64{}
+	for _, token := range tokens {
+		counts[token]++
+	}
+	return normalizeVector(weightedVector(counts, idf))
+}
+
+// clusterTitles groups documents greedily: each joins the most similar
+// existing cluster when that is close enough, or starts a new one. Only
+// clusters sharing a term with the document are compared.
+func clusterTitles(docs []minedDocument, threshold float64) []*titleCluster {
+	order := make([]int, len(docs))
+	for i := range order {
+		order[i] = i
+	}
+	// Longer titles first, so clusters form around the fuller names.
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(docs[order[a]].vector) > len(docs[order[b]].vector)
+	})
+
+	var clusters []*titleCluster
+	postings := map[string][]int{}
+	for _, i := range order {
+		doc := docs[i]
+		c, ok := bestClusterFrom(clusters, postings, doc.vector, threshold)
+		if !ok {
+			c = len(clusters)
+			clusters = append(clusters, &titleCluster{centroid: map[string]float64{}})
+		}
+		cluster := clusters[c]
+		cluster.members = append(cluster.members, i)
+		for term, weight := range doc.vector {
+			if _, ok := cluster.centroid[term]; !ok {
+				postings[term] = append(postings[term], c)
+			}
+			cluster.centroid[term] += weight
+		}
+		norm := 0.0
+		for _, weight := range cluster.centroid {
+			norm += weight * weight
+		}
+		cluster.norm = math.Sqrt(norm)
+	}
+	return clusters
+}
+
+func bestClusterFrom(clusters []*titleCluster, postings map[string][]int, vector map[string]float64, threshold float64) (int, bool) {
+	candidates := map[int]struct{}{}
+	for term := range vector {
+		for _, c := range postings[term] {
+			candidates[c] = struct{}{}
+		}
+	}
+	best, bestScore := -1, threshold
+	for c := range candidates {
+		score := clusters[c].similarity(vector)
+		if score > bestScore || (score == bestScore && best >= 0 && c < best) {
+			best, bestScore = c, score
+		}
+	}
+	return best, best >= 0
+}
+
+func bestCluster(clusters []*titleCluster, vector map[string]float64, threshold float64) (int, bool) {
+	best, bestScore := -1, threshold
+	for c, cluster := range clusters {
+		if score := cluster.similarity(vector); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best, best >= 0
+}
+
+func (c *titleCluster) similarity(vector map[string]float64) float64 {
+	if c.norm == 0 {
+		return 0
+	}
+	dot := 0.0
+	for term, weight := range vector {
+		dot += weight * c.centroid[term]
+	}
+	return dot / c.norm
+}
+
+// proposeProduct names a cluster after the words most of its titles share,
+// with each word in its most common spelling.
+func proposeProduct(docs []minedDocument, cluster *titleCluster, idf map[string]float64, opts MineOptions) (CatalogCandidate, bool) {
+	titles := make([][]string, 0, len(cluster.members))
+	spellings := map[string]map[string]int{}
+	var identifiers []string
+	for _, i := range cluster.members {
+		titles = append(titles, docs[i].words)
+		for _, word := range docs[i].words {
+			lower := strings.ToLower(word)
+			if spellings[lower] == nil {
+				spellings[lower] = map[string]int{}
+			}
+			spellings[lower][word]++
+		}
+		if code := strings.TrimSpace(docs[i].title.Identifier); code != "" && validateIdentifiers([]string{code}) == nil {
+			identifiers = append(identifiers, code)
+		}
+	}
+
+	threshold := max(2, int(math.Ceil(nameShare*float64(len(titles)))))
+	words := commonTitleWords(titles, threshold)
+	if len(words) < 2 {
+		return CatalogCandidate{}, false
+	}
+	if len(words) > maxIdentifierTitleWords {
+		words = words[:maxIdentifierTitleWords]
+	}
+	for i, word := range words {
+		words[i] = preferredSpelling(spellings[strings.ToLower(word)], word)
+	}
+
+	entry := sanitizeEntry(ProductEntry{
+		Name:        strings.Join(words, " "),
+		Category:    opts.Category,
+		Identifiers: identifiers,
+	})
+	if alias := distinctiveAlias(words, idf); alias != "" {
+		entry.Synonyms = addSynonym(entry, alias)
+	}
+
+	candidate := CatalogCandidate{
+		Entry:   entry,
+		Support: len(cluster.members),
+		Sample:  docs[cluster.members[0]].title.Title,
+	}
+	if existing, ok := opts.Known.containedProduct(entry.Name); ok {
+		candidate.Existing = existing
+	}
+	return candidate, true
+}
+
+// containedProduct returns the most specific catalog product whose name
+// words all appear in name, so "Nintendo Switch OLED Console" is known as
+// "Nintendo Switch OLED".
+func (idx *ProductIndex) containedProduct(name string) (string, bool) {
+	if idx == nil {
+		return "", false
+	}
+	words := tokenPattern.FindAllString(strings.ToLower(name), -1)
+	counts := map[string]float64{}
+	for _, token := range tokenize(name) {
+		counts[token]++
+	}
+	scores := idx.acquireScores()
+	defer idx.releaseScores(scores)
+	idx.similarities(normalizeVector(weightedVector(counts, idx.idf)), scores)
+
+	best, bestWords := -1, 0
+	for _, i := range scores.touched {
+		product := idx.products[i]
+		if n := len(product.nameTokens); n < bestWords || (n == bestWords && i > best) {
+			continue
+		}
+		contained := true
+		for _, token := range product.nameTokens {
+			if !slices.Contains(words, token) {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			best, bestWords = i, len(product.nameTokens)
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	return idx.products[best].entry.Name, true
+}
+
+// preferredSpelling picks the most common spelling of a word, preferring
+// mixed case over all caps when they tie.
+func preferredSpelling(spellings map[string]int, fallback string) string {
+	options := make([]string, 0, len(spellings))
+	for spelling := range spellings {
+		options = append(options, spelling)
+	}
+	if len(options) == 0 {
+		return fallback
+	}
+	allCaps := func(s string) bool { return strings.ToUpper(s) == s && strings.ToLower(s) != s }
+	sort.Slice(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if spellings[a] != spellings[b] {
+			return spellings[a] > spellings[b]
+		}
+		if allCaps(a) != allCaps(b) {
+			return !allCaps(a)
+		}
+		return a < b
+	})
+	return options[0]
+}
+
+// distinctiveAlias is the two rarest words of a longer name, in name order,
+// such as "pyrex 443" for "Pyrex 443 Primary Mixing Bowl".
+func distinctiveAlias(words []string, idf map[string]float64) string {
+	if len(words) < 3 {
+		return ""
+	}
+	type ranked struct {
+		position int
+		weight   float64
+	}
+	var tokens []ranked
+	for i, word := range words {
+		weight := 0.0
+		for _, token := range tokenize(word) {
+			weight = max(weight, idf[token])
+		}
+		if weight > 0 {
+			tokens = append(tokens, ranked{position: i, weight: weight})
+		}
+	}
+	if len(tokens) < 3 {
+		return ""
+	}
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].weight > tokens[j].weight })
+	first, second := tokens[0].position, tokens[1].position
+	if first > second {
+		first, second = second, first
+	}
+	return strings.ToLower(words[first] + " " + words[second])
+}
+
+// addSynonym appends synonym unless it repeats the name or another synonym,
+// up to maxMinedSynonyms.
+func addSynonym(entry ProductEntry, synonym string) []string {
+	if synonym == "" || len(entry.Synonyms) >= maxMinedSynonyms || strings.EqualFold(synonym, entry.Name) {
+		return entry.Synonyms
+	}
+	for _, existing := range entry.Synonyms {
+		if strings.EqualFold(existing, synonym) {
+			return entry.Synonyms
+		}
+	}
+	return append(entry.Synonyms, synonym)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMineCatalogProposesProductsFromSimilarTitles(t *testing.T) {
+	titles := []MinedTitle{
+		{Title: "Pyrex 443 Primary Mixing Bowl Yellow 2.5 qt - eBay", Query: "pyrex 443"},
+		{Title: "VINTAGE PYREX 443 PRIMARY YELLOW MIXING BOWL", Query: "pyrex 443"},
+		{Title: "Pyrex 443 yellow primary mixing bowl FREE SHIPPING $24.99", Query: "pyrex yellow bowl"},
+		{Title: "Pyrex Primary 443 Yellow Mixing Bowl tested", Query: "pyrex 443", Identifier: "036000291452"},
+		{Title: "Le Creuset Dutch Oven 5.5 qt Flame", Query: "le creuset"},
+		{Title: "Le Creuset Signature Dutch Oven Flame 5.5qt", Query: "le creuset"},
+		{Title: "Random garage sale item"},
+	}
+
+	got := MineCatalog(titles, MineOptions{Queries: []string{"Pyrex Yellow Mixing Bowl", "dutch oven"}})
+	if len(got) != 1 {
+		t.Fatalf("expected one candidate with enough support, got %+v", got)
+	}
+	candidate := got[0]
+	if candidate.Support != 4 {
+		t.Fatalf("expected 4 supporting titles, got %d", candidate.Support)
+	}
+	if candidate.Entry.Name != "Pyrex 443 Primary Mixing Bowl Yellow" {
+		t.Fatalf("unexpected canonical name %q", candidate.Entry.Name)
+	}
+	if candidate.Entry.Category != "Mined" || !slices.Equal(candidate.Entry.Identifiers, []string{"036000291452"}) {
+		t.Fatalf("unexpected category or identifiers: %+v", candidate.Entry)
+	}
+	for _, want := range []string{"pyrex 443", "pyrex yellow mixing bowl", "pyrex yellow bowl"} {
+		if !slices.Contains(candidate.Entry.Synonyms, want) {
+			t.Fatalf("expected synonym %q in %v", want, candidate.Entry.Synonyms)
+		}
+	}
+	if err := validateCatalogEntry(candidate.Entry); err != nil {
+		t.Fatalf("expected a loadable entry, got %v", err)
+	}
+
+	got = MineCatalog(titles, MineOptions{MinSupport: 2})
+	if len(got) != 2 || got[1].Entry.Name != "Le Creuset Dutch Oven Flame" {
+		t.Fatalf("expected a second candidate at lower support, got %+v", got)
+	}
+}
+
+func TestMineCatalogMarksExistingProducts(t *testing.T) {
+	titles := []MinedTitle{
+		{Title: "Nintendo Switch OLED White Console"},
+		{Title: "Nintendo Switch OLED Console Neon"},
+		{Title: "Nintendo Switch OLED console bundle"},
+	}
+	got := MineCatalog(titles, MineOptions{Known: NewProductIndex()})
+	if len(got) != 1 || got[0].Existing != "Nintendo Switch OLED" {
+		t.Fatalf("expected the cluster to resolve to the catalog product, got %+v", got)
+	}
+}
@@ -6,61 +6,153 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
 	pricePatternSymbolPrefix = regexp.MustCompile(`(?i)(?:\busd\b|us\s*\$|us\$|\$)\s*(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?`)
 	pricePatternUSDSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\s*\busd\b`)
 	pricePatternContext      = regexp.MustCompile(`(?i)\b(?:price|asking|ask|obo|offer|now|for)\s*[:\-]?\s*(\d{1,3}(?:,\d{3})+|\d{2,})(?:\.(\d{1,2}))?\b`)
-	conditionNewPattern      = regexp.MustCompile(`\bnew\b`)
-	conditionSealedPattern   = regexp.MustCompile(`\bsealed\b`)
-	conditionGoodPattern     = regexp.MustCompile(`\bgood\b`)
-	conditionFairPattern     = regexp.MustCompile(`\bfair\b`)
+	pricePatternGBPPrefix    = regexp.MustCompile(`(?i)(?:£|\bgbp\b)\s*(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?`)
+	pricePatternGBPSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\s*\bgbp\b`)
+	pricePatternCADPrefix    = regexp.MustCompile(`(?i)(?:\bcad\b\s*\$?|ca?\$|\$)\s*(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?`)
+	pricePatternCADSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\s*\bcad\b`)
+	pricePatternAUDPrefix    = regexp.MustCompile(`(?i)(?:\baud\b\s*\$?|au?\$|\$)\s*(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?`)
+	pricePatternAUDSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\s*\baud\b`)
+	pricePatternEURPrefix    = regexp.MustCompile(`(?i)(?:€|\beur\b)\s*(\d{1,3}(?:\.\d{3})+|\d+)(?:,(\d{1,2}))?`)
+	pricePatternEURSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:\.\d{3})+|\d+)(?:,(\d{1,2}))?\s*(?:€|\beur\b)`)
+	pricePatternJPYPrefix    = regexp.MustCompile(`(?i)(?:¥|￥|\bjpy\b)\s*(\d{1,3}(?:,\d{3})+|\d+)`)
+	pricePatternJPYSuffix    = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+)\s*(?:円|\bjpy\b)`)
 	statusSoldPattern        = regexp.MustCompile(`\bsold\b`)
 	statusUnsoldPattern      = regexp.MustCompile(`\b(?:not\s+sold|unsold|never\s+sold)\b`)
 )
 
-// SearchResult normalizes provider payload fields for parsing.
-type SearchResult struct {
-	URL         string `json:"url"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+// maxRawContentScan bounds how much page text is scanned for prices.
+const maxRawContentScan = 600
+
+// priceContextRadius is how many bytes around a price match are kept as context.
+const priceContextRadius = 32
+
+// Price candidate sources, in the order the parser trusts them.
+const (
+	PriceSourceOffer   = "offer"
+	PriceSourceSnippet = "snippet"
+	PriceSourcePage    = "page"
+)
+
+// ParseOptions adjusts parsing for a market. The zero value parses USD.
+type ParseOptions struct {
+	Currency string      // ISO 4217 code listings are expected to be priced in
+	Now      time.Time   // reference for relative ages; zero means time.Now
+	Rules    *ParseRules // user rules checked ahead of the built-in patterns
+}
+
+// pricePattern names a price regex so candidates can say which rule matched.
+type pricePattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// currencyFormat holds the price patterns for one currency. Patterns are
+// tried in order; decimalComma marks formats like "1.299,00 €".
+type currencyFormat struct {
+	patterns     []pricePattern
+	decimalComma bool
+}
+
+var pricePatternContextRule = pricePattern{"context", pricePatternContext}
+
+var currencyFormats = map[string]currencyFormat{
+	"USD": {patterns: []pricePattern{{"symbol-prefix", pricePatternSymbolPrefix}, {"usd-suffix", pricePatternUSDSuffix}, pricePatternContextRule}},
+	"GBP": {patterns: []pricePattern{{"gbp-prefix", pricePatternGBPPrefix}, {"gbp-suffix", pricePatternGBPSuffix}, pricePatternContextRule}},
+	"CAD": {patterns: []pricePattern{{"cad-prefix", pricePatternCADPrefix}, {"cad-suffix", pricePatternCADSuffix}, pricePatternContextRule}},
+	"AUD": {patterns: []pricePattern{{"aud-prefix", pricePatternAUDPrefix}, {"aud-suffix", pricePatternAUDSuffix}, pricePatternContextRule}},
+	"EUR": {patterns: []pricePattern{{"eur-prefix", pricePatternEURPrefix}, {"eur-suffix", pricePatternEURSuffix}}, decimalComma: true},
+	"JPY": {patterns: []pricePattern{{"jpy-prefix", pricePatternJPYPrefix}, {"jpy-suffix", pricePatternJPYSuffix}}},
+}
+
+func (o ParseOptions) currency() string {
+	code := strings.ToUpper(strings.TrimSpace(o.Currency))
+	if _, ok := currencyFormats[code]; !ok {
+		return "USD"
+	}
+	return code
 }
 
-// ParseSearchResults extracts listing data from search results.
+// SearchResult normalizes provider payload fields for parsing.
+type SearchResult = types.SearchResult
+
+// ParseSearchResults extracts listing data from search results priced in USD.
 func ParseSearchResults(data []SearchResult) []types.Listing {
+	return ParseSearchResultsWith(data, ParseOptions{})
+}
+
+// ParseSearchResultsWith extracts listing data using market-specific options.
+func ParseSearchResultsWith(data []SearchResult, opts ParseOptions) []types.Listing {
+	format := currencyFormats[opts.currency()]
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
 	listings := make([]types.Listing, 0, len(data))
 
 	for _, item := range data {
+		identity := IdentifyListing(item.URL)
 		listing := types.Listing{
-			URL:   item.URL,
-			Title: item.Title,
+			URL:      identity.URL,
+			ID:       identity.Key(),
+			Title:    item.Title,
+			Platform: identity.Platform,
 		}
 
-		listing.Platform = detectPlatform(item.URL)
+		text := searchResultText(item)
+		rules := opts.Rules.forPlatform(identity.Platform)
+		if rules.ignores(text) {
+			continue
+		}
 
-		text := item.Title + " " + item.Description
-		if price, ok := extractBestPrice(text); ok {
-			listing.Price = price
+		excerpt := rawContentExcerpt(item.RawContent)
+		ruleFormat := currencyFormat{patterns: rules.price, decimalComma: format.decimalComma}
+		ruled := append(ruleFormat.priceMatches(text, PriceSourceSnippet), ruleFormat.priceMatches(excerpt, PriceSourcePage)...)
+		offers := offerCandidates(item.Prices)
+		snippets := format.priceMatches(text, PriceSourceSnippet)
+		page := format.priceMatches(excerpt, PriceSourcePage)
+		groups := [][]priceMatch{ruled, offers, snippets, page}
+		for _, group := range groups {
+			if price, ok := rules.pickCandidate(group); ok {
+				listing.Price = price
+				break
+			}
 		}
+		listing.PriceCandidates = dedupeCandidates(groups...)
+		source := item
+		source.RawContent = excerpt
+		listing.Source = &source
 
 		if listing.Price == 0 {
 			continue
 		}
 
+		if date, ok := extractListingDate(text, item.Age, now); ok {
+			listing.Date = date
+		}
+
 		textLower := strings.ToLower(text)
-		switch {
-		case conditionNewPattern.MatchString(textLower) || conditionSealedPattern.MatchString(textLower):
-			listing.Condition = "New"
-		case conditionGoodPattern.MatchString(textLower):
-			listing.Condition = "Good"
-		case conditionFairPattern.MatchString(textLower):
-			listing.Condition = "Fair"
-		default:
-			listing.Condition = "Used"
-		}
-
-		if statusUnsoldPattern.MatchString(textLower) {
+		if condition, ok := rules.matchCondition(text); ok {
+			listing.Condition, listing.ConditionConfidence = condition, ruleConditionConfidence
+		} else {
+			listing.Condition, listing.ConditionConfidence = classifyCondition(textLower)
+		}
+		listing.Variant = extractVariant(item.Title, text)
+		listing.Quantity, listing.Bundle = extractLot(strings.ToLower(item.Title))
+		if isLocalPickup(item.URL) {
+			listing.Location = extractLocation(text, item.URL)
+		}
+
+		if status, ok := rules.matchStatus(text); ok {
+			listing.Status = status
+		} else if statusUnsoldPattern.MatchString(textLower) {
 			listing.Status = "Active"
 		} else if statusSoldPattern.MatchString(textLower) {
 			listing.Status = "Sold"
@@ -74,32 +166,200 @@ func ParseSearchResults(data []SearchResult) []types.Listing {
 	return listings
 }
 
+// searchResultText joins the snippet fields used for price, condition and status extraction.
+func searchResultText(item SearchResult) string {
+	parts := make([]string, 0, 2+len(item.ExtraSnippets))
+	parts = append(parts, item.Title, item.Description)
+	for _, snippet := range item.ExtraSnippets {
+		if trimmed := strings.TrimSpace(snippet); trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// rawContentExcerpt returns the leading part of a page body. Full pages carry
+// shipping costs and related items, so only the top of the page is trusted.
+func rawContentExcerpt(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) <= maxRawContentScan {
+		return trimmed
+	}
+	cut := maxRawContentScan
+	for cut > 0 && !utf8.RuneStart(trimmed[cut]) {
+		cut--
+	}
+	return trimmed[:cut]
+}
+
+// priceMatch is a price candidate with the clause leading up to it, which
+// rule keyword priorities are checked against.
+type priceMatch struct {
+	candidate types.PriceCandidate
+	lead      string
+}
+
+// offerCandidates turns structured provider offer prices into candidates.
+func offerCandidates(values []float64) []priceMatch {
+	out := make([]priceMatch, 0, len(values))
+	for _, value := range values {
+		if value <= 0 {
+			continue
+		}
+		out = append(out, priceMatch{candidate: types.PriceCandidate{
+			Amount:  value,
+			Source:  PriceSourceOffer,
+			Pattern: "structured",
+			Context: "provider offer price",
+		}})
+	}
+	return out
+}
+
+func lowestCandidate(matches []priceMatch) (float64, bool) {
+	return platformRules{}.pickCandidate(matches)
+}
+
+// dedupeCandidates concatenates candidate groups, keeping the first of any
+// repeated amount, source and pattern.
+func dedupeCandidates(groups ...[]priceMatch) []types.PriceCandidate {
+	var out []types.PriceCandidate
+	seen := make(map[types.PriceCandidate]struct{})
+	for _, group := range groups {
+		for _, match := range group {
+			key := match.candidate
+			key.Context = ""
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, match.candidate)
+		}
+	}
+	return out
+}
+
 // extractBestPrice returns the lowest positive USD amount found in the text.
 // This helps pick current prices in snippets like "Was $150, now $99".
 func extractBestPrice(text string) (float64, bool) {
-	best := 0.0
-	found := false
+	return currencyFormats["USD"].extractBestPrice(text)
+}
 
-	patterns := []*regexp.Regexp{
-		pricePatternSymbolPrefix,
-		pricePatternUSDSuffix,
-		pricePatternContext,
-	}
-	for _, pattern := range patterns {
-		matches := pattern.FindAllStringSubmatch(text, -1)
-		for _, match := range matches {
+// extractBestPrice returns the lowest positive amount in the format's currency.
+func (f currencyFormat) extractBestPrice(text string) (float64, bool) {
+	return lowestCandidate(f.priceMatches(text, PriceSourceSnippet))
+}
+
+// priceMatches returns every positive amount the format's patterns match in
+// text, with the matching pattern, the surrounding text and the leading clause.
+func (f currencyFormat) priceMatches(text, source string) []priceMatch {
+	var out []priceMatch
+	for _, pattern := range f.patterns {
+		for _, loc := range pattern.re.FindAllStringSubmatchIndex(text, -1) {
+			match := submatchStrings(text, loc)
+			if f.decimalComma {
+				match = normalizeDecimalComma(match)
+			}
 			price, ok := parsePriceMatch(match)
 			if !ok {
 				continue
 			}
-			if !found || price < best {
-				best = price
-				found = true
-			}
+			out = append(out, priceMatch{
+				candidate: types.PriceCandidate{
+					Amount:  price,
+					Source:  source,
+					Pattern: pattern.name,
+					Context: matchContext(text, loc[0], loc[1]),
+				},
+				lead: matchLead(text, loc[0]),
+			})
 		}
 	}
+	return out
+}
 
-	return best, found
+// matchLead returns the clause before text[start:], up to priceContextRadius
+// bytes back and cut at the nearest clause separator.
+func matchLead(text string, start int) string {
+	from := max(0, start-priceContextRadius)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	lead := text[from:start]
+	if cut := strings.LastIndexAny(lead, ",;|\n"); cut >= 0 {
+		lead = lead[cut+1:]
+	}
+	return strings.TrimSpace(lead)
+}
+
+// submatchStrings expands a FindStringSubmatchIndex result into strings;
+// unmatched groups become empty.
+func submatchStrings(text string, loc []int) []string {
+	out := make([]string, len(loc)/2)
+	for i := range out {
+		if start, end := loc[2*i], loc[2*i+1]; start >= 0 && end >= 0 {
+			out[i] = text[start:end]
+		}
+	}
+	return out
+}
+
+// matchContext returns the text around text[start:end] on one line, marking
+// truncated ends with an ellipsis.
+func matchContext(text string, start, end int) string {
+	from := max(0, start-priceContextRadius)
+	to := min(len(text), end+priceContextRadius)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	excerpt := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(text) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// normalizeDecimalComma rewrites a "1.299" / "00" match into the comma
+// thousands form parsePriceMatch expects.
+func normalizeDecimalComma(match []string) []string {
+	out := append([]string(nil), match...)
+	if len(out) > 1 {
+		out[1] = strings.ReplaceAll(out[1], ".", ",")
+	}
+	return out
+}
+
+// parseStructuredPrice reads a provider offer price such as "299.99" or "$1,099".
+// Offers in another currency than expected (USD when empty) are skipped
+// rather than misread.
+func parseStructuredPrice(raw, currency, expected string) (float64, bool) {
+	expected = ParseOptions{Currency: expected}.currency()
+	currency = strings.TrimSpace(currency)
+	if currency != "" && !strings.EqualFold(currency, expected) {
+		return 0, false
+	}
+	// Structured prices use a decimal point; drop symbols and grouping commas.
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' {
+			return r
+		}
+		return -1
+	}, raw)
+	if cleaned == "" {
+		return 0, false
+	}
+	price, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || price <= 0 {
+		return 0, false
+	}
+	return price, true
 }
 
 func parsePriceMatch(match []string) (float64, bool) {
@@ -1,6 +1,10 @@
 package api
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
 
 func TestParseSearchResults(t *testing.T) {
 	data := []SearchResult{
@@ -33,8 +37,8 @@ func TestParseSearchResults(t *testing.T) {
 	if first.Price != 1299.99 {
 		t.Fatalf("expected first price 1299.99, got %v", first.Price)
 	}
-	if first.Condition != "New" {
-		t.Fatalf("expected first condition New, got %q", first.Condition)
+	if first.Condition != "Sealed" {
+		t.Fatalf("expected first condition Sealed, got %q", first.Condition)
 	}
 	if first.Status != "Sold" {
 		t.Fatalf("expected first status Sold, got %q", first.Status)
@@ -183,3 +187,169 @@ func TestParseSearchResultsSoldNegationStaysActive(t *testing.T) {
 		t.Fatalf("expected negated sold status to remain Active, got %q", got[0].Status)
 	}
 }
+
+func TestParseSearchResultsPrefersStructuredPrices(t *testing.T) {
+	data := []SearchResult{
+		{
+			URL:         "https://www.amazon.com/dp/1",
+			Title:       "Headphones",
+			Description: "Save $50 today",
+			Prices:      []float64{0, 279.99, 299.99},
+		},
+		{
+			URL:         "https://www.ebay.com/itm/2",
+			Title:       "Headphones",
+			Description: "no amount in snippet",
+			RawContent:  strings.Repeat("filler ", 200) + "$10",
+		},
+	}
+
+	got := ParseSearchResults(data)
+	if len(got) != 1 {
+		t.Fatalf("expected only the structured-price result, got %d", len(got))
+	}
+	if got[0].Price != 279.99 {
+		t.Fatalf("expected lowest structured price 279.99, got %v", got[0].Price)
+	}
+}
+
+func TestRawContentExcerptKeepsWholeRunes(t *testing.T) {
+	// "中" is three bytes, so the scan limit falls inside one.
+	raw := "a" + strings.Repeat("中", maxRawContentScan)
+	got := rawContentExcerpt(raw)
+	if !utf8.ValidString(got) || len(got) > maxRawContentScan || len(got) < maxRawContentScan-2 {
+		t.Fatalf("expected a valid excerpt just under %d bytes, got %d bytes valid=%v", maxRawContentScan, len(got), utf8.ValidString(got))
+	}
+}
+
+func TestParseSearchResultsWithMarketCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		text     string
+		want     float64
+	}{
+		{currency: "GBP", text: "PS5 Slim £349.99 was $499", want: 349.99},
+		{currency: "EUR", text: "PS5 Slim für 1.299,50 € gebraucht", want: 1299.50},
+		{currency: "EUR", text: "PS5 Slim EUR 449", want: 449},
+		{currency: "JPY", text: "PS5 中古 ¥54,800", want: 54800},
+		{currency: "JPY", text: "PS5 中古 49800円", want: 49800},
+		{currency: "CAD", text: "PS5 Slim C$529.99", want: 529.99},
+		{currency: "AUD", text: "PS5 Slim AU$649", want: 649},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.currency+" "+tc.text, func(t *testing.T) {
+			got := ParseSearchResultsWith([]SearchResult{
+				{URL: "https://www.ebay.co.uk/itm/1", Title: tc.text},
+			}, ParseOptions{Currency: tc.currency})
+			if len(got) != 1 {
+				t.Fatalf("expected one listing, got %d", len(got))
+			}
+			if got[0].Price != tc.want {
+				t.Fatalf("expected price %.2f, got %.2f", tc.want, got[0].Price)
+			}
+		})
+	}
+}
+
+func TestParseSearchResultsWithIgnoresOtherCurrencySymbols(t *testing.T) {
+	got := ParseSearchResultsWith([]SearchResult{
+		{URL: "https://www.ebay.de/itm/1", Title: "PS5 Slim $399"},
+	}, ParseOptions{Currency: "EUR"})
+	if len(got) != 0 {
+		t.Fatalf("expected USD-only snippet to be skipped in EUR market, got %+v", got)
+	}
+}
+
+func TestParseStructuredPriceChecksExpectedCurrency(t *testing.T) {
+	if _, ok := parseStructuredPrice("299.99", "USD", "GBP"); ok {
+		t.Fatal("expected USD offer to be skipped in GBP market")
+	}
+	if price, ok := parseStructuredPrice("£1,099.00", "GBP", "GBP"); !ok || price != 1099 {
+		t.Fatalf("expected GBP offer 1099, got %.2f (ok=%v)", price, ok)
+	}
+	if price, ok := parseStructuredPrice("$45", "", ""); !ok || price != 45 {
+		t.Fatalf("expected default USD offer 45, got %.2f (ok=%v)", price, ok)
+	}
+}
+
+func TestParseSearchResultsRecordsLotsAndBundles(t *testing.T) {
+	got := ParseSearchResults([]SearchResult{
+		{URL: "https://www.ebay.com/itm/1", Title: "Lot of 5 Pokemon Booster Packs $60"},
+		{URL: "https://www.ebay.com/itm/2", Title: "PS5 + 2 controllers + 3 games $520"},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 listings, got %d", len(got))
+	}
+	if got[0].Quantity != 5 || got[0].UnitPrice() != 12 {
+		t.Fatalf("expected lot of 5 at $12 each, got qty=%d unit=%.2f", got[0].Quantity, got[0].UnitPrice())
+	}
+	if !got[1].Bundle || got[1].Units() != 1 {
+		t.Fatalf("expected single-unit bundle, got %+v", got[1])
+	}
+}
+
+func TestParseSearchResultsKeepsPriceCandidates(t *testing.T) {
+	got := ParseSearchResults([]SearchResult{{
+		URL:         "https://www.ebay.com/itm/123456789012",
+		Title:       "Switch OLED console",
+		Description: "Was $349.99, now $299.99 with free shipping",
+		RawContent:  "Buy It Now price 310 USD. Shipping $15.00",
+	}})
+	if len(got) != 1 {
+		t.Fatalf("expected 1 listing, got %d", len(got))
+	}
+
+	listing := got[0]
+	if listing.Price != 299.99 {
+		t.Fatalf("expected snippet price 299.99, got %v", listing.Price)
+	}
+	want := []struct {
+		amount  float64
+		source  string
+		pattern string
+	}{
+		{349.99, PriceSourceSnippet, "symbol-prefix"},
+		{299.99, PriceSourceSnippet, "symbol-prefix"},
+		{15, PriceSourcePage, "symbol-prefix"},
+		{310, PriceSourcePage, "usd-suffix"},
+		{310, PriceSourcePage, "context"},
+	}
+	if len(listing.PriceCandidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %+v", len(want), listing.PriceCandidates)
+	}
+	for i, w := range want {
+		c := listing.PriceCandidates[i]
+		if c.Amount != w.amount || c.Source != w.source || c.Pattern != w.pattern {
+			t.Fatalf("candidate %d: expected %v/%s/%s, got %+v", i, w.amount, w.source, w.pattern, c)
+		}
+	}
+	if ctx := listing.PriceCandidates[0].Context; !strings.Contains(ctx, "Was $349.99, now") {
+		t.Fatalf("expected surrounding text in context, got %q", ctx)
+	}
+}
+
+func TestParseSearchResultsPrefersOfferCandidates(t *testing.T) {
+	got := ParseSearchResults([]SearchResult{{
+		URL:         "https://www.amazon.com/dp/B0ABCDEFGH",
+		Title:       "Switch OLED",
+		Description: "List Price: $349.99",
+		Prices:      []float64{319.99},
+	}})
+	if len(got) != 1 || got[0].Price != 319.99 {
+		t.Fatalf("expected offer price 319.99, got %+v", got)
+	}
+	first := got[0].PriceCandidates[0]
+	if first.Source != PriceSourceOffer || first.Pattern != "structured" {
+		t.Fatalf("expected offer candidate first, got %+v", first)
+	}
+}
+
+func TestMatchContextTrimsAroundMatch(t *testing.T) {
+	text := strings.Repeat("a", 50) + " price $20 " + strings.Repeat("b", 50)
+	start := strings.Index(text, "$20")
+	got := matchContext(text, start, start+3)
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "price $20") {
+		t.Fatalf("unexpected context %q", got)
+	}
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mrktr/types"
+)
+
+// QueryTokenKind classifies one span of search-box text.
+type QueryTokenKind int
+
+const (
+	QueryTokenSpace QueryTokenKind = iota
+	QueryTokenTerm
+	QueryTokenPhrase
+	QueryTokenExclude
+	QueryTokenField
+	QueryTokenPrice
+	QueryTokenInvalid
+)
+
+// QueryToken is a span of the raw query; concatenating every token's Text
+// reproduces the input exactly.
+type QueryToken struct {
+	Kind QueryTokenKind
+	Text string
+	Err  error
+}
+
+// Query is a parsed search-box query.
+//
+// Supported syntax: bare terms, "exact phrase", -exclude, platform:ebay,
+// cond:new, status:sold and $100..$300 (either bound may be omitted).
+// cond:new, cond:refurbished and cond:used match their whole condition family.
+type Query struct {
+	Terms     []string
+	Phrases   []string
+	Exclude   []string
+	Platform  string
+	Condition string
+	Status    string
+	MinPrice  float64
+	MaxPrice  float64
+}
+
+type queryPlatform struct {
+	name   string
+	domain string
+}
+
+var queryPlatforms = map[string]queryPlatform{
+	"ebay":     {name: "eBay", domain: "ebay.com"},
+	"mercari":  {name: "Mercari", domain: "mercari.com"},
+	"amazon":   {name: "Amazon", domain: "amazon.com"},
+	"facebook": {name: "Facebook", domain: "facebook.com"},
+}
+
+var queryConditions = map[string]string{
+	"sealed":      types.ConditionSealed,
+	"new":         types.ConditionNew,
+	"openbox":     types.ConditionOpenBox,
+	"open-box":    types.ConditionOpenBox,
+	"likenew":     types.ConditionLikeNew,
+	"like-new":    types.ConditionLikeNew,
+	"certified":   types.ConditionCertifiedRefurbished,
+	"refurbished": types.ConditionRefurbished,
+	"used":        types.ConditionUsed,
+	"good":        types.ConditionGood,
+	"fair":        types.ConditionFair,
+	"parts":       types.ConditionForParts,
+}
+
+var queryStatuses = map[string]string{
+	"active": "Active",
+	"sold":   "Sold",
+}
+
+// ParseQuery parses raw search-box text. The returned error describes the
+// first invalid token; the partially parsed query is still returned.
+func ParseQuery(raw string) (Query, error) {
+	var q Query
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, token := range TokenizeQuery(raw) {
+		if token.Err != nil {
+			fail(token.Err)
+			continue
+		}
+		switch token.Kind {
+		case QueryTokenTerm:
+			q.Terms = append(q.Terms, token.Text)
+		case QueryTokenPhrase:
+			q.Phrases = append(q.Phrases, strings.TrimSpace(strings.Trim(token.Text, `"`)))
+		case QueryTokenExclude:
+			q.Exclude = append(q.Exclude, strings.ToLower(token.Text[1:]))
+		case QueryTokenField:
+			if err := q.setField(token.Text); err != nil {
+				fail(err)
+			}
+		case QueryTokenPrice:
+			q.MinPrice, q.MaxPrice, _ = parsePriceRange(token.Text)
+		}
+	}
+
+	if firstErr == nil && len(q.Terms) == 0 && len(q.Phrases) == 0 {
+		firstErr = fmt.Errorf("add at least one search term")
+	}
+	return q, firstErr
+}
+
+// TokenizeQuery splits raw into classified spans for parsing and highlighting.
+func TokenizeQuery(raw string) []QueryToken {
+	runes := []rune(raw)
+	tokens := make([]QueryToken, 0, 8)
+	for i := 0; i < len(runes); {
+		start := i
+		switch {
+		case unicode.IsSpace(runes[i]):
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, QueryToken{Kind: QueryTokenSpace, Text: string(runes[start:i])})
+		case runes[i] == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			if i >= len(runes) {
+				tokens = append(tokens, QueryToken{
+					Kind: QueryTokenInvalid,
+					Text: string(runes[start:]),
+					Err:  fmt.Errorf("unterminated quote"),
+				})
+				continue
+			}
+			i++
+			tokens = append(tokens, classifyPhrase(string(runes[start:i])))
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, classifyWord(string(runes[start:i])))
+		}
+	}
+	return tokens
+}
+
+func classifyPhrase(text string) QueryToken {
+	if strings.TrimSpace(strings.Trim(text, `"`)) == "" {
+		return QueryToken{Kind: QueryTokenInvalid, Text: text, Err: fmt.Errorf("empty phrase")}
+	}
+	return QueryToken{Kind: QueryTokenPhrase, Text: text}
+}
+
+func classifyWord(text string) QueryToken {
+	switch {
+	case strings.HasPrefix(text, "-") && len(text) > 1:
+		return QueryToken{Kind: QueryTokenExclude, Text: text}
+	case strings.HasPrefix(text, "$") || strings.HasPrefix(text, "..$"):
+		if _, _, err := parsePriceRange(text); err != nil {
+			return QueryToken{Kind: QueryTokenInvalid, Text: text, Err: err}
+		}
+		return QueryToken{Kind: QueryTokenPrice, Text: text}
+	}
+
+	if key, value, ok := strings.Cut(text, ":"); ok && isQueryField(key) {
+		if err := validateField(key, value); err != nil {
+			return QueryToken{Kind: QueryTokenInvalid, Text: text, Err: err}
+		}
+		return QueryToken{Kind: QueryTokenField, Text: text}
+	}
+	return QueryToken{Kind: QueryTokenTerm, Text: text}
+}
+
+func isQueryField(key string) bool {
+	switch strings.ToLower(key) {
+	case "platform", "cond", "condition", "status":
+		return true
+	default:
+		return false
+	}
+}
+
+func validateField(key, value string) error {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return fmt.Errorf("%s: needs a value", strings.ToLower(key))
+	}
+
+	var ok bool
+	switch strings.ToLower(key) {
+	case "platform":
+		_, ok = queryPlatforms[value]
+	case "cond", "condition":
+		_, ok = queryConditions[value]
+	case "status":
+		_, ok = queryStatuses[value]
+	}
+	if !ok {
+		return fmt.Errorf("unknown %s %q", strings.ToLower(key), value)
+	}
+	return nil
+}
+
+func (q *Query) setField(text string) error {
+	key, value, _ := strings.Cut(text, ":")
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	var slot *string
+	var resolved string
+	switch strings.ToLower(key) {
+	case "platform":
+		slot, resolved = &q.Platform, queryPlatforms[value].name
+	case "cond", "condition":
+		slot, resolved = &q.Condition, queryConditions[value]
+	case "status":
+		slot, resolved = &q.Status, queryStatuses[value]
+	}
+	if *slot != "" && *slot != resolved {
+		return fmt.Errorf("%s: given twice", strings.ToLower(key))
+	}
+	*slot = resolved
+	return nil
+}
+
+// parsePriceRange parses "$a..$b"; either side may be empty and the dollar
+// signs are optional after the first.
+func parsePriceRange(text string) (float64, float64, error) {
+	lo, hi, found := strings.Cut(text, "..")
+	if !found {
+		return 0, 0, fmt.Errorf("price range %q: use $min..$max", text)
+	}
+
+	parseBound := func(raw string) (float64, error) {
+		raw = strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(raw), "$"), ",", "")
+		if raw == "" {
+			return 0, nil
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("price range %q: invalid amount", text)
+		}
+		return value, nil
+	}
+
+	minPrice, err := parseBound(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxPrice, err := parseBound(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if minPrice == 0 && maxPrice == 0 {
+		return 0, 0, fmt.Errorf("price range %q: needs a bound", text)
+	}
+	if maxPrice > 0 && minPrice > maxPrice {
+		return 0, 0, fmt.Errorf("price range %q: min exceeds max", text)
+	}
+	return minPrice, maxPrice, nil
+}
+
+// Keywords returns the bare search terms, which are eligible for expansion.
+func (q Query) Keywords() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// Filter returns the post-fetch filter implied by the query.
+func (q Query) Filter() types.ResultFilter {
+	return types.ResultFilter{
+		Platform:  q.Platform,
+		Condition: q.Condition,
+		Status:    q.Status,
+		MinPrice:  q.MinPrice,
+		MaxPrice:  q.MaxPrice,
+		Exclude:   append([]string(nil), q.Exclude...),
+		Require:   phraseTerms(q.Phrases),
+	}
+}
+
+// phraseTerms lower-cases quoted phrases so titles are checked for them after
+// fetching; providers often return results without the exact phrase.
+func phraseTerms(phrases []string) []string {
+	var out []string
+	for _, phrase := range phrases {
+		if phrase = strings.ToLower(strings.Join(strings.Fields(phrase), " ")); phrase != "" {
+			out = append(out, phrase)
+		}
+	}
+	return out
+}
+
+// Request builds a provider request for keywords, which may be an expanded
+// form of q.Keywords().
+func (q Query) Request(keywords string) SearchRequest {
+	return SearchRequest{
+		Query:    keywords,
+		Phrases:  append([]string(nil), q.Phrases...),
+		Exclude:  append([]string(nil), q.Exclude...),
+		Platform: q.Platform,
+	}
+}
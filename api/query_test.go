@@ -0,0 +1,133 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"mrktr/types"
+)
+
+func TestParseQuerySyntax(t *testing.T) {
+	q, err := ParseQuery(`switch "oled model" -Broken platform:ebay cond:used status:sold $100..$300`)
+	if err != nil {
+		t.Fatalf("expected valid query, got %v", err)
+	}
+
+	if q.Keywords() != "switch" {
+		t.Fatalf("expected keywords %q, got %q", "switch", q.Keywords())
+	}
+	if len(q.Phrases) != 1 || q.Phrases[0] != "oled model" {
+		t.Fatalf("expected exact phrase, got %v", q.Phrases)
+	}
+	if len(q.Exclude) != 1 || q.Exclude[0] != "broken" {
+		t.Fatalf("expected lower-cased exclusion, got %v", q.Exclude)
+	}
+
+	f := q.Filter()
+	if f.Platform != "eBay" || f.Condition != "Used" || f.Status != "Sold" {
+		t.Fatalf("unexpected field filters: %+v", f)
+	}
+	if f.MinPrice != 100 || f.MaxPrice != 300 {
+		t.Fatalf("expected price bounds 100..300, got %.2f..%.2f", f.MinPrice, f.MaxPrice)
+	}
+	if len(f.Require) != 1 || f.Require[0] != "oled model" {
+		t.Fatalf("expected the phrase to be required in titles, got %v", f.Require)
+	}
+	listings := []types.Listing{
+		{Title: "Nintendo Switch OLED Model White", Platform: "eBay", Condition: "Used", Status: "Sold", Price: 250},
+		{Title: "Nintendo Switch OLED White", Platform: "eBay", Condition: "Used", Status: "Sold", Price: 250},
+	}
+	if got := types.ApplyFilter(listings, f); len(got) != 1 || got[0].Title != listings[0].Title {
+		t.Fatalf("expected only the title with the exact phrase, got %+v", got)
+	}
+
+	req := q.Request("nintendo switch")
+	if req.Query != "nintendo switch" || req.Platform != "eBay" {
+		t.Fatalf("unexpected provider request: %+v", req)
+	}
+	if domains := req.normalized().domains(); len(domains) != 1 || domains[0] != "ebay.com" {
+		t.Fatalf("expected eBay domain, got %v", domains)
+	}
+}
+
+func TestParseQueryOpenPriceBounds(t *testing.T) {
+	q, err := ParseQuery("ps5 $250..")
+	if err != nil {
+		t.Fatalf("expected valid query, got %v", err)
+	}
+	if q.MinPrice != 250 || q.MaxPrice != 0 {
+		t.Fatalf("expected open upper bound, got %.2f..%.2f", q.MinPrice, q.MaxPrice)
+	}
+
+	q, err = ParseQuery("ps5 ..$400")
+	if err != nil {
+		t.Fatalf("expected valid query, got %v", err)
+	}
+	if q.MinPrice != 0 || q.MaxPrice != 400 {
+		t.Fatalf("expected open lower bound, got %.2f..%.2f", q.MinPrice, q.MaxPrice)
+	}
+}
+
+func TestParseQueryConditionVocabulary(t *testing.T) {
+	tests := map[string]string{
+		"cond:sealed":      "Sealed",
+		"cond:open-box":    "Open Box",
+		"cond:likenew":     "Like New",
+		"cond:refurbished": "Refurbished",
+		"cond:certified":   "Certified Refurbished",
+		"cond:parts":       "For Parts",
+	}
+	for token, want := range tests {
+		q, err := ParseQuery("switch " + token)
+		if err != nil {
+			t.Fatalf("%s: expected valid query, got %v", token, err)
+		}
+		if got := q.Filter().Condition; got != want {
+			t.Fatalf("%s: expected condition %q, got %q", token, want, got)
+		}
+	}
+}
+
+func TestParseQueryValidationErrors(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: `switch "oled`, want: "unterminated quote"},
+		{raw: "switch platform:craigslist", want: "unknown platform"},
+		{raw: "switch cond:mint", want: "unknown cond"},
+		{raw: "switch status:", want: "needs a value"},
+		{raw: "switch $300..$100", want: "min exceeds max"},
+		{raw: "switch $abc..", want: "invalid amount"},
+		{raw: "switch $300", want: "use $min..$max"},
+		{raw: "platform:ebay -broken", want: "at least one search term"},
+		{raw: "switch platform:ebay platform:mercari", want: "given twice"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			_, err := ParseQuery(tc.raw)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestTokenizeQueryRoundTripsInput(t *testing.T) {
+	raw := `  switch  "oled"-broken cond:new $1..$2 "open`
+	var b strings.Builder
+	kinds := map[QueryTokenKind]int{}
+	for _, token := range TokenizeQuery(raw) {
+		b.WriteString(token.Text)
+		kinds[token.Kind]++
+	}
+	if b.String() != raw {
+		t.Fatalf("expected tokens to reproduce input, got %q", b.String())
+	}
+	for _, kind := range []QueryTokenKind{QueryTokenTerm, QueryTokenPhrase, QueryTokenExclude, QueryTokenField, QueryTokenPrice, QueryTokenInvalid} {
+		if kinds[kind] == 0 {
+			t.Fatalf("expected at least one token of kind %d, got %v", kind, kinds)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	datedEventPattern  = regexp.MustCompile(`(?i)\b(?:sold|ended|listed|posted)(?:\s+on)?\s*:?\s+(today|yesterday|[a-z]{3,9}\.?\s+\d{1,2}(?:st|nd|rd|th)?,?\s+\d{4}|\d{1,2}\s+[a-z]{3,9}\.?\s+\d{4}|\d{4}-\d{2}-\d{2})`)
+	relativeAgePattern = regexp.MustCompile(`(?i)\b(\d+|an?|one)\s+(minute|hour|day|week|month|year)s?\s+ago\b`)
+	ordinalSuffix      = regexp.MustCompile(`(?i)(\d)(?:st|nd|rd|th)\b`)
+)
+
+var absoluteDateLayouts = []string{
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2006-01-02",
+}
+
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.000000",
+}
+
+// Freshness windows shared by providers. Each provider maps these onto its
+// own parameter values.
+const (
+	freshnessDay   = "day"
+	freshnessWeek  = "week"
+	freshnessMonth = "month"
+	freshnessYear  = "year"
+)
+
+// extractListingDate finds when a listing sold or was listed. Snippet text
+// such as "Sold Mar 3, 2026" or "2 days ago" wins over the provider-reported
+// page age, which reflects crawling rather than the sale.
+func extractListingDate(text, providerAge string, now time.Time) (time.Time, bool) {
+	if match := datedEventPattern.FindStringSubmatch(text); match != nil {
+		if date, ok := parseDateText(match[1], now); ok {
+			return date, true
+		}
+	}
+	if match := relativeAgePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := parseRelativeAge(match, now); ok {
+			return date, true
+		}
+	}
+	return parseProviderAge(providerAge, now)
+}
+
+// parseProviderAge reads provider age fields: timestamps ("2026-03-03T10:00:00"),
+// dates ("March 3, 2026") or relative ages ("2 days ago").
+func parseProviderAge(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timestampLayouts {
+		if date, err := time.Parse(layout, raw); err == nil {
+			return validDate(date, now)
+		}
+	}
+	if match := relativeAgePattern.FindStringSubmatch(raw); match != nil {
+		return parseRelativeAge(match, now)
+	}
+	return parseDateText(raw, now)
+}
+
+func parseDateText(raw string, now time.Time) (time.Time, bool) {
+	cleaned := strings.TrimSpace(raw)
+	switch strings.ToLower(cleaned) {
+	case "today":
+		return truncateDay(now), true
+	case "yesterday":
+		return truncateDay(now).AddDate(0, 0, -1), true
+	}
+
+	cleaned = ordinalSuffix.ReplaceAllString(cleaned, "$1")
+	cleaned = strings.ReplaceAll(cleaned, ".", "")
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+	if fields := strings.Fields(cleaned); len(fields) > 0 && len(fields[0]) > 3 && !isDigits(fields[0]) {
+		// Accept "Sept 3, 2026" and other non-standard month abbreviations.
+		if month, ok := monthFromName(fields[0]); ok {
+			fields[0] = month
+			cleaned = strings.Join(fields, " ")
+		}
+	}
+	for _, layout := range absoluteDateLayouts {
+		if date, err := time.Parse(layout, cleaned); err == nil {
+			return validDate(date, now)
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseRelativeAge(match []string, now time.Time) (time.Time, bool) {
+	if len(match) < 3 {
+		return time.Time{}, false
+	}
+	count := 1
+	switch strings.ToLower(match[1]) {
+	case "a", "an", "one":
+	default:
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+		count = value
+	}
+
+	switch strings.ToLower(match[2]) {
+	case "minute":
+		return now.Add(-time.Duration(count) * time.Minute), true
+	case "hour":
+		return now.Add(-time.Duration(count) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, -count), true
+	case "week":
+		return now.AddDate(0, 0, -7*count), true
+	case "month":
+		return now.AddDate(0, -count, 0), true
+	case "year":
+		return now.AddDate(-count, 0, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// validDate rejects dates more than a day ahead of now, which are usually
+// release dates or shipping estimates rather than listing dates.
+func validDate(date, now time.Time) (time.Time, bool) {
+	if date.After(now.Add(24 * time.Hour)) {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func monthFromName(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for month := time.January; month <= time.December; month++ {
+		full := strings.ToLower(month.String())
+		if strings.HasPrefix(full, lower) && len(lower) >= 3 {
+			return month.String(), true
+		}
+	}
+	return "", false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// freshnessWindow returns the narrowest provider freshness window covering
+// maxAge, or "" when no restriction applies.
+func freshnessWindow(maxAge time.Duration) string {
+	switch {
+	case maxAge <= 0:
+		return ""
+	case maxAge <= 24*time.Hour:
+		return freshnessDay
+	case maxAge <= 7*24*time.Hour:
+		return freshnessWeek
+	case maxAge <= 31*24*time.Hour:
+		return freshnessMonth
+	case maxAge <= 366*24*time.Hour:
+		return freshnessYear
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractListingDate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+		age  string
+		want time.Time
+		ok   bool
+	}{
+		{name: "sold date", text: "PS5 Slim Sold Mar 3, 2026 $420", want: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "ordinal and long month", text: "Ended on: September 28th 2025", want: time.Date(2025, 9, 28, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "abbreviated with period", text: "Sold Sept. 2, 2025", want: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "day first", text: "Listed 3 Feb 2026", want: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "iso", text: "Posted 2026-01-15", want: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "sold yesterday", text: "Sold yesterday for $300", want: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "relative days", text: "Listed 2 days ago", want: now.AddDate(0, 0, -2), ok: true},
+		{name: "relative article", text: "an hour ago · $150", want: now.Add(-time.Hour), ok: true},
+		{name: "relative weeks", text: "3 weeks ago", want: now.AddDate(0, 0, -21), ok: true},
+		{name: "snippet wins over provider age", text: "Sold Mar 1, 2026", age: "2025-01-01T00:00:00", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "provider timestamp", text: "no date here", age: "2026-02-20T08:30:00", want: time.Date(2026, 2, 20, 8, 30, 0, 0, time.UTC), ok: true},
+		{name: "provider relative", text: "", age: "5 days ago", want: now.AddDate(0, 0, -5), ok: true},
+		{name: "provider long date", text: "", age: "February 1, 2026", want: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), ok: true},
+		{name: "future release date rejected", text: "Listed Dec 25, 2026", ok: false},
+		{name: "no date", text: "PS5 Slim $420", ok: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := extractListingDate(tc.text, tc.age, now)
+			if ok != tc.ok {
+				t.Fatalf("expected ok=%v, got %v (%v)", tc.ok, ok, got)
+			}
+			if ok && !got.Equal(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFreshnessWindow(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		maxAge time.Duration
+		want   string
+	}{
+		{maxAge: 0, want: ""},
+		{maxAge: day, want: freshnessDay},
+		{maxAge: 7 * day, want: freshnessWeek},
+		{maxAge: 30 * day, want: freshnessMonth},
+		{maxAge: 90 * day, want: freshnessYear},
+		{maxAge: 800 * day, want: ""},
+	}
+	for _, tc := range tests {
+		if got := freshnessWindow(tc.maxAge); got != tc.want {
+			t.Fatalf("freshnessWindow(%v): expected %q, got %q", tc.maxAge, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mrktr/types"
+)
+
+// Bounds on a plausible price as a share of MSRP. Prices outside them are
+// more often a misparsed shipping cost, accessory or bundle than a real sale.
+// Discontinued products may sell well above retail, so their ceiling is higher.
+const (
+	minRetailFraction       = 0.10
+	maxRetailMultiple       = 3.0
+	maxDiscontinuedMultiple = 6.0
+)
+
+// MSRPCurrency is the currency ProductEntry.MSRP is in. Prices in other
+// currencies cannot be compared with it.
+const MSRPCurrency = "USD"
+
+// releasedLayouts are the accepted forms of ProductEntry.Released.
+var releasedLayouts = []string{"2006-01-02", "2006-01", "2006"}
+
+// ReleaseDate parses Released.
+func (e ProductEntry) ReleaseDate() (time.Time, bool) {
+	for _, layout := range releasedLayouts {
+		if t, err := time.Parse(layout, e.Released); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Fees returns the fee class selling fees are looked up with.
+func (e ProductEntry) Fees() types.FeeClass {
+	class, _ := types.ParseFeeClass(e.FeeClass)
+	return class
+}
+
+// PercentOfRetail returns price, in MSRPCurrency, as a percentage of MSRP.
+func (e ProductEntry) PercentOfRetail(price float64) (float64, bool) {
+	if e.MSRP <= 0 || price <= 0 {
+		return 0, false
+	}
+	return price / e.MSRP * 100, true
+}
+
+// PlausiblePriceRange returns the prices, in MSRPCurrency, outside of which a
+// listing of this product is probably misparsed. ok is false without an MSRP.
+func (e ProductEntry) PlausiblePriceRange() (low, high float64, ok bool) {
+	if e.MSRP <= 0 {
+		return 0, 0, false
+	}
+	multiple := maxRetailMultiple
+	if e.Discontinued {
+		multiple = maxDiscontinuedMultiple
+	}
+	return e.MSRP * minRetailFraction, e.MSRP * multiple, true
+}
+
+// Product returns the catalog product named name, case-insensitively.
+func (idx *ProductIndex) Product(name string) (ProductEntry, bool) {
+	if idx == nil {
+		return ProductEntry{}, false
+	}
+	i, ok := idx.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return ProductEntry{}, false
+	}
+	return idx.products[i].entry, true
+}
+
+func validateRetail(entry ProductEntry) error {
+	if entry.MSRP < 0 {
+		return errors.New("msrp must not be negative")
+	}
+	if entry.Released != "" {
+		if _, ok := entry.ReleaseDate(); !ok {
+			return fmt.Errorf("released %q: want YYYY, YYYY-MM or YYYY-MM-DD", entry.Released)
+		}
+	}
+	if _, ok := types.ParseFeeClass(entry.FeeClass); !ok {
+		names := make([]string, 0, len(types.FeeClasses))
+		for _, class := range types.FeeClasses {
+			names = append(names, string(class))
+		}
+		return fmt.Errorf("fee_class %q: want one of %s", entry.FeeClass, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"mrktr/types"
+)
+
+func TestEmbeddedCatalogEntriesAreValid(t *testing.T) {
+	for i, entry := range loadProductCatalog() {
+		if err := validateCatalogEntry(entry); err != nil {
+			t.Fatalf("embedded catalog entry %d (%s): %v", i, entry.Name, err)
+		}
+	}
+}
+
+func TestProductRetailMetadata(t *testing.T) {
+	idx := NewProductIndex()
+	product, ok := idx.Product("nintendo switch oled")
+	if !ok {
+		t.Fatal("expected Nintendo Switch OLED in the catalog")
+	}
+	if product.Fees() != types.FeeClassElectronics {
+		t.Fatalf("expected electronics fee class, got %q", product.Fees())
+	}
+	if released, ok := product.ReleaseDate(); !ok || released.Year() != 2021 {
+		t.Fatalf("expected a 2021 release date, got %v (%v)", released, ok)
+	}
+	pct, ok := product.PercentOfRetail(product.MSRP / 2)
+	if !ok || pct != 50 {
+		t.Fatalf("expected 50%% of retail, got %.2f (%v)", pct, ok)
+	}
+
+	low, high, ok := product.PlausiblePriceRange()
+	if !ok || low != product.MSRP*minRetailFraction || high != product.MSRP*maxRetailMultiple {
+		t.Fatalf("unexpected plausible range $%.2f-$%.2f (%v)", low, high, ok)
+	}
+	product.Discontinued = true
+	if _, high, _ := product.PlausiblePriceRange(); high != product.MSRP*maxDiscontinuedMultiple {
+		t.Fatalf("expected a higher ceiling for a discontinued product, got $%.2f", high)
+	}
+	if _, _, ok := (ProductEntry{Name: "No MSRP"}).PlausiblePriceRange(); ok {
+		t.Fatal("expected no plausible range without an MSRP")
+	}
+}
+
+func TestValidateCatalogEntryRetailFields(t *testing.T) {
+	cases := map[string]ProductEntry{
+		"msrp":      {Name: "A", MSRP: -1},
+		"released":  {Name: "A", Released: "fall 2020"},
+		"fee_class": {Name: "A", FeeClass: "furniture"},
+	}
+	for field, entry := range cases {
+		err := validateCatalogEntry(entry)
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Fatalf("expected a %s error, got %v", field, err)
+		}
+	}
+	if err := validateCatalogEntry(ProductEntry{Name: "A", MSRP: 20, Released: "2019-06", FeeClass: "media"}); err != nil {
+		t.Fatalf("expected a valid entry, got %v", err)
+	}
+}
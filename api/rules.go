@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mrktr/types"
+)
+
+// ruleConditionConfidence is the confidence given to conditions named by a
+// user rule; the user wrote the rule for exactly this phrasing.
+const ruleConditionConfidence = 0.95
+
+// rulesPlatformAll is the rules-file key whose rules apply to every platform.
+const rulesPlatformAll = "*"
+
+// rulesPlatforms maps accepted rules-file platform keys to platform names.
+var rulesPlatforms = map[string]string{
+	"ebay":           "eBay",
+	"mercari":        "Mercari",
+	"amazon":         "Amazon",
+	"facebook":       "Facebook",
+	"other":          "Other",
+	rulesPlatformAll: rulesPlatformAll,
+}
+
+// ParseRules are user-defined extraction rules checked ahead of the built-in
+// patterns. A nil *ParseRules applies no rules.
+type ParseRules struct {
+	platforms map[string]platformRules // keyed by platform name or "*"
+}
+
+type platformRules struct {
+	price     []pricePattern
+	condition []valueRule
+	status    []valueRule
+	keywords  []keywordPriority
+	ignore    []*regexp.Regexp
+}
+
+// valueRule assigns a condition or status when its pattern matches.
+type valueRule struct {
+	re    *regexp.Regexp
+	value string
+}
+
+// keywordPriority ranks price candidates whose context mentions keyword.
+type keywordPriority struct {
+	keyword  string
+	priority int
+}
+
+// rulesFile is the JSON layout of a rules file:
+//
+//	{"platforms": {"eBay": {"price": [{"name": "bin", "pattern": "buy it now:? \\$(\\d+)(?:\\.(\\d{2}))?"}],
+//	  "condition": [{"pattern": "pre-loved", "condition": "Used"}],
+//	  "status": [{"pattern": "auction ended", "status": "Sold"}],
+//	  "keywords": [{"keyword": "buy it now", "priority": 10}],
+//	  "ignore": ["\\bwanted\\b"]}}}
+type rulesFile struct {
+	Platforms map[string]rulesFilePlatform `json:"platforms"`
+}
+
+type rulesFilePlatform struct {
+	Price     []rulesFilePattern `json:"price"`
+	Condition []rulesFilePattern `json:"condition"`
+	Status    []rulesFilePattern `json:"status"`
+	Keywords  []rulesFileKeyword `json:"keywords"`
+	Ignore    []string           `json:"ignore"`
+}
+
+type rulesFilePattern struct {
+	Name      string `json:"name,omitempty"`
+	Pattern   string `json:"pattern"`
+	Condition string `json:"condition,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+type rulesFileKeyword struct {
+	Keyword  string `json:"keyword"`
+	Priority int    `json:"priority"`
+}
+
+// LoadParseRules reads a rules file. A missing file yields nil rules.
+func LoadParseRules(path string) (*ParseRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	rules, err := ParseRulesJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("rules %s:\n%w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRulesJSON compiles and validates a rules file. Every problem is
+// reported, each prefixed with its location such as "platforms.eBay.price[0]".
+// Patterns are case-insensitive; price patterns capture the whole amount in
+// their first group and optional decimals in their second.
+func ParseRulesJSON(data []byte) (*ParseRules, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file rulesFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode rules: %w", err)
+	}
+
+	rules := &ParseRules{platforms: map[string]platformRules{}}
+	var errs []error
+	keys := make([]string, 0, len(file.Platforms))
+	for key := range file.Platforms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prefix := "platforms." + key
+		platform, ok := rulesPlatforms[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown platform (want eBay, Mercari, Amazon, Facebook, Other or *)", prefix))
+			continue
+		}
+		compiled, platformErrs := compilePlatformRules(prefix, file.Platforms[key])
+		errs = append(errs, platformErrs...)
+		existing := rules.platforms[platform]
+		rules.platforms[platform] = mergePlatformRules(existing, compiled)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func compilePlatformRules(prefix string, raw rulesFilePlatform) (platformRules, []error) {
+	var out platformRules
+	var errs []error
+
+	for i, rule := range raw.Price {
+		where := fmt.Sprintf("%s.price[%d]", prefix, i)
+		re, err := compileRulePattern(rule.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		if re.NumSubexp() < 1 {
+			errs = append(errs, fmt.Errorf("%s: pattern needs a capture group for the amount", where))
+			continue
+		}
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			name = strings.TrimPrefix(where, "platforms.")
+		}
+		out.price = append(out.price, pricePattern{name: "rule:" + name, re: re})
+	}
+
+	for i, rule := range raw.Condition {
+		where := fmt.Sprintf("%s.condition[%d]", prefix, i)
+		re, err := compileRulePattern(rule.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		condition := types.CanonicalCondition(rule.Condition)
+		if !isCanonicalCondition(condition) {
+			errs = append(errs, fmt.Errorf("%s: unknown condition %q (want one of %s)", where, rule.Condition, strings.Join(types.Conditions, ", ")))
+			continue
+		}
+		out.condition = append(out.condition, valueRule{re: re, value: condition})
+	}
+
+	for i, rule := range raw.Status {
+		where := fmt.Sprintf("%s.status[%d]", prefix, i)
+		re, err := compileRulePattern(rule.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		var status string
+		switch strings.ToLower(strings.TrimSpace(rule.Status)) {
+		case "sold":
+			status = "Sold"
+		case "active":
+			status = "Active"
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown status %q (want Sold or Active)", where, rule.Status))
+			continue
+		}
+		out.status = append(out.status, valueRule{re: re, value: status})
+	}
+
+	for i, keyword := range raw.Keywords {
+		text := strings.ToLower(strings.TrimSpace(keyword.Keyword))
+		if text == "" {
+			errs = append(errs, fmt.Errorf("%s.keywords[%d]: keyword is empty", prefix, i))
+			continue
+		}
+		out.keywords = append(out.keywords, keywordPriority{keyword: text, priority: keyword.Priority})
+	}
+
+	for i, pattern := range raw.Ignore {
+		re, err := compileRulePattern(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.ignore[%d]: %w", prefix, i, err))
+			continue
+		}
+		out.ignore = append(out.ignore, re)
+	}
+
+	return out, errs
+}
+
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern: %w", err)
+	}
+	return re, nil
+}
+
+func isCanonicalCondition(condition string) bool {
+	for _, known := range types.Conditions {
+		if condition == known {
+			return true
+		}
+	}
+	return false
+}
+
+// mergePlatformRules appends next's rules after base's.
+func mergePlatformRules(base, next platformRules) platformRules {
+	base.price = append(base.price, next.price...)
+	base.condition = append(base.condition, next.condition...)
+	base.status = append(base.status, next.status...)
+	base.keywords = append(base.keywords, next.keywords...)
+	base.ignore = append(base.ignore, next.ignore...)
+	return base
+}
+
+// forPlatform returns the platform's rules followed by the rules for every
+// platform.
+func (r *ParseRules) forPlatform(platform string) platformRules {
+	if r == nil {
+		return platformRules{}
+	}
+	specific := r.platforms[platform]
+	return mergePlatformRules(mergePlatformRules(platformRules{}, specific), r.platforms[rulesPlatformAll])
+}
+
+// ignores reports whether an ignore rule matches the result text.
+func (p platformRules) ignores(text string) bool {
+	for _, re := range p.ignore {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCondition returns the condition of the first matching rule.
+func (p platformRules) matchCondition(text string) (string, bool) {
+	return firstValueRule(p.condition, text)
+}
+
+// matchStatus returns the status of the first matching rule.
+func (p platformRules) matchStatus(text string) (string, bool) {
+	return firstValueRule(p.status, text)
+}
+
+func firstValueRule(rules []valueRule, text string) (string, bool) {
+	for _, rule := range rules {
+		if rule.re.MatchString(text) {
+			return rule.value, true
+		}
+	}
+	return "", false
+}
+
+// priority sums the priorities of keywords in the clause before a price.
+func (p platformRules) priority(match priceMatch) int {
+	if len(p.keywords) == 0 {
+		return 0
+	}
+	lead := strings.ToLower(match.lead)
+	total := 0
+	for _, keyword := range p.keywords {
+		if strings.Contains(lead, keyword.keyword) {
+			total += keyword.priority
+		}
+	}
+	return total
+}
+
+// pickCandidate returns the highest-priority, then lowest, amount.
+func (p platformRules) pickCandidate(matches []priceMatch) (float64, bool) {
+	best := 0.0
+	bestPriority := 0
+	found := false
+	for _, match := range matches {
+		priority := p.priority(match)
+		amount := match.candidate.Amount
+		if !found || priority > bestPriority || (priority == bestPriority && amount < best) {
+			best = amount
+			bestPriority = priority
+			found = true
+		}
+	}
+	return best, found
+}
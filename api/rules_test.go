@@ -0,0 +1,137 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRules = `{
+  "platforms": {
+    "eBay": {
+      "price": [{"name": "bin", "pattern": "buy it now:?\\s*\\$(\\d[\\d,]*)(?:\\.(\\d{2}))?"}],
+      "condition": [{"pattern": "pre-loved", "condition": "used"}],
+      "status": [{"pattern": "auction ended", "status": "sold"}],
+      "ignore": ["\\bwanted\\b"]
+    },
+    "*": {
+      "keywords": [{"keyword": "shipping", "priority": -10}]
+    }
+  }
+}`
+
+func TestParseRulesApplyAheadOfBuiltins(t *testing.T) {
+	rules, err := ParseRulesJSON([]byte(testRules))
+	if err != nil {
+		t.Fatalf("parse rules: %v", err)
+	}
+
+	data := []SearchResult{
+		{
+			URL:         "https://www.ebay.com/itm/123456789012",
+			Title:       "Nintendo Switch OLED",
+			Description: "Bids from $150. Buy It Now $289.00. Pre-loved, auction ended",
+		},
+		{
+			URL:         "https://www.ebay.com/itm/223456789012",
+			Title:       "WANTED: Nintendo Switch",
+			Description: "paying $100",
+		},
+		{
+			URL:         "https://www.mercari.com/us/item/m123",
+			Title:       "Switch Lite",
+			Description: "shipping $12, price $140",
+		},
+	}
+
+	got := ParseSearchResultsWith(data, ParseOptions{Rules: rules})
+	if len(got) != 2 {
+		t.Fatalf("expected the wanted post to be ignored, got %d listings", len(got))
+	}
+
+	ebay := got[0]
+	if ebay.Price != 289 {
+		t.Fatalf("expected rule price 289 over lower bid, got %v", ebay.Price)
+	}
+	if ebay.Condition != "Used" || ebay.ConditionConfidence != ruleConditionConfidence {
+		t.Fatalf("expected rule condition Used, got %q (%v)", ebay.Condition, ebay.ConditionConfidence)
+	}
+	if ebay.Status != "Sold" {
+		t.Fatalf("expected rule status Sold, got %q", ebay.Status)
+	}
+	if len(ebay.PriceCandidates) == 0 || ebay.PriceCandidates[0].Pattern != "rule:bin" {
+		t.Fatalf("expected rule candidate first, got %+v", ebay.PriceCandidates)
+	}
+
+	mercari := got[1]
+	if mercari.Price != 140 {
+		t.Fatalf("expected shipping amount deprioritized, got %v", mercari.Price)
+	}
+}
+
+func TestParseRulesWithoutRulesMatchesBuiltins(t *testing.T) {
+	data := []SearchResult{{
+		URL:         "https://www.mercari.com/us/item/m123",
+		Title:       "Switch Lite",
+		Description: "shipping $12, price $140",
+	}}
+
+	got := ParseSearchResultsWith(data, ParseOptions{})
+	if len(got) != 1 || got[0].Price != 12 {
+		t.Fatalf("expected built-in lowest price 12, got %+v", got)
+	}
+}
+
+func TestParseRulesJSONReportsEveryProblem(t *testing.T) {
+	raw := `{
+  "platforms": {
+    "Craigslist": {},
+    "eBay": {
+      "price": [{"pattern": "\\$\\d+"}, {"pattern": "([0-9"}],
+      "condition": [{"pattern": "mint", "condition": "Pristine"}],
+      "status": [{"pattern": "gone", "status": "Archived"}],
+      "keywords": [{"keyword": " "}]
+    }
+  }
+}`
+
+	_, err := ParseRulesJSON([]byte(raw))
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"platforms.Craigslist: unknown platform",
+		"platforms.eBay.price[0]: pattern needs a capture group",
+		"platforms.eBay.price[1]: pattern:",
+		`platforms.eBay.condition[0]: unknown condition "Pristine"`,
+		`platforms.eBay.status[0]: unknown status "Archived"`,
+		"platforms.eBay.keywords[0]: keyword is empty",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestParseRulesJSONRejectsUnknownFields(t *testing.T) {
+	_, err := ParseRulesJSON([]byte(`{"platforms": {"eBay": {"prices": []}}}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "prices"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestLoadParseRulesMissingFileIsEmpty(t *testing.T) {
+	rules, err := LoadParseRules(filepath.Join(t.TempDir(), "rules.json"))
+	if err != nil || rules != nil {
+		t.Fatalf("expected nil rules for missing file, got %v, %v", rules, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{"platforms": {"eBay": {"ignore": ["("]}}}`), 0o644); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	if _, err := LoadParseRules(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error naming %s, got %v", path, err)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+// prefixMark flags a product reached by a prefix match rather than only by
+// sharing a query term; the lower bits flag which query terms it contains.
+const prefixMark uint64 = 1 << 63
+
+// scoreBuffer accumulates per-product scores in dense arrays that are reused
+// across lookups, so scoring a query costs only the postings and prefix
+// matches it touches, not a map or array the size of the catalog.
+type scoreBuffer struct {
+	scores  []float64
+	marks   []uint64
+	stamp   []uint32 // scores[i] and marks[i] are live when stamp[i] == gen
+	gen     uint32
+	touched []int
+}
+
+// acquireScores returns an empty buffer sized for the index. Return it
+// with releaseScores.
+func (idx *ProductIndex) acquireScores() *scoreBuffer {
+	b, _ := idx.buffers.Get().(*scoreBuffer)
+	if b == nil || len(b.scores) != len(idx.products) {
+		b = &scoreBuffer{
+			scores: make([]float64, len(idx.products)),
+			marks:  make([]uint64, len(idx.products)),
+			stamp:  make([]uint32, len(idx.products)),
+		}
+	}
+	b.gen++
+	if b.gen == 0 {
+		clear(b.stamp)
+		b.gen = 1
+	}
+	b.touched = b.touched[:0]
+	return b
+}
+
+func (idx *ProductIndex) releaseScores(b *scoreBuffer) {
+	idx.buffers.Put(b)
+}
+
+func (b *scoreBuffer) touch(i int) {
+	if b.stamp[i] != b.gen {
+		b.stamp[i] = b.gen
+		b.scores[i] = 0
+		b.marks[i] = 0
+		b.touched = append(b.touched, i)
+	}
+}
+
+// add adds score to product i, marking it touched.
+func (b *scoreBuffer) add(i int, score float64) {
+	b.touch(i)
+	b.scores[i] += score
+}
+
+// mark sets bits on product i, marking it touched.
+func (b *scoreBuffer) mark(i int, bits uint64) {
+	b.touch(i)
+	b.marks[i] |= bits
+}
+
+// score returns the score accumulated for product i.
+func (b *scoreBuffer) score(i int) float64 {
+	if b.stamp[i] != b.gen {
+		return 0
+	}
+	return b.scores[i]
+}
+
+// marked returns the bits set on product i.
+func (b *scoreBuffer) marked(i int) uint64 {
+	if b.stamp[i] != b.gen {
+		return 0
+	}
+	return b.marks[i]
+}
@@ -33,12 +33,21 @@ const (
 	ProviderErrorTransport ProviderErrorKind = "transport"
 )
 
+const (
+	DefaultResultDepth     = 20
+	DefaultMaxPageRequests = 3
+	DefaultSearchDeadline  = 45 * time.Second
+)
+
 const (
 	DefaultBraveSearchURL     = "https://api.search.brave.com/res/v1/web/search"
 	DefaultFirecrawlSearchURL = "https://api.firecrawl.dev/v1/search"
 	DefaultTavilySearchURL    = "https://api.tavily.com/search"
 )
 
+// defaultMarketplaceDomains lists the marketplaces providers are restricted to.
+var defaultMarketplaceDomains = []string{"ebay.com", "mercari.com", "amazon.com"}
+
 // SearchResponse wraps search results with metadata used by the UI.
 type SearchResponse struct {
 	Results        []types.Listing
@@ -55,21 +64,135 @@ type ProviderError struct {
 	Err      error
 }
 
+// SearchRequest describes one price search handed to each provider.
+type SearchRequest struct {
+	Query       string
+	Phrases     []string      // exact phrases the provider query must contain
+	Exclude     []string      // terms the provider query negates
+	Platform    string        // restricts the search to one marketplace, e.g. "eBay"
+	Market      Market        // zero value means DefaultMarket
+	MaxAge      time.Duration // asks providers for pages fresher than this; zero means any age
+	Depth       int           // target number of priced listings per provider
+	MaxRequests int           // cap on page requests per provider
+	Rules       *ParseRules   // user parsing rules; nil uses only the built-in patterns
+	Progress    func(SearchProgress)
+}
+
+// SearchProgress reports how far a provider has paged through results.
+type SearchProgress struct {
+	Provider string
+	Listings int
+	Page     int
+}
+
+func (r SearchRequest) normalized() SearchRequest {
+	r.Query = strings.TrimSpace(r.Query)
+	if r.Market.Code == "" {
+		r.Market = DefaultMarket()
+	}
+	if r.Depth <= 0 {
+		r.Depth = DefaultResultDepth
+	}
+	if r.MaxRequests <= 0 {
+		r.MaxRequests = DefaultMaxPageRequests
+	}
+	return r
+}
+
+// providerQuery renders the request as web-search text: keywords, quoted
+// phrases and negated terms.
+func (r SearchRequest) providerQuery() string {
+	parts := make([]string, 0, 1+len(r.Phrases)+len(r.Exclude))
+	if r.Query != "" {
+		parts = append(parts, r.Query)
+	}
+	for _, phrase := range r.Phrases {
+		if phrase = strings.TrimSpace(phrase); phrase != "" {
+			parts = append(parts, `"`+phrase+`"`)
+		}
+	}
+	for _, term := range r.Exclude {
+		term = strings.TrimSpace(term)
+		switch {
+		case strings.Contains(term, " "):
+			parts = append(parts, `-"`+term+`"`)
+		case term != "":
+			parts = append(parts, "-"+term)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// domains returns the marketplace domains for the request's market, narrowed
+// to Platform when set. A platform the market lacks falls back to its US site.
+func (r SearchRequest) domains() []string {
+	if r.Platform != "" {
+		if domain, ok := r.Market.domainFor(r.Platform); ok {
+			return []string{domain}
+		}
+		if domain, ok := DefaultMarket().domainFor(r.Platform); ok {
+			return []string{domain}
+		}
+		for _, platform := range queryPlatforms {
+			if platform.name == r.Platform {
+				return []string{platform.domain}
+			}
+		}
+	}
+	if len(r.Market.Domains) == 0 {
+		return defaultMarketplaceDomains
+	}
+	return r.Market.Domains
+}
+
+func (r SearchRequest) parseOptions() ParseOptions {
+	return ParseOptions{Currency: r.Market.Currency, Rules: r.Rules}
+}
+
+func (r SearchRequest) freshness() string {
+	return freshnessWindow(r.MaxAge)
+}
+
+func (r SearchRequest) reportProgress(provider string, listings, page int) {
+	if r.Progress == nil {
+		return
+	}
+	r.Progress(SearchProgress{Provider: provider, Listings: listings, Page: page})
+}
+
+// ProviderResult is one provider's outcome within a streamed search.
+type ProviderResult struct {
+	Provider string
+	Results  []types.Listing
+	Err      error
+	Kind     ProviderErrorKind
+}
+
 // SearchProvider abstracts a single provider implementation.
 type SearchProvider interface {
 	Name() string
 	Configured() bool
-	Search(ctx context.Context, query string) ([]types.Listing, error)
+	Search(ctx context.Context, req SearchRequest) ([]types.Listing, error)
 }
 
 // Client coordinates provider execution order.
 type Client struct {
 	providers []SearchProvider
+	deadline  time.Duration
 }
 
 // NewClient creates a search client from providers.
 func NewClient(providers ...SearchProvider) *Client {
-	return &Client{providers: providers}
+	return &Client{providers: providers, deadline: DefaultSearchDeadline}
+}
+
+// SetDeadline bounds the total time one search may spend across providers.
+// A non-positive value disables the deadline.
+func (c *Client) SetDeadline(d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.deadline = d
 }
 
 // HasConfiguredProvider reports whether at least one provider has usable credentials.
@@ -85,6 +208,20 @@ func (c *Client) HasConfiguredProvider() bool {
 	return false
 }
 
+// ConfiguredProviderNames lists configured providers in execution order.
+func (c *Client) ConfiguredProviderNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		if provider != nil && provider.Configured() {
+			names = append(names, providerName(provider))
+		}
+	}
+	return names
+}
+
 // NewEnvClient builds a default client from process environment variables.
 func NewEnvClient() *Client {
 	httpClient := &http.Client{Timeout: 30 * time.Second}
@@ -103,11 +240,16 @@ func (c *Client) SearchPrices(query string) SearchResponse {
 
 // SearchPricesContext searches for item prices using a caller-provided context.
 func (c *Client) SearchPricesContext(ctx context.Context, query string) SearchResponse {
+	return c.SearchPricesRequest(ctx, SearchRequest{Query: query})
+}
+
+// SearchPricesRequest searches for item prices with explicit depth and progress settings.
+func (c *Client) SearchPricesRequest(ctx context.Context, req SearchRequest) SearchResponse {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	q := strings.TrimSpace(query)
+	req = req.normalized()
 
 	if c == nil {
 		return SearchResponse{
@@ -129,6 +271,12 @@ func (c *Client) SearchPricesContext(ctx context.Context, query string) SearchRe
 		}
 	}
 
+	if c.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.deadline)
+		defer cancel()
+	}
+
 	var successfulProviders int
 	providerErrors := make([]ProviderError, 0, len(c.providers))
 	var failedProviders []string
@@ -139,7 +287,7 @@ func (c *Client) SearchPricesContext(ctx context.Context, query string) SearchRe
 			continue
 		}
 
-		results, err := provider.Search(ctx, q)
+		results, err := provider.Search(ctx, req)
 		if err != nil {
 			name := providerName(provider)
 			providerErrors = append(providerErrors, ProviderError{
@@ -184,6 +332,116 @@ func (c *Client) SearchPricesContext(ctx context.Context, query string) SearchRe
 	}
 }
 
+// appendNewListings appends listings whose identity has not been seen yet.
+func appendNewListings(dst []types.Listing, seen map[string]struct{}, batch []types.Listing) []types.Listing {
+	for _, listing := range batch {
+		key := listing.Key()
+		if key != "" {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		dst = append(dst, listing)
+	}
+	return dst
+}
+
+// StreamPrices queries every configured provider concurrently. emit is called
+// from the calling goroutine as each provider completes, and the merged
+// response is returned once all providers have finished.
+func (c *Client) StreamPrices(ctx context.Context, req SearchRequest, emit func(ProviderResult)) SearchResponse {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req = req.normalized()
+
+	if c == nil || !c.HasConfiguredProvider() {
+		return c.SearchPricesRequest(ctx, req)
+	}
+
+	if c.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.deadline)
+		defer cancel()
+	}
+
+	type indexedResult struct {
+		index  int
+		result ProviderResult
+	}
+
+	active := make([]SearchProvider, 0, len(c.providers))
+	for _, provider := range c.providers {
+		if provider != nil && provider.Configured() {
+			active = append(active, provider)
+		}
+	}
+
+	done := make(chan indexedResult, len(active))
+	for i, provider := range active {
+		go func(index int, provider SearchProvider) {
+			results, err := provider.Search(ctx, req)
+			result := ProviderResult{Provider: providerName(provider), Results: results, Err: err}
+			if err != nil {
+				result.Results = nil
+				result.Kind = classifyProviderError(err)
+			}
+			done <- indexedResult{index: index, result: result}
+		}(i, provider)
+	}
+
+	ordered := make([]ProviderResult, len(active))
+	for range active {
+		item := <-done
+		ordered[item.index] = item.result
+		if emit != nil {
+			emit(item.result)
+		}
+	}
+
+	var successfulProviders int
+	merged := []types.Listing{}
+	seen := map[string]struct{}{}
+	providerErrors := make([]ProviderError, 0, len(active))
+	var failedProviders []string
+	var failedHints []string
+	for _, result := range ordered {
+		if result.Err != nil {
+			providerErrors = append(providerErrors, ProviderError{
+				Provider: result.Provider,
+				Kind:     result.Kind,
+				Err:      result.Err,
+			})
+			failedProviders = append(failedProviders, result.Provider)
+			if hint := actionableProviderError(result.Provider, result.Err); hint != "" {
+				failedHints = append(failedHints, hint)
+			}
+			continue
+		}
+		successfulProviders++
+		merged = appendNewListings(merged, seen, result.Results)
+	}
+
+	warning := buildSearchWarning(failedProviders, failedHints)
+	if successfulProviders > 0 {
+		return SearchResponse{
+			Results:        merged,
+			Mode:           SearchModeLive,
+			Warning:        warning,
+			ProviderErrors: providerErrors,
+		}
+	}
+
+	return SearchResponse{
+		Results:        []types.Listing{},
+		Mode:           SearchModeUnavailable,
+		Warning:        warning,
+		Err:            buildSearchError(warning, providerErrors),
+		ProviderErrors: providerErrors,
+	}
+}
+
 func providerName(provider SearchProvider) string {
 	name := strings.TrimSpace(provider.Name())
 	if name == "" {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"mrktr/types"
 )
@@ -28,7 +29,7 @@ func (p stubProvider) Configured() bool {
 	return p.configured
 }
 
-func (p stubProvider) Search(_ context.Context, _ string) ([]types.Listing, error) {
+func (p stubProvider) Search(_ context.Context, _ SearchRequest) ([]types.Listing, error) {
 	if p.err != nil {
 		return nil, p.err
 	}
@@ -43,7 +44,7 @@ func (p *contextProbeProvider) Configured() bool {
 	return true
 }
 
-func (p *contextProbeProvider) Search(ctx context.Context, _ string) ([]types.Listing, error) {
+func (p *contextProbeProvider) Search(ctx context.Context, _ SearchRequest) ([]types.Listing, error) {
 	if err := ctx.Err(); err != nil {
 		p.sawCanceled = true
 		return nil, err
@@ -249,3 +250,81 @@ func TestSearchPricesProviderErrorClassification(t *testing.T) {
 		t.Fatalf("expected Tavily timeout classification, got %q", kinds["Tavily"])
 	}
 }
+
+func TestSearchPricesRequestAppliesDeadline(t *testing.T) {
+	probe := &deadlineProbeProvider{}
+	client := NewClient(probe)
+	client.SetDeadline(time.Minute)
+
+	client.SearchPricesRequest(context.Background(), SearchRequest{Query: "ps5"})
+	if !probe.hadDeadline {
+		t.Fatal("expected provider context to carry the search deadline")
+	}
+	if probe.depth != DefaultResultDepth || probe.maxRequests != DefaultMaxPageRequests {
+		t.Fatalf("expected default depth and page cap, got depth=%d pages=%d", probe.depth, probe.maxRequests)
+	}
+}
+
+type deadlineProbeProvider struct {
+	hadDeadline bool
+	depth       int
+	maxRequests int
+}
+
+func (p *deadlineProbeProvider) Name() string {
+	return "DeadlineProbe"
+}
+
+func (p *deadlineProbeProvider) Configured() bool {
+	return true
+}
+
+func (p *deadlineProbeProvider) Search(ctx context.Context, req SearchRequest) ([]types.Listing, error) {
+	_, p.hadDeadline = ctx.Deadline()
+	p.depth = req.Depth
+	p.maxRequests = req.MaxRequests
+	return []types.Listing{}, nil
+}
+
+func TestStreamPricesEmitsEachProviderAndMerges(t *testing.T) {
+	client := NewClient(
+		stubProvider{
+			name:       "Brave",
+			configured: true,
+			results: []types.Listing{
+				{URL: "https://ebay.com/1", Price: 499.0, Platform: "eBay", Title: "PS5"},
+			},
+		},
+		stubProvider{name: "Tavily", configured: true, err: errors.New("upstream unavailable")},
+		stubProvider{
+			name:       "Firecrawl",
+			configured: true,
+			results: []types.Listing{
+				{URL: "https://ebay.com/1", Price: 499.0, Platform: "eBay", Title: "PS5"},
+				{URL: "https://mercari.com/1", Price: 450.0, Platform: "Mercari", Title: "PS5"},
+			},
+		},
+		stubProvider{name: "Disabled", configured: false},
+	)
+
+	emitted := map[string]ProviderResult{}
+	resp := client.StreamPrices(context.Background(), SearchRequest{Query: "ps5"}, func(result ProviderResult) {
+		emitted[result.Provider] = result
+	})
+
+	if len(emitted) != 3 {
+		t.Fatalf("expected one emission per configured provider, got %d", len(emitted))
+	}
+	if emitted["Tavily"].Err == nil || emitted["Tavily"].Kind == "" {
+		t.Fatalf("expected classified Tavily failure, got %+v", emitted["Tavily"])
+	}
+	if resp.Mode != SearchModeLive {
+		t.Fatalf("expected live mode, got %q", resp.Mode)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected merged results deduplicated by URL, got %d", len(resp.Results))
+	}
+	if !strings.Contains(resp.Warning, "Tavily") || len(resp.ProviderErrors) != 1 {
+		t.Fatalf("expected Tavily warning, got %q (%d errors)", resp.Warning, len(resp.ProviderErrors))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 )
 
 const (
@@ -14,6 +15,10 @@ const (
 	minExpandScore      = 0.34
 	minExpandSeparation = 0.08
 	maxSuggestions      = 6
+
+	// fuzzyTermWeight discounts a corrected query token against one typed
+	// exactly as it appears in the catalog.
+	fuzzyTermWeight = 0.8
 )
 
 var (
@@ -31,33 +36,117 @@ type ProductEntry struct {
 	Name     string   `json:"name"`
 	Category string   `json:"category"`
 	Synonyms []string `json:"synonyms"`
+	// Identifiers are product codes that resolve to this product when
+	// typed or scanned: UPC/EAN/GTIN, ASIN, MPN or set numbers.
+	Identifiers []string `json:"identifiers,omitempty"`
+	// Retail metadata: launch MSRP in USD, release date (YYYY, YYYY-MM or
+	// YYYY-MM-DD), whether it is still made, and the fee class selling
+	// fees are looked up with.
+	MSRP         float64 `json:"msrp,omitempty"`
+	Released     string  `json:"released,omitempty"`
+	Discontinued bool    `json:"discontinued,omitempty"`
+	FeeClass     string  `json:"fee_class,omitempty"`
+	// Require and Exclude narrow searches for the product: listing titles
+	// must contain every required term and none of the excluded ones, e.g.
+	// "case" and "skin" for a console.
+	Require  []string `json:"require,omitempty"`
+	Exclude  []string `json:"exclude,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"` // in a user catalog, removes the product of the same name
 }
 
 type productDocument struct {
 	entry         ProductEntry
 	nameLower     string
 	synonymsLower []string
+	nameTokens    []string
+	synonymTokens [][]string
+	synonymKeys   []string // synonym tokens joined by spaces, for comparing corrected queries
 	vector        map[string]float64
 }
 
 // ProductIndex provides local query expansion and suggestions.
 type ProductIndex struct {
-	products []productDocument
-	idf      map[string]float64
+	products      []productDocument
+	idf           map[string]float64
+	postings      map[string][]posting
+	fuzzy         *fuzzyIndex
+	rules         map[string]ExpansionRule // keyed by ExpansionKey
+	identifiers   map[string]int           // NormalizeIdentifier code to product
+	byName        map[string]int           // lowercase name to product
+	learned       map[string]string        // NormalizeIdentifier code to learned title
+	catalogErrors []error
+
+	// Prefix lookups: every lowercase name and synonym, and every word of
+	// one with the products using it, both sorted.
+	phrases      []phrase
+	words        []string
+	wordProducts [][]int32
+
+	buffers sync.Pool // *scoreBuffer
 }
 
-// NewProductIndex builds a product index from embedded catalog data.
-func NewProductIndex() *ProductIndex {
-	entries := loadProductCatalog()
-	return newProductIndexFromEntries(entries)
+// phrase is a product's lowercase name or one of its synonyms.
+type phrase struct {
+	text    string
+	product int32
 }
 
-func newProductIndexFromEntries(entries []ProductEntry) *ProductIndex {
-	idx := &ProductIndex{
-		products: make([]productDocument, 0, len(entries)),
-		idf:      map[string]float64{},
+// posting is one product's weight for a term.
+type posting struct {
+	product int
+	weight  float64
+}
+
+// queryTerm is one query token and, when it is not in the catalog
+// vocabulary, the vocabulary tokens it was corrected to.
+type queryTerm struct {
+	token   string
+	prefix  bool // the last token of a suggestion prefix, possibly unfinished
+	matches []fuzzyMatch
+}
+
+// NewProductIndex builds a product index from the embedded catalog layered
+// with the catalog files at catalogPaths, later files overriding earlier
+// ones. Problems in those files are reported by CatalogErrors.
+func NewProductIndex(catalogPaths ...string) *ProductIndex {
+	layers := [][]ProductEntry{loadProductCatalog()}
+	var problems []error
+	for _, path := range catalogPaths {
+		entries, entryErrs, err := LoadCatalogFile(path)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		problems = append(problems, entryErrs...)
+		layers = append(layers, entries)
 	}
+
+	idx := newProductIndexFromEntries(MergeCatalogs(layers...))
+	idx.catalogErrors = problems
+	return idx
+}
+
+// CatalogErrors returns the unreadable files and invalid entries skipped
+// while building the index.
+func (idx *ProductIndex) CatalogErrors() []error {
+	if idx == nil {
+		return nil
+	}
+	return idx.catalogErrors
+}
+
+// Len returns the number of products in the index.
+func (idx *ProductIndex) Len() int {
+	if idx == nil {
+		return 0
+	}
+	return len(idx.products)
+}
+
+func newProductIndexFromEntries(entries []ProductEntry) *ProductIndex {
+	idx := &ProductIndex{idf: map[string]float64{}}
 	if len(entries) == 0 {
+		idx.buildLookups()
 		return idx
 	}
 
@@ -97,15 +186,7 @@ func newProductIndexFromEntries(entries []ProductEntry) *ProductIndex {
 			df[token]++
 		}
 
-		doc := productDocument{
-			entry:     entry,
-			nameLower: strings.ToLower(entry.Name),
-		}
-		for _, synonym := range entry.Synonyms {
-			doc.synonymsLower = append(doc.synonymsLower, strings.ToLower(synonym))
-		}
-
-		idx.products = append(idx.products, doc)
+		idx.products = append(idx.products, newProductDocument(entry))
 		documentTerms = append(documentTerms, terms)
 	}
 
@@ -113,78 +194,173 @@ func newProductIndexFromEntries(entries []ProductEntry) *ProductIndex {
 	for token, docsWithToken := range df {
 		idx.idf[token] = math.Log((1.0+n)/(1.0+float64(docsWithToken))) + 1.0
 	}
-
 	for i, terms := range documentTerms {
 		vector := weightedVector(terms, idx.idf)
 		idx.products[i].vector = normalizeVector(vector)
 	}
 
+	idx.buildLookups()
 	return idx
 }
 
-// Expand rewrites vague queries into a best-fit product name when confidence is high.
-func (idx *ProductIndex) Expand(query string) string {
-	trimmed := strings.TrimSpace(query)
-	if idx == nil || len(idx.products) == 0 || trimmed == "" {
-		return trimmed
-	}
+// newProductDocument precomputes the lowercase forms and words of entry's
+// name and synonyms. The TF-IDF vector is set by the caller.
+func newProductDocument(entry ProductEntry) productDocument {
+	doc := productDocument{
+		entry:     entry,
+		nameLower: strings.ToLower(entry.Name),
+	}
+	doc.nameTokens = tokenPattern.FindAllString(doc.nameLower, -1)
+	for _, synonym := range entry.Synonyms {
+		lower := strings.ToLower(synonym)
+		tokens := tokenPattern.FindAllString(lower, -1)
+		doc.synonymsLower = append(doc.synonymsLower, lower)
+		doc.synonymTokens = append(doc.synonymTokens, tokens)
+		doc.synonymKeys = append(doc.synonymKeys, strings.Join(withoutStopWords(tokens), " "))
+	}
+	return doc
+}
 
-	tokens := tokenize(trimmed)
-	if len(tokens) == 0 || len(tokens) > maxExpandTokens {
-		return trimmed
-	}
+// buildLookups derives everything lookups use from the products and their
+// vectors: the postings scoring walks, the fuzzy vocabulary, the code and
+// name maps, and the sorted phrases and words prefixes are found in.
+func (idx *ProductIndex) buildLookups() {
+	idx.buildTermLookups()
+	idx.buildPrefixLookups()
+}
+
+// buildTermLookups builds the postings, the fuzzy vocabulary and the code
+// and name maps.
+func (idx *ProductIndex) buildTermLookups() {
+	idx.identifiers = map[string]int{}
+	idx.byName = make(map[string]int, len(idx.products))
+	idx.postings = make(map[string][]posting, len(idx.idf))
 
-	queryVector := normalizeVector(weightedVector(termCounts(tokens), idx.idf))
-	if len(queryVector) == 0 {
-		return trimmed
+	vocabulary := make([]string, 0, len(idx.idf))
+	for token := range idx.idf {
+		vocabulary = append(vocabulary, token)
 	}
+	idx.fuzzy = newFuzzyIndex(vocabulary)
 
-	queryLower := strings.ToLower(trimmed)
-	topName := ""
-	topScore := 0.0
-	secondScore := 0.0
+	for i, product := range idx.products {
+		for _, identifier := range product.entry.Identifiers {
+			if code := NormalizeIdentifier(identifier); code != "" {
+				idx.identifiers[code] = i
+			}
+		}
+		idx.byName[product.nameLower] = i
+		for term, weight := range product.vector {
+			idx.postings[term] = append(idx.postings[term], posting{product: i, weight: weight})
+		}
+	}
+}
 
-	for _, product := range idx.products {
-		score := cosineSimilarity(queryVector, product.vector)
-		if queryLower == product.nameLower {
-			score += 0.50
+// buildPrefixLookups sorts every name and synonym, and every word of one
+// with the products using it.
+func (idx *ProductIndex) buildPrefixLookups() {
+	idx.phrases = idx.phrases[:0]
+	wordProducts := map[string][]int32{}
+	addWords := func(tokens []string, product int32) {
+		for _, token := range tokens {
+			ids := wordProducts[token]
+			if len(ids) == 0 || ids[len(ids)-1] != product {
+				wordProducts[token] = append(ids, product)
+			}
 		}
+	}
+	for i, product := range idx.products {
+		id := int32(i)
+		idx.phrases = append(idx.phrases, phrase{text: product.nameLower, product: id})
 		for _, synonym := range product.synonymsLower {
-			if queryLower == synonym {
-				score += 0.50
-				break
-			}
-			if len(queryLower) >= 3 && strings.HasPrefix(synonym, queryLower) {
-				score += 0.10
-				break
-			}
+			idx.phrases = append(idx.phrases, phrase{text: synonym, product: id})
 		}
-
-		if score > topScore {
-			secondScore = topScore
-			topScore = score
-			topName = product.entry.Name
-			continue
+		addWords(product.nameTokens, id)
+		for _, tokens := range product.synonymTokens {
+			addWords(tokens, id)
 		}
-		if score > secondScore {
-			secondScore = score
+	}
+	sort.Slice(idx.phrases, func(i, j int) bool {
+		if idx.phrases[i].text != idx.phrases[j].text {
+			return idx.phrases[i].text < idx.phrases[j].text
 		}
+		return idx.phrases[i].product < idx.phrases[j].product
+	})
+
+	idx.words = make([]string, 0, len(wordProducts))
+	for word := range wordProducts {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	idx.wordProducts = make([][]int32, len(idx.words))
+	for i, word := range idx.words {
+		idx.wordProducts[i] = wordProducts[word]
 	}
+}
 
-	if topName == "" {
-		return trimmed
+// prefixCandidates marks the products with a name or synonym, or with
+// words set a word of one, starting with prefix.
+func (idx *ProductIndex) prefixCandidates(prefix string, words bool, scores *scoreBuffer) {
+	if prefix == "" {
+		return
 	}
-	if topScore < minExpandScore {
-		return trimmed
+	i := sort.Search(len(idx.phrases), func(i int) bool { return idx.phrases[i].text >= prefix })
+	for ; i < len(idx.phrases) && strings.HasPrefix(idx.phrases[i].text, prefix); i++ {
+		scores.mark(int(idx.phrases[i].product), prefixMark)
 	}
-	if (topScore - secondScore) < minExpandSeparation {
-		return trimmed
+	if !words {
+		return
 	}
-	if strings.EqualFold(trimmed, topName) {
-		return trimmed
+	i = sort.SearchStrings(idx.words, prefix)
+	for ; i < len(idx.words) && strings.HasPrefix(idx.words[i], prefix); i++ {
+		for _, product := range idx.wordProducts[i] {
+			scores.mark(int(product), prefixMark)
+		}
 	}
+}
 
-	return topName
+// similarities adds the cosine similarity of queryVector to each product
+// sharing a term with it into scores. Walking the postings of the few query
+// terms keeps this cheap however many products the catalog holds.
+func (idx *ProductIndex) similarities(queryVector map[string]float64, scores *scoreBuffer) {
+	for term, weight := range queryVector {
+		for _, p := range idx.postings[term] {
+			scores.add(p.product, weight*p.weight)
+		}
+	}
+}
+
+// markTerms sets bit i on every product containing term i, or one of its
+// corrections, and returns the bits a product needs to match all terms.
+// An unfinished prefix term is left to matchesTerms.
+func (idx *ProductIndex) markTerms(terms []queryTerm, scores *scoreBuffer) (required uint64) {
+	for i, term := range terms {
+		if i >= 63 {
+			break
+		}
+		bit := uint64(1) << i
+		switch {
+		case len(term.matches) > 0:
+			for _, match := range term.matches {
+				for _, p := range idx.postings[match.token] {
+					scores.mark(p.product, bit)
+				}
+			}
+		case term.prefix:
+			continue
+		default:
+			for _, p := range idx.postings[term.token] {
+				scores.mark(p.product, bit)
+			}
+		}
+		required |= bit
+	}
+	return required
+}
+
+// Expand rewrites vague queries into a best-fit product name when confidence
+// is high, or as pinned by an expansion rule. Explain reports why.
+func (idx *ProductIndex) Expand(query string) string {
+	return idx.Explain(query).Expanded
 }
 
 // Suggest returns ranked product suggestions for the current input prefix.
@@ -194,22 +370,42 @@ func (idx *ProductIndex) Suggest(prefix string) []string {
 		return nil
 	}
 
-	queryVector := normalizeVector(weightedVector(termCounts(tokenize(p)), idx.idf))
-
-	type candidate struct {
-		value string
-		score float64
-	}
-	candidates := make([]candidate, 0, len(idx.products))
-
-	for _, product := range idx.products {
-		baseScore := 0.0
-		if len(queryVector) > 0 {
-			baseScore = cosineSimilarity(queryVector, product.vector)
+	terms, corrected := idx.correctTerms(tokenize(p), !strings.HasSuffix(prefix, " "))
+	queryVector := normalizeVector(weightedVector(termWeights(terms), idx.idf))
+
+	// Only products sharing a term with the query, or with a name,
+	// synonym or word the input starts, can score.
+	scores := idx.acquireScores()
+	defer idx.releaseScores(scores)
+	idx.similarities(queryVector, scores)
+	idx.prefixCandidates(p, true, scores)
+
+	var required uint64
+	if corrected {
+		required = idx.markTerms(terms, scores)
+	}
+
+	top := suggestionTop{limit: maxSuggestions}
+	for _, i := range scores.touched {
+		marks := scores.marked(i)
+		if marks&prefixMark == 0 {
+			// Reached only by a shared term: a candidate only as a typo
+			// match, which needs every term.
+			if corrected && marks&required == required {
+				product := &idx.products[i]
+				if distance, ok := product.matchesTerms(terms); ok {
+					top.offer(product.entry.Name, 1.6-0.3*float64(distance)+scores.score(i))
+				}
+			}
+			continue
 		}
 
+		product := &idx.products[i]
+		baseScore := scores.score(i)
+
 		namePrefix := strings.HasPrefix(product.nameLower, p)
-		nameTokenPrefix := tokenHasPrefix(product.nameLower, p)
+		nameTokenPrefix := tokenHasPrefix(product.nameTokens, p)
+		prefixMatched := namePrefix || nameTokenPrefix
 		if namePrefix || nameTokenPrefix {
 			score := 2.4 + baseScore
 			if namePrefix {
@@ -218,18 +414,16 @@ func (idx *ProductIndex) Suggest(prefix string) []string {
 			if product.nameLower == p {
 				score += 1.0
 			}
-			candidates = append(candidates, candidate{
-				value: product.entry.Name,
-				score: score,
-			})
+			top.offer(product.entry.Name, score)
 		}
 
 		for i, synonym := range product.synonymsLower {
 			synPrefix := strings.HasPrefix(synonym, p)
-			synTokenPrefix := tokenHasPrefix(synonym, p)
+			synTokenPrefix := tokenHasPrefix(product.synonymTokens[i], p)
 			if !synPrefix && !synTokenPrefix {
 				continue
 			}
+			prefixMatched = true
 
 			score := 3.4 + baseScore
 			if synPrefix {
@@ -238,44 +432,87 @@ func (idx *ProductIndex) Suggest(prefix string) []string {
 			if synonym == p {
 				score += 1.0
 			}
-			candidates = append(candidates, candidate{
-				value: product.entry.Synonyms[i],
-				score: score,
-			})
+			top.offer(product.entry.Synonyms[i], score)
 		}
-	}
 
-	sort.Slice(candidates, func(i, j int) bool {
-		if candidates[i].score == candidates[j].score {
-			return candidates[i].value < candidates[j].value
+		// Typo matches rank below every prefix match: at most 1.6 plus a
+		// cosine score of at most 1.0, under the 2.4 of a token prefix.
+		if corrected && !prefixMatched {
+			if distance, ok := product.matchesTerms(terms); ok {
+				top.offer(product.entry.Name, 1.6-0.3*float64(distance)+baseScore)
+			}
 		}
-		return candidates[i].score > candidates[j].score
-	})
+	}
 
-	out := make([]string, 0, maxSuggestions)
-	seen := map[string]struct{}{}
-	for _, c := range candidates {
-		key := strings.ToLower(strings.TrimSpace(c.value))
-		if key == "" {
-			continue
-		}
-		if _, ok := seen[key]; ok {
-			continue
-		}
-		seen[key] = struct{}{}
-		out = append(out, c.value)
-		if len(out) == maxSuggestions {
+	return top.values()
+}
+
+// suggestionTop keeps the best distinct suggestions offered so far: by score,
+// then alphabetically, each value once case-insensitively. It replaces
+// sorting every candidate when a short prefix matches thousands.
+type suggestionTop struct {
+	limit int
+	items []suggestion
+}
+
+type suggestion struct {
+	value string
+	key   string
+	score float64
+}
+
+func (a suggestion) better(b suggestion) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.value < b.value
+}
+
+func (t *suggestionTop) offer(value string, score float64) {
+	c := suggestion{value: value, score: score}
+	if len(t.items) == t.limit && !c.better(t.items[len(t.items)-1]) {
+		return
+	}
+	c.key = strings.ToLower(strings.TrimSpace(value))
+	if c.key == "" {
+		return
+	}
+	pos := len(t.items)
+	for i, item := range t.items {
+		if item.key == c.key {
+			if !c.better(item) {
+				return
+			}
+			pos = i
 			break
 		}
 	}
+	if pos == len(t.items) {
+		if len(t.items) < t.limit {
+			t.items = append(t.items, c)
+		} else {
+			pos--
+		}
+	}
+	t.items[pos] = c
+	for ; pos > 0 && t.items[pos].better(t.items[pos-1]); pos-- {
+		t.items[pos], t.items[pos-1] = t.items[pos-1], t.items[pos]
+	}
+}
+
+func (t *suggestionTop) values() []string {
+	out := make([]string, len(t.items))
+	for i, item := range t.items {
+		out[i] = item.value
+	}
 	return out
 }
 
-func tokenHasPrefix(text, prefix string) bool {
+func tokenHasPrefix(tokens []string, prefix string) bool {
 	if prefix == "" {
 		return false
 	}
-	for _, token := range tokenPattern.FindAllString(strings.ToLower(text), -1) {
+	for _, token := range tokens {
 		if strings.HasPrefix(token, prefix) {
 			return true
 		}
@@ -283,6 +520,100 @@ func tokenHasPrefix(text, prefix string) bool {
 	return false
 }
 
+// correctTerms looks up tokens missing from the catalog vocabulary, such as
+// "playstaion" or "airpod", and attaches their nearest vocabulary tokens.
+// With lastIsPrefix set the final token only has to start a vocabulary
+// token. corrected reports whether any token was corrected.
+func (idx *ProductIndex) correctTerms(tokens []string, lastIsPrefix bool) (terms []queryTerm, corrected bool) {
+	terms = make([]queryTerm, 0, len(tokens))
+	for i, token := range tokens {
+		term := queryTerm{token: token, prefix: lastIsPrefix && i == len(tokens)-1}
+		known := idx.fuzzy.contains(token)
+		if term.prefix {
+			known = idx.fuzzy.hasPrefix(token)
+		}
+		if !known {
+			term.matches = idx.fuzzy.match(token, term.prefix)
+			corrected = corrected || len(term.matches) > 0
+		}
+		terms = append(terms, term)
+	}
+	return terms, corrected
+}
+
+// termWeights counts query terms for the TF-IDF vector. A corrected token
+// counts as its matches, sharing fuzzyTermWeight between them; an unfinished
+// prefix counts only when it is already a whole vocabulary token.
+func termWeights(terms []queryTerm) map[string]float64 {
+	if len(terms) == 0 {
+		return nil
+	}
+	counts := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		if len(term.matches) == 0 {
+			counts[term.token]++
+			continue
+		}
+		share := fuzzyTermWeight / float64(len(term.matches))
+		for _, match := range term.matches {
+			counts[match.token] += share
+		}
+	}
+	return counts
+}
+
+// correctedQueryKey joins the query with each corrected token replaced by
+// its best match, in the form of productDocument.synonymKeys.
+func correctedQueryKey(terms []queryTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if len(term.matches) > 0 {
+			parts = append(parts, term.matches[0].token)
+			continue
+		}
+		parts = append(parts, term.token)
+	}
+	return strings.Join(parts, " ")
+}
+
+// matchesTerms reports whether every query term appears among the product's
+// terms, allowing the corrections, and returns the largest edit distance
+// used.
+func (doc productDocument) matchesTerms(terms []queryTerm) (distance int, ok bool) {
+	for _, term := range terms {
+		switch {
+		case len(term.matches) > 0:
+			found := false
+			for _, match := range term.matches {
+				if _, has := doc.vector[match.token]; has {
+					found = true
+					distance = max(distance, match.distance)
+					break
+				}
+			}
+			if !found {
+				return 0, false
+			}
+		case term.prefix:
+			found := false
+			for token := range doc.vector {
+				if strings.HasPrefix(token, term.token) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return 0, false
+			}
+		default:
+			if _, has := doc.vector[term.token]; !has {
+				return 0, false
+			}
+		}
+	}
+	return distance, true
+}
+
 func loadProductCatalog() []ProductEntry {
 	if len(embeddedProductCatalog) == 0 {
 		return defaultProductCatalog()
@@ -309,6 +640,8 @@ func defaultProductCatalog() []ProductEntry {
 func sanitizeEntry(entry ProductEntry) ProductEntry {
 	entry.Name = strings.TrimSpace(entry.Name)
 	entry.Category = strings.TrimSpace(entry.Category)
+	entry.Released = strings.TrimSpace(entry.Released)
+	entry.FeeClass = strings.ToLower(strings.TrimSpace(entry.FeeClass))
 
 	synonyms := make([]string, 0, len(entry.Synonyms))
 	seen := map[string]struct{}{}
@@ -325,6 +658,26 @@ func sanitizeEntry(entry ProductEntry) ProductEntry {
 		synonyms = append(synonyms, trimmed)
 	}
 	entry.Synonyms = synonyms
+
+	identifiers := make([]string, 0, len(entry.Identifiers))
+	seenCodes := map[string]struct{}{}
+	for _, raw := range entry.Identifiers {
+		code := NormalizeIdentifier(raw)
+		if code == "" {
+			continue
+		}
+		if _, ok := seenCodes[code]; ok {
+			continue
+		}
+		seenCodes[code] = struct{}{}
+		identifiers = append(identifiers, strings.TrimSpace(raw))
+	}
+	if len(identifiers) == 0 {
+		identifiers = nil
+	}
+	entry.Identifiers = identifiers
+	entry.Require = normalizeTerms(entry.Require)
+	entry.Exclude = normalizeTerms(entry.Exclude)
 	return entry
 }
 
@@ -344,17 +697,6 @@ func tokenize(text string) []string {
 	return out
 }
 
-func termCounts(tokens []string) map[string]float64 {
-	if len(tokens) == 0 {
-		return nil
-	}
-	counts := make(map[string]float64, len(tokens))
-	for _, token := range tokens {
-		counts[token]++
-	}
-	return counts
-}
-
 func weightedVector(counts map[string]float64, idf map[string]float64) map[string]float64 {
 	if len(counts) == 0 {
 		return nil
@@ -390,17 +732,3 @@ func normalizeVector(vector map[string]float64) map[string]float64 {
 	}
 	return out
 }
-
-func cosineSimilarity(a, b map[string]float64) float64 {
-	if len(a) == 0 || len(b) == 0 {
-		return 0
-	}
-
-	score := 0.0
-	for term, left := range a {
-		if right, ok := b[term]; ok {
-			score += left * right
-		}
-	}
-	return score
-}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -125,3 +127,153 @@ func TestNewProductIndexLoadsEmbeddedCatalog(t *testing.T) {
 		t.Fatal("expected embedded catalog to provide suggestions")
 	}
 }
+
+func TestProductIndexExpandToleratesTypos(t *testing.T) {
+	idx := newProductIndexFromEntries([]ProductEntry{
+		{Name: "PlayStation 5 Console", Category: "Gaming", Synonyms: []string{"ps5", "playstation 5"}},
+		{Name: "AirPods Pro 2", Category: "Audio", Synonyms: []string{"airpods pro"}},
+		{Name: "Nintendo Switch OLED", Category: "Gaming", Synonyms: []string{"switch oled"}},
+	})
+
+	cases := map[string]string{
+		"playstaion 5":  "PlayStation 5 Console",
+		"airpod pro":    "AirPods Pro 2",
+		"swtich oled":   "Nintendo Switch OLED",
+		"ps4":           "ps4",
+		"zzzzzz widget": "zzzzzz widget",
+	}
+	for query, want := range cases {
+		if got := idx.Expand(query); got != want {
+			t.Fatalf("Expand(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestProductIndexSuggestRanksPrefixesAboveTypos(t *testing.T) {
+	idx := newProductIndexFromEntries([]ProductEntry{
+		{Name: "Playmobil Pirate Ship", Category: "Toys"},
+		{Name: "PlayStation 5 Console", Category: "Gaming", Synonyms: []string{"ps5"}},
+		{Name: "Plyo Box", Category: "Fitness"},
+	})
+
+	got := idx.Suggest("plyas")
+	if len(got) == 0 || got[0] != "PlayStation 5 Console" {
+		t.Fatalf("expected typo to suggest PlayStation, got %v", got)
+	}
+
+	got = idx.Suggest("play")
+	if len(got) != 2 || slices.Contains(got, "Plyo Box") {
+		t.Fatalf("expected only prefix matches for a correct prefix, got %v", got)
+	}
+
+	got = idx.Suggest("plyo")
+	if len(got) == 0 || got[0] != "Plyo Box" {
+		t.Fatalf("expected exact prefix to beat near matches, got %v", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"switch", "switch", 0},
+		{"swtich", "switch", 1},
+		{"playstaion", "playstation", 1},
+		{"airpod", "airpods", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range cases {
+		if got := editDistance(tc.a, tc.b, 3); got != tc.want {
+			t.Fatalf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+	if got := prefixEditDistance("plyas", "playstation", 3); got != 1 {
+		t.Fatalf("expected prefix distance 1, got %d", got)
+	}
+}
+
+// benchmarkCatalogSize is the catalog size keystroke latency is measured at.
+const benchmarkCatalogSize = 50000
+
+// benchmarkCatalog generates n products named like "Acme Falcon 212 Pro" so
+// index lookups can be measured at catalog sizes well beyond the embedded one.
+func benchmarkCatalog(n int) []ProductEntry {
+	brands := []string{"Acme", "Zenith", "Nimbus", "Vertex", "Orion", "Helix", "Quasar", "Pioneer", "Summit", "Cobalt"}
+	lines := []string{"Falcon", "Harbor", "Meridian", "Lantern", "Cascade", "Tundra", "Ember", "Solstice", "Voyager", "Prairie"}
+	variants := []string{"Pro", "Max", "Mini", "Lite", "Plus", "Ultra", "Slim", "Air"}
+	categories := []string{"Gaming", "Audio", "Kitchen", "Toys", "Tools"}
+
+	entries := make([]ProductEntry, 0, n)
+	for i := 0; len(entries) < n; i++ {
+		brand := brands[i%len(brands)]
+		line := lines[(i/len(brands))%len(lines)]
+		model := fmt.Sprintf("%d", 100+i/(len(brands)*len(lines)))
+		variant := variants[i%len(variants)]
+		entries = append(entries, ProductEntry{
+			Name:     fmt.Sprintf("%s %s %s %s", brand, line, model, variant),
+			Category: categories[i%len(categories)],
+			Synonyms: []string{strings.ToLower(line + " " + model), strings.ToLower(brand + " " + line)},
+		})
+	}
+	return entries
+}
+
+func BenchmarkProductIndexSuggest(b *testing.B) {
+	idx := newProductIndexFromEntries(benchmarkCatalog(benchmarkCatalogSize))
+	for _, query := range []string{"voy", "voyager 14", "voyagr 14", "zenith casade"} {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				idx.Suggest(query)
+			}
+		})
+	}
+}
+
+func BenchmarkProductIndexExpand(b *testing.B) {
+	idx := newProductIndexFromEntries(benchmarkCatalog(benchmarkCatalogSize))
+	for _, query := range []string{"voyager 140", "voyagr 140", "zenith casade"} {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				idx.Expand(query)
+			}
+		})
+	}
+}
+
+func BenchmarkNewProductIndex(b *testing.B) {
+	entries := benchmarkCatalog(benchmarkCatalogSize)
+	for i := 0; i < b.N; i++ {
+		newProductIndexFromEntries(entries)
+	}
+}
+
+func TestProductIndexExplainAppliesRules(t *testing.T) {
+	idx := newProductIndexFromEntries([]ProductEntry{
+		{Name: "Nintendo Switch OLED", Category: "Gaming", Synonyms: []string{"switch oled"}},
+		{Name: "Nintendo Switch Lite", Category: "Gaming", Synonyms: []string{"switch lite"}},
+	})
+
+	got := idx.Explain("switch oled")
+	if !got.Changed() || got.Expanded != "Nintendo Switch OLED" || got.Rule != nil {
+		t.Fatalf("expected scored expansion, got %+v", got)
+	}
+	if len(got.Candidates) != 2 || got.Candidates[1].Name != "Nintendo Switch Lite" || got.Candidates[0].Score <= got.Candidates[1].Score {
+		t.Fatalf("expected both products ranked by score, got %+v", got.Candidates)
+	}
+
+	idx.SetExpansionRules([]ExpansionRule{
+		{Query: "Switch  OLED", Never: true},
+		{Query: "switch", Target: "Nintendo Switch Lite"},
+		{Query: "ignored"},
+	})
+	if got := idx.Explain("switch oled"); got.Changed() || got.Rule == nil || !got.Rule.Never {
+		t.Fatalf("expected never rule to keep the query, got %+v", got)
+	}
+	if got := idx.Expand("SWITCH"); got != "Nintendo Switch Lite" {
+		t.Fatalf("expected pinned expansion, got %q", got)
+	}
+	if got := idx.Expand("ignored"); got != "ignored" {
+		t.Fatalf("expected empty rule to be ignored, got %q", got)
+	}
+}
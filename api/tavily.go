@@ -13,6 +13,8 @@ import (
 	"mrktr/types"
 )
 
+const tavilyMaxResults = 20
+
 // TavilyProvider implements SearchProvider via Tavily.
 type TavilyProvider struct {
 	apiKey    string
@@ -43,30 +45,40 @@ func (p *TavilyProvider) Configured() bool {
 	return p != nil && p.apiKey != ""
 }
 
-func (p *TavilyProvider) Search(ctx context.Context, query string) ([]types.Listing, error) {
+// Search issues one Tavily request. Tavily has no result offsets, so depth
+// only raises max_results up to the API ceiling.
+func (p *TavilyProvider) Search(ctx context.Context, req SearchRequest) ([]types.Listing, error) {
 	if !p.Configured() {
 		return nil, fmt.Errorf("TAVILY_API_KEY not set")
 	}
 
-	searchQuery := fmt.Sprintf("%s price ebay OR mercari OR amazon", query)
+	req = req.normalized()
+	searchQuery := fmt.Sprintf("%s price", req.providerQuery())
 
 	reqBody := map[string]any{
-		"api_key":     p.apiKey,
-		"query":       searchQuery,
-		"max_results": 20,
+		"api_key":             p.apiKey,
+		"query":               searchQuery,
+		"max_results":         min(req.Depth, tavilyMaxResults),
+		"search_depth":        "advanced",
+		"include_domains":     req.domains(),
+		"include_raw_content": true,
+		"country":             req.Market.CountryName,
+	}
+	if freshness := req.freshness(); freshness != "" {
+		reqBody["time_range"] = freshness
 	}
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshal tavily request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.searchURL, bytes.NewReader(jsonBody))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.searchURL, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("create tavily request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(req)
+	resp, err := p.client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request tavily: %w", err)
 	}
@@ -86,9 +98,10 @@ func (p *TavilyProvider) Search(ctx context.Context, query string) ([]types.List
 
 	var result struct {
 		Results []struct {
-			URL     string `json:"url"`
-			Title   string `json:"title"`
-			Content string `json:"content"`
+			URL        string `json:"url"`
+			Title      string `json:"title"`
+			Content    string `json:"content"`
+			RawContent string `json:"raw_content"`
 		} `json:"results"`
 	}
 
@@ -101,7 +114,10 @@ func (p *TavilyProvider) Search(ctx context.Context, query string) ([]types.List
 		data[i].URL = r.URL
 		data[i].Title = r.Title
 		data[i].Description = r.Content
+		data[i].RawContent = r.RawContent
 	}
 
-	return ParseSearchResults(data), nil
+	listings := ParseSearchResultsWith(data, req.parseOptions())
+	req.reportProgress(p.Name(), len(listings), 1)
+	return listings, nil
 }
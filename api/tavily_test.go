@@ -22,7 +22,7 @@ func TestSearchTavilyChecksHTTPStatus(t *testing.T) {
 
 	provider := NewTavilyProvider("tavily-key", "https://tavily.test/search", client)
 
-	_, err := provider.Search(context.Background(), "ps5")
+	_, err := provider.Search(context.Background(), SearchRequest{Query: "ps5"})
 	if err == nil {
 		t.Fatal("expected error for non-2xx tavily response")
 	}
@@ -56,7 +56,8 @@ func TestSearchTavilyBuildsRequestAndParsesResults(t *testing.T) {
 					{
 						"url": "https://ebay.com/itm/123",
 						"title": "Nintendo Switch OLED",
-						"content": "Excellent condition for $249.99"
+						"content": "Excellent condition for $249.99",
+						"raw_content": null
 					}
 				]
 			}`
@@ -70,7 +71,7 @@ func TestSearchTavilyBuildsRequestAndParsesResults(t *testing.T) {
 
 	provider := NewTavilyProvider("tavily-key", "https://tavily.test/search", client)
 
-	results, err := provider.Search(context.Background(), "switch")
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch"})
 	if err != nil {
 		t.Fatalf("expected successful tavily search, got %v", err)
 	}
@@ -91,8 +92,18 @@ func TestSearchTavilyBuildsRequestAndParsesResults(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected query string in request body, got %T", gotBody["query"])
 	}
-	if !strings.Contains(queryVal, "ebay OR mercari OR amazon") {
-		t.Fatalf("expected marketplace filters in query, got %q", queryVal)
+	if strings.Contains(queryVal, "ebay OR") {
+		t.Fatalf("expected marketplace names kept out of query text, got %q", queryVal)
+	}
+	domains, ok := gotBody["include_domains"].([]any)
+	if !ok || len(domains) == 0 || domains[0] != "ebay.com" {
+		t.Fatalf("expected include_domains marketplace list, got %#v", gotBody["include_domains"])
+	}
+	if gotBody["search_depth"] != "advanced" {
+		t.Fatalf("expected advanced search depth, got %#v", gotBody["search_depth"])
+	}
+	if gotBody["include_raw_content"] != true {
+		t.Fatalf("expected raw content to be requested, got %#v", gotBody["include_raw_content"])
 	}
 
 	if len(results) != 1 {
@@ -105,3 +116,79 @@ func TestSearchTavilyBuildsRequestAndParsesResults(t *testing.T) {
 		t.Fatalf("expected parsed price 249.99, got %.2f", results[0].Price)
 	}
 }
+
+func TestSearchTavilyFallsBackToRawContentPrice(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{
+				"results": [
+					{
+						"url": "https://www.mercari.com/us/item/m123",
+						"title": "Steam Deck OLED 512GB",
+						"content": "Great handheld, barely used",
+						"raw_content": "Steam Deck OLED 512GB Price: $429 Ships in 2 days"
+					}
+				]
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	provider := NewTavilyProvider("tavily-key", "https://tavily.test/search", client)
+
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "steam deck"})
+	if err != nil {
+		t.Fatalf("expected successful tavily search, got %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 parsed listing, got %d", len(results))
+	}
+	if results[0].Price != 429 {
+		t.Fatalf("expected raw content price 429, got %.2f", results[0].Price)
+	}
+}
+
+func TestSearchTavilyUsesMarketLocale(t *testing.T) {
+	var gotBody map[string]any
+
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			bodyBytes, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(bodyBytes, &gotBody); err != nil {
+				return nil, err
+			}
+
+			body := `{"results": [{"url": "https://www.ebay.co.uk/itm/1", "title": "Switch OLED", "content": "Buy it now £219.99"}]}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	uk, _ := LookupMarket("UK")
+	provider := NewTavilyProvider("tavily-key", "https://tavily.test/search", client)
+	results, err := provider.Search(context.Background(), SearchRequest{Query: "switch", Market: uk})
+	if err != nil {
+		t.Fatalf("expected successful tavily search, got %v", err)
+	}
+
+	if gotBody["country"] != "united kingdom" {
+		t.Fatalf("expected country hint, got %v", gotBody["country"])
+	}
+	domains, _ := gotBody["include_domains"].([]any)
+	if len(domains) != 2 || domains[0] != "ebay.co.uk" {
+		t.Fatalf("expected UK marketplace domains, got %v", gotBody["include_domains"])
+	}
+	if len(results) != 1 || results[0].Price != 219.99 {
+		t.Fatalf("expected GBP price parsed, got %+v", results)
+	}
+}
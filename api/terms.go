@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"mrktr/types"
+)
+
+// Narrow adds the product's required and excluded terms to a provider
+// request for it. Required terms the query already has are not repeated.
+func (e ProductEntry) Narrow(req SearchRequest) SearchRequest {
+	if len(e.Require) == 0 && len(e.Exclude) == 0 {
+		return req
+	}
+	words := strings.Fields(strings.ToLower(req.Query))
+	req.Phrases = slices.Clone(req.Phrases)
+	for _, term := range e.Require {
+		if slices.Contains(words, term) || containsFold(req.Phrases, term) {
+			continue
+		}
+		req.Phrases = append(req.Phrases, term)
+	}
+	req.Exclude = slices.Clone(req.Exclude)
+	for _, term := range e.Exclude {
+		if !containsFold(req.Exclude, term) {
+			req.Exclude = append(req.Exclude, term)
+		}
+	}
+	return req
+}
+
+// TitleFilter returns the filter that drops listings whose titles lack a
+// required term or mention an excluded one.
+func (e ProductEntry) TitleFilter() types.ResultFilter {
+	return types.ResultFilter{
+		Require: slices.Clone(e.Require),
+		Exclude: slices.Clone(e.Exclude),
+	}
+}
+
+// normalizeTerms lower-cases and collapses the spaces of terms, dropping
+// empty and repeated ones.
+func normalizeTerms(terms []string) []string {
+	var out []string
+	for _, raw := range terms {
+		term := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+		if term != "" && !slices.Contains(out, term) {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+func validateTerms(entry ProductEntry) error {
+	for _, term := range entry.Require {
+		if slices.Contains(entry.Exclude, term) {
+			return fmt.Errorf("term %q is both required and excluded", term)
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"mrktr/types"
+)
+
+func TestProductEntryNarrowsRequestAndTitles(t *testing.T) {
+	entry := sanitizeEntry(ProductEntry{
+		Name:    "Nintendo Switch OLED",
+		Require: []string{" Switch ", "switch", "OLED  Model"},
+		Exclude: []string{"Case", "skin", "", "case"},
+	})
+	if !slices.Equal(entry.Require, []string{"switch", "oled model"}) || !slices.Equal(entry.Exclude, []string{"case", "skin"}) {
+		t.Fatalf("expected normalized terms, got require %v exclude %v", entry.Require, entry.Exclude)
+	}
+
+	req := entry.Narrow(SearchRequest{Query: "Nintendo Switch OLED", Exclude: []string{"broken", "skin"}})
+	if got := req.providerQuery(); got != `Nintendo Switch OLED "oled model" -broken -skin -case` {
+		t.Fatalf("unexpected provider query %q", got)
+	}
+
+	listings := []types.Listing{
+		{Title: "Nintendo Switch OLED Model white"},
+		{Title: "Switch OLED model carrying case"},
+		{Title: "OLED model light switch"},
+		{Title: "Nintendo Switch Lite"},
+	}
+	got := types.ApplyFilter(listings, entry.TitleFilter())
+	if len(got) != 2 || got[0].Title != listings[0].Title || got[1].Title != listings[2].Title {
+		t.Fatalf("expected titles with every required and no excluded term, got %+v", got)
+	}
+}
+
+func TestLoadCatalogFileRejectsConflictingTerms(t *testing.T) {
+	path := writeCatalog(t, `[
+  {"name": "AirPods Pro 2", "synonyms": ["airpods pro"], "require": ["airpods"], "exclude": ["Case Only", "skin"]},
+  {"name": "AirPods Max", "synonyms": ["airpods max"], "require": ["case"], "exclude": ["CASE"]}
+]`)
+
+	entries, entryErrs, err := LoadCatalogFile(path)
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	if len(entries) != 1 || !slices.Equal(entries[0].Exclude, []string{"case only", "skin"}) {
+		t.Fatalf("expected the valid entry with normalized terms, got %+v", entries)
+	}
+	if len(entryErrs) != 1 || !strings.Contains(entryErrs[0].Error(), `term "case" is both required and excluded`) {
+		t.Fatalf("expected a conflicting term error, got %v", entryErrs)
+	}
+}
+
+func TestEmbeddedProductTermsKeepRealListings(t *testing.T) {
+	product, ok := NewProductIndex().Product("Nintendo Switch OLED")
+	if !ok {
+		t.Fatal("expected the embedded Switch OLED product")
+	}
+	req := product.Narrow(SearchRequest{Query: "Nintendo Switch OLED"})
+	if got := req.providerQuery(); !strings.Contains(got, `-"case only"`) || strings.Contains(got, "-case ") {
+		t.Fatalf("expected phrase exclusions to be quoted, got %q", got)
+	}
+
+	listings := []types.Listing{
+		{Title: "Nintendo Switch OLED w/ carrying case"},
+		{Title: "Switch OLED bundle with 3 game cartridges"},
+		{Title: "Switch OLED Discover edition skinny dock"},
+		{Title: "Nintendo Switch OLED case only"},
+		{Title: "Switch OLED skin decal wrap"},
+		{Title: "Mario Kart 8 Nintendo Switch game cartridge"},
+		{Title: "Zelda Tears of the Kingdom Switch cartridge only"},
+	}
+	got := types.ApplyFilter(listings, product.TitleFilter())
+	if len(got) != 3 || got[2].Title != listings[2].Title {
+		t.Fatalf("expected accessory-only and game-only listings dropped, got %+v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+
+	"mrktr/types"
+)
+
+var (
+	storagePattern     = regexp.MustCompile(`(?i)\b(\d{2,4}|[1-8])\s?(gb|tb)\b(\s+(?:of\s+)?(?:ram|memory|ddr\d?))?`)
+	carrierPattern     = regexp.MustCompile(`(?i)\b(unlocked|verizon|at&t|att|t-mobile|tmobile|sprint|cricket|boost\s+mobile|us\s+cellular|metro\s*pcs|vodafone|rogers|telus)\b`)
+	shoeSizePattern    = regexp.MustCompile(`(?i)\b(?:(?:us\s*)?(?:size|sz|men'?s|women'?s|mens|womens)|us\s*[mw])\s*:?\s*(\d{1,2}(?:\.5)?)\b|\bus\s*(\d{1,2}\.5)\b`)
+	apparelSizePattern = regexp.MustCompile(`(?i)\b(?:size|sz)\s*:?\s*(xxs|xs|s|m|l|xl|xxl|xxxl|2xl|3xl|small|medium|large|x-large)\b`)
+	modelNumberPattern = regexp.MustCompile(`\b([A-Z]{1,4}(?:-[A-Z]{0,2})?\d{3,5}[A-Z]{0,3}(?:/[A-Z])?)\b`)
+)
+
+// variantColors lists color names longest first so "space black" wins over "black".
+var variantColors = []string{
+	"space black", "space gray", "space grey", "deep purple", "sierra blue",
+	"alpine green", "pacific blue", "rose gold", "product red", "midnight green",
+	"natural titanium", "blue titanium", "white titanium", "black titanium",
+	"midnight", "starlight", "graphite", "silver", "gold", "black", "white",
+	"blue", "red", "green", "purple", "pink", "yellow", "orange", "gray", "grey",
+	"titanium", "coral", "lavender",
+}
+
+var colorPatterns = func() []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(variantColors))
+	for i, color := range variantColors {
+		out[i] = regexp.MustCompile(`(?i)\b` + strings.ReplaceAll(color, " ", `\s+`) + `\b`)
+	}
+	return out
+}()
+
+var carrierNames = map[string]string{
+	"unlocked":     "Unlocked",
+	"verizon":      "Verizon",
+	"at&t":         "AT&T",
+	"att":          "AT&T",
+	"t-mobile":     "T-Mobile",
+	"tmobile":      "T-Mobile",
+	"sprint":       "Sprint",
+	"cricket":      "Cricket",
+	"boost mobile": "Boost Mobile",
+	"us cellular":  "US Cellular",
+	"metropcs":     "Metro PCS",
+	"metro pcs":    "Metro PCS",
+	"vodafone":     "Vodafone",
+	"rogers":       "Rogers",
+	"telus":        "Telus",
+}
+
+var apparelSizes = map[string]string{
+	"small":   "S",
+	"medium":  "M",
+	"large":   "L",
+	"x-large": "XL",
+	"2xl":     "XXL",
+	"3xl":     "XXXL",
+}
+
+// extractVariant reads variant attributes from a listing. The title is
+// checked first because snippets often mention other listings' variants.
+func extractVariant(title, text string) types.Variant {
+	var v types.Variant
+	for _, source := range []string{title, text} {
+		if v.Storage == "" {
+			v.Storage = extractStorage(source)
+		}
+		if v.Color == "" {
+			v.Color = extractColor(source)
+		}
+		if v.Carrier == "" {
+			v.Carrier = extractCarrier(source)
+		}
+		if v.Size == "" {
+			v.Size = extractSize(source)
+		}
+		if v.Model == "" {
+			v.Model = extractModelNumber(source)
+		}
+	}
+	return v
+}
+
+// extractStorage returns the first storage capacity that is not RAM.
+func extractStorage(text string) string {
+	for _, match := range storagePattern.FindAllStringSubmatch(text, -1) {
+		if match[3] != "" {
+			continue
+		}
+		return match[1] + strings.ToUpper(match[2])
+	}
+	return ""
+}
+
+func extractColor(text string) string {
+	for i, pattern := range colorPatterns {
+		if pattern.MatchString(text) {
+			color := variantColors[i]
+			if color == "space grey" {
+				color = "space gray"
+			}
+			if color == "grey" {
+				color = "gray"
+			}
+			return titleCase(color)
+		}
+	}
+	return ""
+}
+
+func extractCarrier(text string) string {
+	match := carrierPattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	key := strings.Join(strings.Fields(strings.ToLower(match[1])), " ")
+	return carrierNames[key]
+}
+
+func extractSize(text string) string {
+	if match := apparelSizePattern.FindStringSubmatch(text); match != nil {
+		size := strings.ToLower(match[1])
+		if mapped, ok := apparelSizes[size]; ok {
+			return mapped
+		}
+		return strings.ToUpper(size)
+	}
+	// A bare "us" is too common ("US 2 pack", "ships from US") to mark a
+	// shoe size, so the pattern needs a size cue after it or a half size.
+	if match := shoeSizePattern.FindStringSubmatch(text); match != nil {
+		return match[1] + match[2]
+	}
+	return ""
+}
+
+// extractModelNumber matches upper-case model and part numbers such as
+// "A2650", "CFI-1215A" or "SM-S918U". Text is not lower-cased first because
+// case separates part numbers from ordinary words.
+func extractModelNumber(text string) string {
+	for _, match := range modelNumberPattern.FindAllStringSubmatch(text, -1) {
+		candidate := match[1]
+		if isCurrencyCode(candidate) {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+func isCurrencyCode(token string) bool {
+	upper := strings.TrimRight(token, "0123456789")
+	_, ok := currencyFormats[upper]
+	return ok
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
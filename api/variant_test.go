@@ -0,0 +1,92 @@
+package api
+
+import (
+	"testing"
+
+	"mrktr/types"
+)
+
+func TestExtractVariant(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		text  string
+		want  types.Variant
+	}{
+		{
+			name:  "phone",
+			title: "Apple iPhone 14 Pro 128GB Space Black Unlocked A2650",
+			want:  types.Variant{Storage: "128GB", Color: "Space Black", Carrier: "Unlocked", Model: "A2650"},
+		},
+		{
+			name:  "terabyte with space",
+			title: "iPhone 14 Pro Max 1 TB Deep Purple Verizon",
+			want:  types.Variant{Storage: "1TB", Color: "Deep Purple", Carrier: "Verizon"},
+		},
+		{
+			name:  "ram skipped",
+			title: "Galaxy S23 Ultra 12GB RAM 512GB SM-S918U",
+			want:  types.Variant{Storage: "512GB", Model: "SM-S918U"},
+		},
+		{
+			name:  "console part number",
+			title: "PS5 Slim Disc CFI-2015 white",
+			want:  types.Variant{Color: "White", Model: "CFI-2015"},
+		},
+		{
+			name:  "shoe size",
+			title: "Nike Air Jordan 1 Chicago Men's 10.5",
+			want:  types.Variant{Size: "10.5"},
+		},
+		{
+			name:  "us size cue",
+			title: "Adidas Samba OG US M 9",
+			want:  types.Variant{Size: "9"},
+		},
+		{
+			name:  "us half size",
+			title: "New Balance 550 US 11.5",
+			want:  types.Variant{Size: "11.5"},
+		},
+		{
+			name:  "us count is not a size",
+			title: "Duracell AA US 2 pack",
+			want:  types.Variant{},
+		},
+		{
+			name:  "us voltage is not a size",
+			title: "Dyson charger US 12V adapter",
+			want:  types.Variant{},
+		},
+		{
+			name:  "shipping origin is not a size",
+			title: "Ring doorbell ships from US 3 days",
+			want:  types.Variant{},
+		},
+		{
+			name:  "apparel size",
+			title: "Patagonia Better Sweater Size Large grey",
+			want:  types.Variant{Size: "L", Color: "Gray"},
+		},
+		{
+			name:  "title wins over snippet",
+			title: "iPhone 13 256GB",
+			text:  "Also available: 128GB blue",
+			want:  types.Variant{Storage: "256GB", Color: "Blue"},
+		},
+		{
+			name:  "currency code is not a model number",
+			title: "Switch OLED USD300",
+			want:  types.Variant{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := extractVariant(tc.title, tc.title+" "+tc.text)
+			if got != tc.want {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"mrktr/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// catalogWatchInterval is how often catalog files are checked for edits.
+const catalogWatchInterval = 2 * time.Second
+
+// catalogCategory is the category given to products added from a query.
+const catalogCategory = "Custom"
+
+type catalogLoadedMsg struct {
+	Index  *api.ProductIndex
+	Stamp  string
+	Reload bool
+}
+
+type catalogWatchTickMsg struct {
+	Stamp string
+}
+
+type catalogSavedMsg struct {
+	Name string
+	Err  error
+}
+
+// defaultCatalogPath is the user catalog layered over the embedded products.
+func defaultCatalogPath() (string, error) {
+	return configFilePath("catalog.json")
+}
+
+// defaultCatalogIndexPath caches the index built from the catalogs, so a
+// large catalog is not tokenized again on every start.
+func defaultCatalogIndexPath() (string, error) {
+	return configFilePath("catalog-index.bin")
+}
+
+// catalogStamp summarizes the size and modification time of each catalog
+// file so edits can be noticed without a file-system watcher.
+func catalogStamp(paths []string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:-;", path)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String()
+}
+
+func loadCatalogCmd(indexPath string, paths []string, reload bool) tea.Cmd {
+	if len(paths) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		stamp := catalogStamp(paths)
+		return catalogLoadedMsg{Index: api.OpenProductIndex(indexPath, paths...), Stamp: stamp, Reload: reload}
+	}
+}
+
+// reloadCatalogCmd rebuilds the product index unless a reload is already
+// running. A slow build would otherwise overlap the next watch tick; changes
+// made meanwhile leave the loaded stamp stale, so a later tick catches them.
+func (m *Model) reloadCatalogCmd() tea.Cmd {
+	if m.catalogReloading || len(m.catalogPaths) == 0 {
+		return nil
+	}
+	m.catalogReloading = true
+	return loadCatalogCmd(m.catalogIndexPath, m.catalogPaths, true)
+}
+
+func scheduleCatalogWatch(paths []string) tea.Cmd {
+	if len(paths) == 0 {
+		return nil
+	}
+	return tea.Tick(catalogWatchInterval, func(time.Time) tea.Msg {
+		return catalogWatchTickMsg{Stamp: catalogStamp(paths)}
+	})
+}
+
+func saveCatalogEntryCmd(path string, entry api.ProductEntry) tea.Cmd {
+	return func() tea.Msg {
+		return catalogSavedMsg{Name: entry.Name, Err: api.SaveCatalogEntry(path, entry)}
+	}
+}
+
+// parseSynonymsInput splits "ps5, playstation 5" into synonyms.
+func parseSynonymsInput(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// catalogWarning summarizes catalog problems for the status line.
+func catalogWarning(errs []error) string {
+	switch len(errs) {
+	case 0:
+		return ""
+	case 1:
+		return "Catalog: " + errs[0].Error()
+	default:
+		return fmt.Sprintf("Catalog: %d problems; first: %s", len(errs), errs[0].Error())
+	}
+}
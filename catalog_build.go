@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"mrktr/api"
+	"mrktr/types"
+)
+
+// exportNamePattern recovers the query from an export file name built by
+// BuildExportPath.
+var exportNamePattern = regexp.MustCompile(`^mrktr-export-(.+)-\d{8}-\d{6}$`)
+
+// runCatalog implements "mrktr catalog <command>".
+func runCatalog(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "build" {
+		fmt.Fprintln(stderr, "usage: mrktr catalog build [flags] [export.csv|export.json ...]")
+		return 1
+	}
+	return runCatalogBuild(args[1:], stdout, stderr)
+}
+
+// runCatalogBuild implements "mrktr catalog build": it mines listing titles
+// from tracked listings, the parser corpus, learned product codes and the
+// given exports, clusters them into products, and writes the proposals as a
+// catalog file to review and load with --catalog.
+func runCatalogBuild(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("catalog build", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	outFlag := flags.String("o", "", `catalog file to write, "-" for stdout (default: catalog-mined.json in the mrktr config directory)`)
+	minSupport := flags.Int("min-support", api.DefaultMineSupport, "distinct titles a product needs")
+	similarity := flags.Float64("similarity", api.DefaultMineSimilarity, "cosine similarity for a title to join a product (0-1)")
+	category := flags.String("category", "", `category of proposed products (default "Mined")`)
+	includeExisting := flags.Bool("include-existing", false, "also write products the catalog already has")
+	saved := flags.Bool("saved", true, "mine saved history, tracked listings, the parser corpus and learned codes")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if *similarity <= 0 || *similarity > 1 {
+		fmt.Fprintln(stderr, "catalog build: -similarity must be between 0 and 1")
+		return 1
+	}
+
+	var titles []api.MinedTitle
+	var queries []string
+	if *saved {
+		var err error
+		titles, queries, err = loadSavedTitles()
+		if err != nil {
+			fmt.Fprintf(stderr, "catalog build: %v\n", err)
+			return 1
+		}
+	}
+	for _, path := range flags.Args() {
+		exported, err := loadExportTitles(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "catalog build: %v\n", err)
+			return 1
+		}
+		titles = append(titles, exported...)
+	}
+	if len(titles) == 0 {
+		fmt.Fprintln(stderr, "catalog build: no listing titles found; track listings, capture corpus entries or pass exported CSV/JSON files")
+		return 1
+	}
+
+	var known *api.ProductIndex
+	if path, err := defaultCatalogPath(); err == nil {
+		known = api.NewProductIndex(path)
+	} else {
+		known = api.NewProductIndex()
+	}
+	candidates := api.MineCatalog(titles, api.MineOptions{
+		MinSupport: *minSupport,
+		Similarity: *similarity,
+		Category:   *category,
+		Queries:    queries,
+		Known:      known,
+	})
+
+	entries := make([]api.ProductEntry, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Existing == "" || *includeExisting {
+			entries = append(entries, candidate.Entry)
+		}
+	}
+
+	outPath := *outFlag
+	if outPath == "" {
+		path, err := configFilePath("catalog-mined.json")
+		if err != nil {
+			fmt.Fprintf(stderr, "catalog build: %v\n", err)
+			return 1
+		}
+		outPath = path
+	}
+	review := stdout
+	if outPath == "-" {
+		review = stderr
+		if err := writeCatalogEntries(stdout, entries); err != nil {
+			fmt.Fprintf(stderr, "catalog build: %v\n", err)
+			return 1
+		}
+	} else if err := saveMinedCatalog(outPath, entries); err != nil {
+		fmt.Fprintf(stderr, "catalog build: %v\n", err)
+		return 1
+	}
+
+	writeCatalogReview(review, candidates, len(titles), *includeExisting)
+	if outPath != "-" {
+		fmt.Fprintf(review, "\nWrote %d products to %s; review it, then run mrktr --catalog %s\n", len(entries), outPath, outPath)
+	}
+	return 0
+}
+
+// loadSavedTitles gathers titles and past queries from the config directory.
+// Missing files contribute nothing.
+func loadSavedTitles() ([]api.MinedTitle, []string, error) {
+	var titles []api.MinedTitle
+	var queries []string
+
+	if store, err := NewFileHistoryStore(); err == nil {
+		history, err := store.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range history {
+			queries = append(queries, entry.Query)
+		}
+	}
+	if store, err := NewFileQueryStatStore(); err == nil {
+		stats, err := store.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, stat := range stats {
+			if !stat.unproductive() {
+				queries = append(queries, stat.Query)
+			}
+		}
+	}
+	if store, err := NewFileTrackedStore(); err == nil {
+		tracked, err := store.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, listing := range tracked {
+			titles = append(titles, api.MinedTitle{Title: listing.Title, Query: listing.Query})
+		}
+	}
+	if path, err := defaultCorpusPath(); err == nil {
+		corpus, err := LoadCorpus(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range corpus {
+			titles = append(titles, api.MinedTitle{Title: entry.Result.Title})
+		}
+	}
+	if store, err := NewFileLearnedIdentifierStore(); err == nil {
+		learned, err := store.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range learned {
+			titles = append(titles, api.MinedTitle{Title: entry.Title, Identifier: entry.Code})
+		}
+	}
+	return titles, queries, nil
+}
+
+// loadExportTitles reads the titles of a CSV or JSON results export. The
+// query is recovered from the export's file name when it was not renamed.
+func loadExportTitles(path string) ([]api.MinedTitle, error) {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	query := ""
+	if match := exportNamePattern.FindStringSubmatch(base); match != nil {
+		query = strings.ReplaceAll(match[1], "-", " ")
+	}
+
+	var raw []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		titles, err := readCSVTitles(path)
+		if err != nil {
+			return nil, err
+		}
+		raw = titles
+	case ".json":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read export: %w", err)
+		}
+		var listings []types.Listing
+		if err := json.Unmarshal(data, &listings); err != nil {
+			return nil, fmt.Errorf("decode export %s: %w", path, err)
+		}
+		for _, listing := range listings {
+			raw = append(raw, listing.Title)
+		}
+	default:
+		return nil, fmt.Errorf("export %s: want a .csv or .json file", path)
+	}
+
+	titles := make([]api.MinedTitle, 0, len(raw))
+	for _, title := range raw {
+		titles = append(titles, api.MinedTitle{Title: title, Query: query})
+	}
+	return titles, nil
+}
+
+func readCSVTitles(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open export: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read export header %s: %w", path, err)
+	}
+	column := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), "title") {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("export %s has no title column", path)
+	}
+
+	var titles []string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read export %s: %w", path, err)
+		}
+		if column < len(record) {
+			titles = append(titles, record[column])
+		}
+	}
+	return titles, nil
+}
+
+func writeCatalogEntries(w io.Writer, entries []api.ProductEntry) error {
+	body, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode catalog: %w", err)
+	}
+	body = append(body, '\n')
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("write catalog: %w", err)
+	}
+	return nil
+}
+
+func saveMinedCatalog(path string, entries []api.ProductEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create catalog directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create catalog: %w", err)
+	}
+	defer f.Close()
+	return writeCatalogEntries(f, entries)
+}
+
+func writeCatalogReview(w io.Writer, candidates []api.CatalogCandidate, titles int, includeExisting bool) {
+	fmt.Fprintf(w, "Catalog build: %d products proposed from %d titles\n\n", len(candidates), titles)
+	if len(candidates) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "titles\tproduct\tsynonyms\tsample")
+	for _, candidate := range candidates {
+		name := candidate.Entry.Name
+		if candidate.Existing != "" {
+			name += fmt.Sprintf(" (known as %q", candidate.Existing)
+			if !includeExisting {
+				name += ", skipped"
+			}
+			name += ")"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n",
+			candidate.Support, name, strings.Join(candidate.Entry.Synonyms, ", "), truncate(candidate.Sample, 48))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mrktr/api"
+	"mrktr/types"
+)
+
+func TestRunCatalogBuildMinesExportsAndTrackedListings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tracked, err := NewFileTrackedStore()
+	if err != nil {
+		t.Fatalf("tracked store: %v", err)
+	}
+	if err := tracked.Save([]TrackedListing{
+		{Key: "ebay:1", Query: "pyrex 443", Title: "Pyrex 443 Primary Mixing Bowl Yellow", URL: "https://www.ebay.com/itm/1", LastSeen: time.Now()},
+		{Key: "ebay:2", Query: "nintendo switch oled", Title: "Nintendo Switch OLED White Console", URL: "https://www.ebay.com/itm/2", LastSeen: time.Now()},
+	}); err != nil {
+		t.Fatalf("save tracked: %v", err)
+	}
+
+	dir := t.TempDir()
+	export := BuildExportPath(dir, "yellow pyrex bowl", "csv", time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	if err := ExportCSV(export, []types.Listing{
+		{Platform: "eBay", Price: 30, Title: "VINTAGE PYREX 443 PRIMARY YELLOW MIXING BOWL"},
+		{Platform: "eBay", Price: 25, Title: "Pyrex 443 yellow primary mixing bowl FREE SHIPPING"},
+		{Platform: "Mercari", Price: 180, Title: "Nintendo Switch OLED Console Neon"},
+		{Platform: "Mercari", Price: 190, Title: "Nintendo Switch OLED console with dock"},
+	}); err != nil {
+		t.Fatalf("export csv: %v", err)
+	}
+
+	out := filepath.Join(dir, "mined.json")
+	var stdout, stderr bytes.Buffer
+	if code := runCatalog([]string{"build", "-o", out, export}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+
+	entries, entryErrs, err := api.LoadCatalogFile(out)
+	if err != nil || len(entryErrs) > 0 {
+		t.Fatalf("expected a loadable catalog, got %v %v", err, entryErrs)
+	}
+	if len(entries) != 1 || entries[0].Name != "Pyrex 443 Primary Yellow Mixing Bowl" {
+		t.Fatalf("expected only the new product to be written, got %+v", entries)
+	}
+	if !strings.Contains(strings.Join(entries[0].Synonyms, "|"), "yellow pyrex bowl") {
+		t.Fatalf("expected the export query as a synonym, got %v", entries[0].Synonyms)
+	}
+
+	review := stdout.String()
+	for _, want := range []string{"2 products proposed from 6 titles", `known as "Nintendo Switch OLED", skipped`, "Wrote 1 products"} {
+		if !strings.Contains(review, want) {
+			t.Fatalf("expected %q in review, got:\n%s", want, review)
+		}
+	}
+}
+
+func TestRunCatalogBuildRequiresTitles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := runCatalog([]string{"build", "-o", "-"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "no listing titles") {
+		t.Fatalf("expected a missing-titles error, got %d %q", code, stderr.String())
+	}
+	if code := runCatalog([]string{"mine"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected usage error for unknown subcommand, got %d", code)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mrktr/api"
+	"mrktr/idea"
+	"mrktr/types"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// categoryBatchDelay spaces the searches of a category ranking so a
+	// whole category does not burst through provider rate limits.
+	categoryBatchDelay = 2 * time.Second
+	// categoryBatchMaxProducts bounds the products one ranking searches.
+	categoryBatchMaxProducts = 25
+)
+
+// SourcingField is what a category ranking is ordered by.
+type SourcingField int
+
+const (
+	SourcingByProfit SourcingField = iota
+	SourcingBySellThrough
+	SourcingByMedian
+)
+
+// Label returns the name shown for the ranking order.
+func (f SourcingField) Label() string {
+	switch f {
+	case SourcingBySellThrough:
+		return "sell-through"
+	case SourcingByMedian:
+		return "median"
+	default:
+		return "profit"
+	}
+}
+
+func (f SourcingField) next() SourcingField {
+	return (f + 1) % 3
+}
+
+// CategoryBrowser groups the catalog category browser and its batch ranking.
+type CategoryBrowser struct {
+	Open     bool
+	Category string // category whose products are listed; empty lists categories
+	Index    int
+	Order    SourcingField
+	Batch    CategoryBatch
+}
+
+// CategoryBatch is a ranking of every product in one category, searched one
+// product at a time.
+type CategoryBatch struct {
+	Category string
+	Products []api.ProductEntry
+	Rows     []SourcingRow // one per product searched so far
+	Gen      int
+	Running  bool
+
+	cancel context.CancelFunc // cancels the product search in flight
+}
+
+// SourcingRow summarizes what searching one product found: its median
+// price, how many listings sold per active one, and the profit of buying at
+// the 25th percentile and selling at the median on the best platform.
+type SourcingRow struct {
+	Product   string
+	Listings  int
+	Median    float64
+	BuyAt     float64
+	Sold      int
+	Active    int
+	NetProfit float64
+	Platform  string
+	Err       error
+}
+
+// SellThrough returns sold listings per active listing.
+func (r SourcingRow) SellThrough() float64 {
+	return float64(r.Sold) / float64(max(1, r.Active))
+}
+
+func (r SourcingRow) value(field SourcingField) float64 {
+	switch field {
+	case SourcingBySellThrough:
+		return r.SellThrough()
+	case SourcingByMedian:
+		return r.Median
+	default:
+		return r.NetProfit
+	}
+}
+
+// rankSourcing orders rows by field, best first. Products that found no
+// listings go last.
+func rankSourcing(rows []SourcingRow, field SourcingField) []SourcingRow {
+	out := append([]SourcingRow(nil), rows...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if (out[i].Listings > 0) != (out[j].Listings > 0) {
+			return out[i].Listings > 0
+		}
+		if a, b := out[i].value(field), out[j].value(field); a != b {
+			return a > b
+		}
+		return out[i].Product < out[j].Product
+	})
+	return out
+}
+
+// sourcingRow summarizes the listings found for product.
+func (m Model) sourcingRow(product api.ProductEntry, listings []types.Listing, err error) SourcingRow {
+	row := SourcingRow{Product: product.Name, Err: err}
+	stats := idea.CalculateExtendedStatsWith(listings, m.statsOptions())
+	if stats.Count == 0 {
+		return row
+	}
+	row.Listings = stats.Count
+	row.Median = stats.Median
+	row.BuyAt = stats.P25
+	row.Sold = stats.SoldCount
+	row.Active = stats.ActiveCount
+	row.Platform, row.NetProfit = m.bestNetPlatformFor(product.Fees(), row.BuyAt, row.Median)
+	return row
+}
+
+type categoryBatchResultMsg struct {
+	Gen     int
+	Results []types.Listing
+	Err     error
+}
+
+type categoryBatchNextMsg struct {
+	Gen int
+}
+
+// toggleCategoryBrowser shows or hides the category browser in the results
+// panel.
+func (m Model) toggleCategoryBrowser() (tea.Model, tea.Cmd) {
+	m.browse.Open = !m.browse.Open
+	if !m.browse.Open {
+		return m, nil
+	}
+	m.trackedOpen = false
+	m.detailOpen = false
+	m.filterBarActive = false
+	m.browse.Index = min(m.browse.Index, max(0, m.categoryBrowserLen()-1))
+	return m.changeFocus(panelResults)
+}
+
+// categoryBrowserLen is the number of rows the browser lists.
+func (m Model) categoryBrowserLen() int {
+	if m.browse.Category == "" {
+		return len(m.productIndex.Categories())
+	}
+	if rows := m.categoryRanking(); len(rows) > 0 {
+		return len(rows)
+	}
+	return len(m.productIndex.CategoryProducts(m.browse.Category))
+}
+
+// categoryRanking returns the ranked rows of the listed category, if it was
+// ranked.
+func (m Model) categoryRanking() []SourcingRow {
+	if m.browse.Category == "" || !strings.EqualFold(m.browse.Batch.Category, m.browse.Category) {
+		return nil
+	}
+	return rankSourcing(m.browse.Batch.Rows, m.browse.Order)
+}
+
+func (m Model) handleCategoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := m.categoryBrowserLen()
+	m.browse.Index = min(m.browse.Index, max(0, count-1))
+	switch {
+	case key.Matches(msg, m.keys.Down):
+		if m.browse.Index < count-1 {
+			m.browse.Index++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.browse.Index > 0 {
+			m.browse.Index--
+		}
+	case key.Matches(msg, m.keys.SortCycle):
+		m.browse.Order = m.browse.Order.next()
+	case key.Matches(msg, m.keys.RankCategory):
+		if m.browse.Batch.Running {
+			m.stopCategoryBatch()
+			return m, m.setStatusFlash("Ranking stopped", 1500*time.Millisecond)
+		}
+		if m.browse.Category == "" {
+			return m, nil
+		}
+		return m.startCategoryBatch(m.browse.Category)
+	case count == 0:
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		if m.browse.Category == "" {
+			m.browse.Category = m.productIndex.Categories()[m.browse.Index].Name
+			m.browse.Index = 0
+			return m, nil
+		}
+		var name string
+		if rows := m.categoryRanking(); len(rows) > 0 {
+			name = rows[m.browse.Index].Product
+		} else {
+			name = m.productIndex.CategoryProducts(m.browse.Category)[m.browse.Index].Name
+		}
+		m.browse.Open = false
+		m.searchInput.SetValue(name)
+		return m.startSearch(name, true)
+	}
+	return m, nil
+}
+
+// closeCategory goes back from a category's products to the category list.
+func (m Model) closeCategory() Model {
+	categories := m.productIndex.Categories()
+	m.browse.Index = 0
+	for i, category := range categories {
+		if strings.EqualFold(category.Name, m.browse.Category) {
+			m.browse.Index = i
+		}
+	}
+	m.browse.Category = ""
+	return m
+}
+
+// startCategoryBatch searches every product in category, up to
+// categoryBatchMaxProducts, one at a time.
+func (m Model) startCategoryBatch(category string) (tea.Model, tea.Cmd) {
+	products := m.productIndex.CategoryProducts(category)
+	if len(products) == 0 {
+		return m, nil
+	}
+	if len(products) > categoryBatchMaxProducts {
+		products = products[:categoryBatchMaxProducts]
+	}
+	m.stopCategoryBatch()
+	m.browse.Batch = CategoryBatch{
+		Category: category,
+		Products: products,
+		Gen:      m.browse.Batch.Gen + 1,
+		Running:  true,
+	}
+	m.browse.Index = 0
+	cmd := m.categoryBatchSearchCmd()
+	return m, cmd
+}
+
+// stopCategoryBatch cancels the running ranking and its search in flight.
+func (m *Model) stopCategoryBatch() {
+	batch := &m.browse.Batch
+	if batch.cancel != nil {
+		batch.cancel()
+		batch.cancel = nil
+	}
+	if batch.Running {
+		batch.Gen++
+		batch.Running = false
+	}
+}
+
+// categoryBatchSearchCmd searches the next unsearched product of the batch.
+func (m *Model) categoryBatchSearchCmd() tea.Cmd {
+	batch := &m.browse.Batch
+	if !batch.Running || len(batch.Rows) >= len(batch.Products) {
+		return nil
+	}
+	req, ok := m.backgroundRequest(batch.Products[len(batch.Rows)].Name)
+	gen := batch.Gen
+	if !ok {
+		return func() tea.Msg {
+			return categoryBatchResultMsg{Gen: gen, Err: fmt.Errorf("product name is not a valid query")}
+		}
+	}
+	client := m.apiClient
+	if client == nil {
+		client = api.NewEnvClient()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	batch.cancel = cancel
+	return func() tea.Msg {
+		response := client.SearchPricesRequest(ctx, req)
+		return categoryBatchResultMsg{Gen: gen, Results: response.Results, Err: response.Err}
+	}
+}
+
+// updateCategoryBatch records one product's results and schedules the next
+// search after categoryBatchDelay.
+func (m Model) updateCategoryBatch(msg categoryBatchResultMsg) (Model, tea.Cmd) {
+	batch := &m.browse.Batch
+	if msg.Gen != batch.Gen || !batch.Running || len(batch.Rows) >= len(batch.Products) {
+		return m, nil
+	}
+	if batch.cancel != nil {
+		batch.cancel()
+		batch.cancel = nil
+	}
+	product := batch.Products[len(batch.Rows)]
+	listings := types.ApplyFilter(applyPriceCorrections(msg.Results, m.priceCorrections), product.TitleFilter())
+	batch.Rows = append(append([]SourcingRow(nil), batch.Rows...), m.sourcingRow(product, listings, msg.Err))
+	if len(batch.Rows) < len(batch.Products) {
+		gen := batch.Gen
+		return m, tea.Tick(categoryBatchDelay, func(time.Time) tea.Msg {
+			return categoryBatchNextMsg{Gen: gen}
+		})
+	}
+
+	batch.Running = false
+	failed := 0
+	for _, row := range batch.Rows {
+		if row.Err != nil && row.Listings == 0 {
+			failed++
+		}
+	}
+	text := fmt.Sprintf("Ranked %d %s products", len(batch.Rows), batch.Category)
+	if failed > 0 {
+		text += fmt.Sprintf(" (%d searches failed)", failed)
+	}
+	return m, m.setStatusFlash(text, 2500*time.Millisecond)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mrktr/api"
+	"mrktr/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func listingsAt(status string, prices ...float64) []types.Listing {
+	out := make([]types.Listing, len(prices))
+	for i, price := range prices {
+		out[i] = types.Listing{Platform: "eBay", Title: "listing", Price: price, Condition: "Used", Status: status}
+	}
+	return out
+}
+
+func TestRankSourcingOrdersByFieldAndSinksEmptyRows(t *testing.T) {
+	rows := []SourcingRow{
+		{Product: "Empty"},
+		{Product: "Pricey", Listings: 4, Median: 300, NetProfit: -20, Sold: 0, Active: 4},
+		{Product: "Flipper", Listings: 4, Median: 120, NetProfit: 45, Sold: 3, Active: 1},
+	}
+	for _, tc := range []struct {
+		field SourcingField
+		want  []string
+	}{
+		{SourcingByProfit, []string{"Flipper", "Pricey", "Empty"}},
+		{SourcingBySellThrough, []string{"Flipper", "Pricey", "Empty"}},
+		{SourcingByMedian, []string{"Pricey", "Flipper", "Empty"}},
+	} {
+		ranked := rankSourcing(rows, tc.field)
+		for i, want := range tc.want {
+			if ranked[i].Product != want {
+				t.Fatalf("by %s: expected %v, got %+v", tc.field.Label(), tc.want, ranked)
+			}
+		}
+	}
+}
+
+func TestCategoryBrowserRanksCategoryAndSearchesPick(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(`[
+  {"name": "Ember Handheld X", "category": "Handhelds", "synonyms": ["ember x"]},
+  {"name": "Nimbus Pocket 2", "category": "Handhelds", "synonyms": ["nimbus pocket"]}
+]`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	provider := &captureQueryProvider{}
+	m := newTestModel()
+	m.apiClient = api.NewClient(provider)
+	m.productIndex = api.NewProductIndex(path)
+	m.focusedPanel = panelResults
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'B'}})
+	if !m.browse.Open || m.focusedPanel != panelResults {
+		t.Fatal("expected B to open the category browser")
+	}
+	if view := xansi.Strip(m.renderResultsPanel(100, 40)); !strings.Contains(view, "Handhelds") || !strings.Contains(view, "2 products") {
+		t.Fatalf("expected the Handhelds category, got %q", view)
+	}
+	for i, category := range m.productIndex.Categories() {
+		if category.Name == "Handhelds" {
+			m.browse.Index = i
+		}
+	}
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.browse.Category != "Handhelds" {
+		t.Fatalf("expected enter to open the category, got %q", m.browse.Category)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(Model)
+	if !m.browse.Batch.Running || cmd == nil {
+		t.Fatal("expected R to start ranking the category")
+	}
+	first, ok := cmd().(categoryBatchResultMsg)
+	if !ok || provider.query != "Ember Handheld X" {
+		t.Fatalf("expected the first product to be searched, got %#v query %q", first, provider.query)
+	}
+
+	// Stale results from an earlier ranking are ignored.
+	updated, _ = m.Update(categoryBatchResultMsg{Gen: m.browse.Batch.Gen - 1, Results: listingsAt("Active", 1)})
+	m = updated.(Model)
+	if len(m.browse.Batch.Rows) != 0 {
+		t.Fatalf("expected stale results to be ignored, got %+v", m.browse.Batch.Rows)
+	}
+
+	updated, cmd = m.Update(categoryBatchResultMsg{Gen: first.Gen, Results: listingsAt("Active", 300, 310, 320, 330)})
+	m = updated.(Model)
+	if len(m.browse.Batch.Rows) != 1 || !m.browse.Batch.Running || cmd == nil {
+		t.Fatalf("expected the next search to be scheduled, got %+v", m.browse.Batch)
+	}
+	updated, cmd = m.Update(categoryBatchNextMsg{Gen: first.Gen})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected the next product to be searched")
+	}
+	cmd()
+	if provider.query != "Nimbus Pocket 2" {
+		t.Fatalf("expected the second product to be searched, got %q", provider.query)
+	}
+	results := append(listingsAt("Sold", 190, 200, 210), listingsAt("Active", 50, 60)...)
+	updated, _ = m.Update(categoryBatchResultMsg{Gen: first.Gen, Results: results})
+	m = updated.(Model)
+	if m.browse.Batch.Running || !strings.Contains(m.statusFlash, "Ranked 2 Handhelds products") {
+		t.Fatalf("expected the ranking to finish, got %+v flash %q", m.browse.Batch, m.statusFlash)
+	}
+
+	ranked := m.categoryRanking()
+	if len(ranked) != 2 || ranked[0].Product != "Nimbus Pocket 2" || ranked[0].NetProfit <= 0 || ranked[1].NetProfit >= 0 {
+		t.Fatalf("expected the profitable product first, got %+v", ranked)
+	}
+	if ranked[0].Sold != 3 || ranked[0].Active != 2 || ranked[0].SellThrough() != 1.5 {
+		t.Fatalf("expected sell-through from sold and active counts, got %+v", ranked[0])
+	}
+	view := xansi.Strip(m.renderResultsPanel(100, 20))
+	if !strings.Contains(view, "Buy@P25") || strings.Index(view, "Nimbus Pocket 2") > strings.Index(view, "Ember Handheld X") {
+		t.Fatalf("expected the shortlist ordered by profit, got %q", view)
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.browse.Order != SourcingByMedian || m.categoryRanking()[0].Product != "Ember Handheld X" {
+		t.Fatalf("expected s to reorder by median, got %v %+v", m.browse.Order, m.categoryRanking())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	defer m.cancelActiveSearch()
+	if m.browse.Open || m.lastQuery != "Ember Handheld X" {
+		t.Fatalf("expected enter to search the selected product, got open=%v query=%q", m.browse.Open, m.lastQuery)
+	}
+}
+
+func TestCategoryRankingUsesStatsPanelPricing(t *testing.T) {
+	m := newTestModel()
+	lots := []types.Listing{
+		{Platform: "eBay", Title: "lot of 10", Price: 1000, Quantity: 10, Status: "Active"},
+		{Platform: "eBay", Title: "single", Price: 100, Status: "Active"},
+		{Platform: "eBay", Title: "single", Price: 110, Status: "Active"},
+	}
+	if row := m.sourcingRow(api.ProductEntry{Name: "Widget"}, lots, nil); row.Median != 100 {
+		t.Fatalf("expected unit prices like the stats panel, got median %v", row.Median)
+	}
+	m.listedPrices = true
+	if row := m.sourcingRow(api.ProductEntry{Name: "Widget"}, lots, nil); row.Median != 110 {
+		t.Fatalf("expected listed prices when the stats panel shows them, got median %v", row.Median)
+	}
+}
+
+func TestStoppingCategoryRankingCancelsSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(`[
+  {"name": "Ember Handheld X", "category": "Handhelds"},
+  {"name": "Nimbus Pocket 2", "category": "Handhelds"}
+]`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	for _, stop := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune{'R'}},
+		{Type: tea.KeyCtrlC},
+	} {
+		provider := &cancelAwareProvider{
+			firstStartedCh: make(chan struct{}),
+			firstCancelCh:  make(chan struct{}),
+		}
+		m := newTestModel()
+		m.apiClient = api.NewClient(provider)
+		m.productIndex = api.NewProductIndex(path)
+		m.focusedPanel = panelResults
+		m.browse = CategoryBrowser{Open: true, Category: "Handhelds"}
+
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+		m = updated.(Model)
+		if !m.browse.Batch.Running || cmd == nil {
+			t.Fatal("expected R to start ranking the category")
+		}
+		done := make(chan tea.Msg, 1)
+		go func() { done <- cmd() }()
+		<-provider.firstStartedCh
+
+		m = sendKey(t, m, stop)
+		select {
+		case <-provider.firstCancelCh:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %s to cancel the search in flight", stop)
+		}
+		<-done
+		if m.browse.Batch.Running {
+			t.Fatalf("expected %s to stop the ranking", stop)
+		}
+	}
+}
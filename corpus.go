@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mrktr/api"
+	"mrktr/types"
+)
+
+// maxCorpusLine bounds one JSONL corpus entry; page text is already trimmed.
+const maxCorpusLine = 1 << 20
+
+func defaultCorpusPath() (string, error) {
+	return configFilePath("parser-corpus.jsonl")
+}
+
+// corpusEntryFor labels the listing's source payload with what the user set
+// in correction: a picked price, condition or status. Fields the user did not
+// correct stay unlabeled, since the parser's own values would score
+// themselves.
+func corpusEntryFor(listing types.Listing, correction PriceCorrection, currency string, now time.Time) (api.CorpusEntry, bool) {
+	if listing.Source == nil {
+		return api.CorpusEntry{}, false
+	}
+	return api.CorpusEntry{
+		Result:     *listing.Source,
+		Currency:   currency,
+		CapturedAt: now,
+		Label: api.CorpusLabel{
+			Price:     correction.Price,
+			Condition: correction.Condition,
+			Status:    correction.Status,
+		},
+	}, true
+}
+
+// LoadCorpus reads a JSONL corpus. When an entry is captured again, the
+// later line replaces the earlier one.
+func LoadCorpus(path string) ([]api.CorpusEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []api.CorpusEntry{}, nil
+		}
+		return nil, fmt.Errorf("open corpus: %w", err)
+	}
+	defer file.Close()
+
+	var entries []api.CorpusEntry
+	index := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCorpusLine)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry api.CorpusEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("decode corpus line %d: %w", lineNo, err)
+		}
+		id := entry.ID()
+		if i, ok := index[id]; ok && id != "" {
+			entries[i] = entry
+			continue
+		}
+		index[id] = len(entries)
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+	return entries, nil
+}
+
+// AppendCorpusEntry adds one entry to the end of a JSONL corpus.
+func AppendCorpusEntry(path string, entry api.CorpusEntry) error {
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("corpus path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create corpus directory: %w", err)
+	}
+
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode corpus entry: %w", err)
+	}
+	body = append(body, '\n')
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open corpus: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(body); err != nil {
+		return fmt.Errorf("write corpus entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"mrktr/types"
+)
+
+const correctionsMaxEntries = 500
+
+// PriceCorrection records what a user set for a listing the parser got
+// wrong: a price picked from its candidates, and the condition and status
+// set in the detail view. Zero fields are not corrected.
+type PriceCorrection struct {
+	URL       string    `json:"url"` // canonical listing URL
+	Price     float64   `json:"price"`
+	Parsed    float64   `json:"parsed"` // parser's pick when the correction was made
+	Pattern   string    `json:"pattern,omitempty"`
+	Condition string    `json:"condition,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// empty reports whether the correction corrects nothing.
+func (c PriceCorrection) empty() bool {
+	return c.Price <= 0 && c.Condition == "" && c.Status == ""
+}
+
+// CorrectionStore persists price corrections between runs.
+type CorrectionStore = listStore[PriceCorrection]
+
+type FileCorrectionStore = jsonFileStore[PriceCorrection]
+
+func NewFileCorrectionStore() (*FileCorrectionStore, error) {
+	return newJSONFileStore("corrections.json", "price corrections", normalizeCorrections)
+}
+
+func NewFileCorrectionStoreAt(path string) *FileCorrectionStore {
+	return newJSONFileStoreAt(path, "price corrections", normalizeCorrections)
+}
+
+// normalizeCorrections drops empty and duplicate URLs, keeping the newest
+// correction per URL, newest first.
+func normalizeCorrections(corrections []PriceCorrection) []PriceCorrection {
+	if len(corrections) == 0 {
+		return []PriceCorrection{}
+	}
+
+	out := make([]PriceCorrection, 0, min(len(corrections), correctionsMaxEntries))
+	index := make(map[string]int, len(corrections))
+	for _, correction := range corrections {
+		correction.URL = strings.TrimSpace(correction.URL)
+		if correction.URL == "" || correction.empty() {
+			continue
+		}
+		if i, ok := index[correction.URL]; ok {
+			if correction.Timestamp.After(out[i].Timestamp) {
+				out[i] = correction
+			}
+			continue
+		}
+		index[correction.URL] = len(out)
+		out = append(out, correction)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Timestamp.After(out[j].Timestamp)
+	})
+	if len(out) > correctionsMaxEntries {
+		out = out[:correctionsMaxEntries]
+	}
+	return out
+}
+
+func (c PriceCorrection) applyTo(listing *types.Listing) {
+	if c.Price > 0 {
+		listing.Price = c.Price
+	}
+	if c.Condition != "" {
+		listing.Condition = c.Condition
+		listing.ConditionConfidence = 1
+	}
+	if c.Status != "" {
+		listing.Status = c.Status
+	}
+}
+
+// nextCondition returns the condition after current in types.Conditions,
+// wrapping around; unknown conditions start the cycle.
+func nextCondition(current string) string {
+	for i, condition := range types.Conditions {
+		if strings.EqualFold(condition, current) {
+			return types.Conditions[(i+1)%len(types.Conditions)]
+		}
+	}
+	return types.Conditions[0]
+}
+
+// nextStatus toggles a listing between sold and active.
+func nextStatus(current string) string {
+	if strings.EqualFold(current, "Sold") {
+		return "Active"
+	}
+	return "Sold"
+}
+
+// applyPriceCorrections returns listings with saved prices, conditions and
+// statuses applied by canonical URL. The input slice is not modified.
+func applyPriceCorrections(listings []types.Listing, corrections map[string]PriceCorrection) []types.Listing {
+	if len(corrections) == 0 || len(listings) == 0 {
+		return listings
+	}
+	out := append([]types.Listing(nil), listings...)
+	for i := range out {
+		if correction, ok := corrections[strings.TrimSpace(out[i].URL)]; ok {
+			correction.applyTo(&out[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mrktr/types"
+)
+
+func TestFileCorrectionStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrections.json")
+	store := NewFileCorrectionStoreAt(path)
+
+	in := []PriceCorrection{
+		{URL: "https://www.ebay.com/itm/1", Price: 120, Parsed: 15, Pattern: "symbol-prefix", Timestamp: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	if err := store.Save(in); err != nil {
+		t.Fatalf("save corrections: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("load corrections: %v", err)
+	}
+	if len(got) != 1 || got[0].Price != 120 || got[0].Parsed != 15 {
+		t.Fatalf("unexpected corrections: %+v", got)
+	}
+}
+
+func TestNormalizeCorrectionsKeepsNewestPerURL(t *testing.T) {
+	older := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	got := normalizeCorrections([]PriceCorrection{
+		{URL: "https://www.ebay.com/itm/1", Price: 100, Timestamp: older},
+		{URL: " ", Price: 50, Timestamp: newer},
+		{URL: "https://www.ebay.com/itm/2", Price: 0, Timestamp: newer},
+		{URL: "https://www.ebay.com/itm/1", Price: 110, Timestamp: newer},
+	})
+	if len(got) != 1 || got[0].Price != 110 {
+		t.Fatalf("expected newest correction only, got %+v", got)
+	}
+}
+
+func TestApplyPriceCorrectionsMatchesURLWithoutMutatingInput(t *testing.T) {
+	in := []types.Listing{
+		{URL: "https://www.ebay.com/itm/1", Price: 15},
+		{URL: "https://www.ebay.com/itm/2", Price: 80},
+	}
+	corrections := map[string]PriceCorrection{
+		"https://www.ebay.com/itm/1": {URL: "https://www.ebay.com/itm/1", Price: 120, Parsed: 15},
+	}
+
+	got := applyPriceCorrections(in, corrections)
+	if got[0].Price != 120 || got[1].Price != 80 {
+		t.Fatalf("unexpected corrected prices: %+v", got)
+	}
+	if in[0].Price != 15 {
+		t.Fatal("expected input listings to be left unchanged")
+	}
+}
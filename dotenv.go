@@ -15,6 +15,13 @@ var allowedDotEnvKeys = map[string]struct{}{
 	"FIRECRAWL_API_KEY":   {},
 	"MRKTR_LOW_POWER":     {},
 	"MRKTR_REDUCE_MOTION": {},
+	"MRKTR_RESULT_DEPTH":  {},
+	"MRKTR_MAX_PAGES":     {},
+	"MRKTR_MARKET":        {},
+	"MRKTR_TRACK_REFRESH": {},
+	"MRKTR_RULES":         {},
+	"MRKTR_HOME":          {},
+	"MRKTR_PICKUP_COST":   {},
 }
 
 // loadDotEnvFile loads KEY=VALUE pairs from a dotenv-style file.
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"mrktr/api"
+)
+
+// ExpansionRuleStore persists pinned query-expansion rules between runs.
+type ExpansionRuleStore = listStore[api.ExpansionRule]
+
+type FileExpansionRuleStore = jsonFileStore[api.ExpansionRule]
+
+func NewFileExpansionRuleStore() (*FileExpansionRuleStore, error) {
+	return newJSONFileStore("expansions.json", "expansion rules", normalizeExpansionRules)
+}
+
+func NewFileExpansionRuleStoreAt(path string) *FileExpansionRuleStore {
+	return newJSONFileStoreAt(path, "expansion rules", normalizeExpansionRules)
+}
+
+// normalizeExpansionRules keeps one rule per query (the last one given),
+// drops rules that neither block nor pin an expansion, and sorts by query.
+func normalizeExpansionRules(rules []api.ExpansionRule) []api.ExpansionRule {
+	byKey := make(map[string]api.ExpansionRule, len(rules))
+	for _, rule := range rules {
+		rule.Query = strings.Join(strings.Fields(rule.Query), " ")
+		rule.Target = strings.TrimSpace(rule.Target)
+		if rule.Never {
+			rule.Target = ""
+		}
+		key := api.ExpansionKey(rule.Query)
+		if key == "" || (!rule.Never && rule.Target == "") {
+			continue
+		}
+		byKey[key] = rule
+	}
+
+	out := make([]api.ExpansionRule, 0, len(byKey))
+	for _, rule := range byKey {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return api.ExpansionKey(out[i].Query) < api.ExpansionKey(out[j].Query)
+	})
+	return out
+}
+
+// withExpansionRule returns rules with the rule for query replaced by rule,
+// or removed when rule neither blocks nor pins an expansion.
+func withExpansionRule(rules []api.ExpansionRule, query string, rule api.ExpansionRule) []api.ExpansionRule {
+	key := api.ExpansionKey(query)
+	out := make([]api.ExpansionRule, 0, len(rules)+1)
+	for _, existing := range rules {
+		if api.ExpansionKey(existing.Query) != key {
+			out = append(out, existing)
+		}
+	}
+	rule.Query = query
+	return normalizeExpansionRules(append(out, rule))
+}
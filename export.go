@@ -21,7 +21,7 @@ func ExportCSV(path string, listings []types.Listing) error {
 
 	w := csv.NewWriter(f)
 
-	if err := w.Write([]string{"platform", "price", "condition", "status", "title", "url"}); err != nil {
+	if err := w.Write([]string{"platform", "price", "condition", "status", "title", "url", "date", "variant", "location"}); err != nil {
 		return fmt.Errorf("write csv header: %w", err)
 	}
 	for _, listing := range listings {
@@ -32,6 +32,9 @@ func ExportCSV(path string, listings []types.Listing) error {
 			listing.Status,
 			listing.Title,
 			listing.URL,
+			formatExportDate(listing.Date),
+			listing.Variant.String(),
+			listing.Location.String(),
 		}
 		if err := w.Write(row); err != nil {
 			return fmt.Errorf("write csv row: %w", err)
@@ -59,6 +62,13 @@ func ExportJSON(path string, listings []types.Listing) error {
 	return nil
 }
 
+func formatExportDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
+
 func BuildExportPath(homeDir, query, ext string, now time.Time) string {
 	sanitized := sanitizeFilename(query)
 	if sanitized == "" {
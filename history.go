@@ -29,7 +29,7 @@ type FileHistoryStore struct {
 }
 
 func NewFileHistoryStore() (*FileHistoryStore, error) {
-	path, err := defaultHistoryPath()
+	path, err := configFilePath("history.json")
 	if err != nil {
 		return nil, err
 	}
@@ -83,17 +83,18 @@ func (s *FileHistoryStore) Save(entries []HistoryEntry) error {
 	return nil
 }
 
-func defaultHistoryPath() (string, error) {
+// configFilePath resolves name inside the mrktr config directory.
+func configFilePath(name string) (string, error) {
 	if configDir, err := os.UserConfigDir(); err == nil && strings.TrimSpace(configDir) != "" {
-		return filepath.Join(configDir, "mrktr", "history.json"), nil
+		return filepath.Join(configDir, "mrktr", name), nil
 	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil || strings.TrimSpace(homeDir) == "" {
-		return "", fmt.Errorf("resolve history path: %w", err)
+		return "", fmt.Errorf("resolve %s path: %w", name, err)
 	}
 
-	return filepath.Join(homeDir, ".config", "mrktr", "history.json"), nil
+	return filepath.Join(homeDir, ".config", "mrktr", name), nil
 }
 
 func normalizeHistoryEntries(entries []HistoryEntry) []HistoryEntry {
@@ -79,3 +79,56 @@ func TestFormatRelativeTime(t *testing.T) {
 		})
 	}
 }
+
+func TestFileQueryStatStoreSaveLoadMergesQueries(t *testing.T) {
+	store := NewFileQueryStatStoreAt(filepath.Join(t.TempDir(), "query_stats.json"))
+	older := time.Date(2026, 2, 9, 8, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	in := []QueryStat{
+		{Query: "pyrex bowl", Searches: 2, ZeroResults: 1, LastCount: 0, LastSearched: older},
+		{Query: "Pyrex  Bowl", Searches: 1, LastCount: 7, LastSearched: newer},
+		{Query: "ps5", Searches: 0},
+	}
+	if err := store.Save(in); err != nil {
+		t.Fatalf("save query stats: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("load query stats: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one merged query, got %+v", got)
+	}
+	if got[0].Query != "Pyrex Bowl" || got[0].Searches != 3 || got[0].ZeroResults != 1 || got[0].LastCount != 7 {
+		t.Fatalf("unexpected merged stat: %+v", got[0])
+	}
+}
+
+func TestRankSuggestionsWeighsFrequencyRecencyAndYield(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stats := []QueryStat{
+		{Query: "switch joycon", Searches: 1, LastCount: 12, LastSearched: now.Add(-time.Hour)},
+		{Query: "switch dock", Searches: 6, LastCount: 30, LastSearched: now.Add(-48 * time.Hour)},
+		{Query: "switch zelda tears", Searches: 5, ZeroResults: 5, LastSearched: now},
+		{Query: "switch pro controller", Searches: 9, LastCount: 4, LastSearched: now.Add(-120 * 24 * time.Hour)},
+	}
+	products := []string{"Nintendo Switch OLED", "Nintendo Switch Lite"}
+
+	got := rankSuggestions("switch", nil, nil, stats, products, 8, now)
+	want := []string{"switch dock", "switch joycon", "Nintendo Switch OLED", "Nintendo Switch Lite", "switch zelda tears", "switch pro controller"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	history := []string{"switch carrying case"}
+	meta := map[string]HistoryEntry{"switch carrying case": {Query: "switch carrying case", Timestamp: now, ResultCount: 3}}
+	if got := rankSuggestions("SWITCH c", history, meta, nil, products, 8, now); len(got) == 0 || got[0] != "switch carrying case" {
+		t.Fatalf("expected history query without stats to rank first, got %v", got)
+	}
+}
@@ -9,12 +9,17 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-type platformRow struct {
-	Name string
-	Stat PlatformStat
+// marketRow is one bar in the market view: a platform's average or a
+// variant bucket's median.
+type marketRow struct {
+	Name    string
+	Count   int
+	Value   float64
+	Variant bool
 }
 
-// RenderMarketBody renders platform averages plus sold/active summary.
+// RenderMarketBody renders platform averages, or variant medians when stats
+// are grouped, plus sold/active summary.
 func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string {
 	if width < 24 {
 		width = 24
@@ -29,19 +34,12 @@ func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string
 		}
 	}
 
-	rows := make([]platformRow, 0, len(stats.PlatformStats))
-	for name, stat := range stats.PlatformStats {
-		rows = append(rows, platformRow{Name: name, Stat: stat})
-	}
-
-	sort.Slice(rows, func(i, j int) bool {
-		if rows[i].Stat.Count != rows[j].Stat.Count {
-			return rows[i].Stat.Count > rows[j].Stat.Count
-		}
-		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
-	})
+	rows := marketRows(stats)
 
 	maxPlatformRows := maxRows - 2 // sold/active and sell-through lines
+	if stats.BundleStats.Count > 0 {
+		maxPlatformRows--
+	}
 	if maxPlatformRows < 1 {
 		maxPlatformRows = 1
 	}
@@ -51,8 +49,8 @@ func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string
 
 	maxAvg := 0.0
 	for _, row := range rows {
-		if row.Stat.Average > maxAvg {
-			maxAvg = row.Stat.Average
+		if row.Value > maxAvg {
+			maxAvg = row.Value
 		}
 	}
 	if maxAvg <= 0 {
@@ -65,12 +63,12 @@ func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string
 		labelWidth := minInt(8, maxInt(4, width/5))
 		label := truncate(row.Name, labelWidth)
 
-		suffix := fmt.Sprintf("%s (%d)", formatPrice(row.Stat.Average), row.Stat.Count)
+		suffix := fmt.Sprintf("%s (%d)", formatPrice(row.Value), row.Count)
 		if width < 34 {
-			suffix = fmt.Sprintf("%s %d", formatPrice(row.Stat.Average), row.Stat.Count)
+			suffix = fmt.Sprintf("%s %d", formatPrice(row.Value), row.Count)
 		}
 		if width < 28 {
-			suffix = fmt.Sprintf("%d", row.Stat.Count)
+			suffix = fmt.Sprintf("%d", row.Count)
 		}
 
 		barWidth := width - labelWidth - len([]rune(suffix)) - 2
@@ -82,12 +80,24 @@ func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string
 		}
 		barWidth = maxInt(2, barWidth)
 
-		ratio := row.Stat.Average / maxAvg
-		bar := renderPlatformBar(row.Name, ratio, barWidth)
+		ratio := row.Value / maxAvg
+		barStyle := row.Name
+		if row.Variant {
+			barStyle = ""
+		}
+		bar := renderPlatformBar(barStyle, ratio, barWidth)
 		line := fmt.Sprintf("%-*s %s %s", labelWidth, label, bar, suffix)
 		lines = append(lines, clipANSIWidth(line, width))
 	}
 
+	if stats.BundleStats.Count > 0 {
+		bundleLine := fmt.Sprintf("Bundles:%d med %s", stats.BundleStats.Count, formatPrice(stats.BundleStats.Median))
+		if width < 30 {
+			bundleLine = fmt.Sprintf("B:%d %s", stats.BundleStats.Count, formatPrice(stats.BundleStats.Median))
+		}
+		lines = append(lines, clipANSIWidth(bundleLine, width))
+	}
+
 	var statusLine string
 	switch {
 	case width >= 46:
@@ -125,6 +135,30 @@ func RenderMarketBody(stats ExtendedStatistics, width int, maxRows int) []string
 	return lines
 }
 
+// marketRows returns variant buckets in their grouped order when stats are
+// grouped, otherwise platforms by listing count.
+func marketRows(stats ExtendedStatistics) []marketRow {
+	if len(stats.VariantStats) > 0 {
+		rows := make([]marketRow, len(stats.VariantStats))
+		for i, variant := range stats.VariantStats {
+			rows[i] = marketRow{Name: variant.Label, Count: variant.Count, Value: variant.Median, Variant: true}
+		}
+		return rows
+	}
+
+	rows := make([]marketRow, 0, len(stats.PlatformStats))
+	for name, stat := range stats.PlatformStats {
+		rows = append(rows, marketRow{Name: name, Count: stat.Count, Value: stat.Average})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+	})
+	return rows
+}
+
 func renderPlatformBar(platform string, ratio float64, width int) string {
 	if ratio < 0 {
 		ratio = 0
@@ -57,3 +57,35 @@ func TestRenderMarketBodyClipsStatusLinesOnNarrowWidths(t *testing.T) {
 		t.Fatalf("expected compact sold/active status line, got %q", lines[len(lines)-2])
 	}
 }
+
+func TestRenderMarketBodyShowsVariantMedians(t *testing.T) {
+	stats := CalculateExtendedStatsWith([]types.Listing{
+		{Platform: "eBay", Price: 600, Variant: types.Variant{Storage: "128GB"}},
+		{Platform: "eBay", Price: 1100, Variant: types.Variant{Storage: "1TB"}},
+	}, StatsOptions{GroupBy: types.VariantStorage})
+
+	lines := RenderMarketBody(stats, 70, 6)
+	plain := xansi.Strip(strings.Join(lines, "\n"))
+	if !strings.Contains(plain, "128GB") || !strings.Contains(plain, "1TB") {
+		t.Fatalf("expected variant rows, got %q", plain)
+	}
+	if strings.Contains(plain, "eBay") {
+		t.Fatalf("expected variant rows to replace platform rows, got %q", plain)
+	}
+}
+
+func TestRenderMarketBodyShowsSeparateBundles(t *testing.T) {
+	stats := CalculateExtendedStatsWith([]types.Listing{
+		{Platform: "eBay", Status: "Sold", Price: 400},
+		{Platform: "eBay", Status: "Sold", Price: 520, Bundle: true},
+	}, StatsOptions{Bundles: BundlesSeparate})
+
+	lines := RenderMarketBody(stats, 70, 6)
+	plain := xansi.Strip(strings.Join(lines, "\n"))
+	if !strings.Contains(plain, "Bundles:1") {
+		t.Fatalf("expected bundle summary line, got %q", plain)
+	}
+	if !strings.Contains(lines[len(lines)-1], "Sell-through:") {
+		t.Fatalf("expected sell-through line to stay last, got %q", lines[len(lines)-1])
+	}
+}
@@ -1,11 +1,17 @@
 package idea
 
 import (
+	"math"
 	"mrktr/types"
 	"sort"
 	"strings"
+	"time"
 )
 
+// RecencyHalfLife is the age at which a listing counts half as much as a
+// fresh one in recency-weighted statistics.
+const RecencyHalfLife = 30 * 24 * time.Hour
+
 // StatsViewMode controls which statistics view is active in the UI.
 type StatsViewMode int
 
@@ -29,12 +35,30 @@ type ExtendedStatistics struct {
 	PlatformStats  map[string]PlatformStat
 	ConditionStats map[string]ConditionStat
 
+	// PartsCount is the number of for-parts listings left out of the
+	// price statistics. ConditionStats still reports them.
+	PartsCount int
+
+	// BundleCount is the number of bundles left out of the price statistics;
+	// BundleStats summarizes them when bundles are shown separately.
+	BundleCount int
+	BundleStats types.Statistics
+
 	SoldCount   int
 	ActiveCount int
 	SoldAvg     float64
 	ActiveAvg   float64
 
+	// Recency-weighted values; equal to Average/Median when no listing is dated.
+	DatedCount    int
+	RecentAverage float64
+	RecentMedian  float64
+
 	Histogram []HistogramBin
+
+	// GroupBy is the attribute VariantStats are bucketed by, if any.
+	GroupBy      types.VariantAttribute
+	VariantStats []VariantStat
 }
 
 type PlatformStat struct {
@@ -47,6 +71,47 @@ type PlatformStat struct {
 type ConditionStat struct {
 	Count   int
 	Average float64
+	// Confidence is the mean parser confidence for listings in the bucket.
+	Confidence float64
+}
+
+// VariantStat summarizes one variant bucket, e.g. all 128GB listings.
+type VariantStat struct {
+	Label string
+	types.Statistics
+}
+
+// BundleMode controls how listings sold with extras feed the statistics.
+type BundleMode int
+
+const (
+	BundlesInclude BundleMode = iota
+	BundlesExclude
+	BundlesSeparate
+)
+
+// String returns the mode's short display label.
+func (b BundleMode) String() string {
+	switch b {
+	case BundlesExclude:
+		return "excluded"
+	case BundlesSeparate:
+		return "separate"
+	default:
+		return "included"
+	}
+}
+
+// StatsOptions controls how listings feed extended statistics.
+type StatsOptions struct {
+	Now time.Time
+	// IncludeParts keeps for-parts listings in the price statistics.
+	IncludeParts bool
+	// UnitPrices divides lot prices by their quantity.
+	UnitPrices bool
+	Bundles    BundleMode
+	// GroupBy fills VariantStats with one entry per attribute value.
+	GroupBy types.VariantAttribute
 }
 
 type HistogramBin struct {
@@ -64,16 +129,56 @@ type runningPlatformStat struct {
 }
 
 type runningConditionStat struct {
-	Count int
-	Sum   float64
+	Count      int
+	Sum        float64
+	Confidence float64
 }
 
 func CalculateExtendedStats(listings []types.Listing) ExtendedStatistics {
+	return CalculateExtendedStatsAt(listings, time.Now())
+}
+
+// CalculateExtendedStatsAt computes statistics with listing ages measured from now.
+func CalculateExtendedStatsAt(listings []types.Listing, now time.Time) ExtendedStatistics {
+	return CalculateExtendedStatsWith(listings, StatsOptions{Now: now})
+}
+
+// CalculateExtendedStatsWith computes statistics using opts. For-parts
+// listings only count toward ConditionStats unless opts.IncludeParts is set.
+func CalculateExtendedStatsWith(all []types.Listing, opts StatsOptions) ExtendedStatistics {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.UnitPrices {
+		all = unitPriced(all)
+	}
+	listings := make([]types.Listing, 0, len(all))
+	var bundles []types.Listing
+	partsCount := 0
+	for _, listing := range all {
+		if !opts.IncludeParts && types.IsForParts(listing.Condition) {
+			partsCount++
+			continue
+		}
+		if listing.Bundle && opts.Bundles != BundlesInclude {
+			bundles = append(bundles, listing)
+			continue
+		}
+		listings = append(listings, listing)
+	}
+
 	stats := ExtendedStatistics{
 		Statistics:     types.CalculateStats(listings),
 		Spread:         "N/A",
 		PlatformStats:  map[string]PlatformStat{},
-		ConditionStats: map[string]ConditionStat{},
+		ConditionStats: calculateConditionStats(all),
+		PartsCount:     partsCount,
+		BundleCount:    len(bundles),
+		GroupBy:        opts.GroupBy,
+		VariantStats:   calculateVariantStats(listings, opts.GroupBy),
+	}
+	if opts.Bundles == BundlesSeparate {
+		stats.BundleStats = types.CalculateStats(bundles)
 	}
 	if len(listings) == 0 {
 		return stats
@@ -81,7 +186,6 @@ func CalculateExtendedStats(listings []types.Listing) ExtendedStatistics {
 
 	prices := make([]float64, len(listings))
 	runningPlatforms := map[string]runningPlatformStat{}
-	runningConditions := map[string]runningConditionStat{}
 
 	var soldSum float64
 	var activeSum float64
@@ -107,12 +211,6 @@ func CalculateExtendedStats(listings []types.Listing) ExtendedStatistics {
 		p.Sum += price
 		runningPlatforms[platform] = p
 
-		condition := normalizeBucketKey(listing.Condition, "Unknown")
-		c := runningConditions[condition]
-		c.Count++
-		c.Sum += price
-		runningConditions[condition] = c
-
 		if strings.EqualFold(strings.TrimSpace(listing.Status), "sold") {
 			stats.SoldCount++
 			soldSum += price
@@ -142,17 +240,6 @@ func CalculateExtendedStats(listings []types.Listing) ExtendedStatistics {
 		}
 	}
 
-	for name, c := range runningConditions {
-		avg := 0.0
-		if c.Count > 0 {
-			avg = c.Sum / float64(c.Count)
-		}
-		stats.ConditionStats[name] = ConditionStat{
-			Count:   c.Count,
-			Average: avg,
-		}
-	}
-
 	sort.Float64s(prices)
 	stats.P10 = calculatePercentile(prices, 0.10)
 	stats.P25 = calculatePercentile(prices, 0.25)
@@ -163,10 +250,102 @@ func CalculateExtendedStats(listings []types.Listing) ExtendedStatistics {
 	stats.CoV = calculateCoV(stats.StdDev, stats.Average)
 	stats.Spread = classifySpread(stats.CoV)
 	stats.Histogram = calculateHistogramBins(prices)
+	stats.DatedCount, stats.RecentAverage, stats.RecentMedian = calculateRecencyWeighted(listings, opts.Now)
+	if stats.DatedCount == 0 {
+		stats.RecentAverage = stats.Average
+		stats.RecentMedian = stats.Median
+	}
 
 	return stats
 }
 
+// unitPriced returns a copy of listings with lot prices divided per unit.
+func unitPriced(listings []types.Listing) []types.Listing {
+	out := make([]types.Listing, len(listings))
+	for i, listing := range listings {
+		listing.Price = listing.UnitPrice()
+		out[i] = listing
+	}
+	return out
+}
+
+// calculateConditionStats buckets every listing by canonical condition,
+// for-parts included.
+func calculateConditionStats(listings []types.Listing) map[string]ConditionStat {
+	running := map[string]runningConditionStat{}
+	for _, listing := range listings {
+		condition := normalizeBucketKey(types.CanonicalCondition(listing.Condition), "Unknown")
+		c := running[condition]
+		c.Count++
+		c.Sum += listing.Price
+		c.Confidence += listing.ConditionConfidence
+		running[condition] = c
+	}
+
+	out := make(map[string]ConditionStat, len(running))
+	for name, c := range running {
+		out[name] = ConditionStat{
+			Count:      c.Count,
+			Average:    c.Sum / float64(c.Count),
+			Confidence: c.Confidence / float64(c.Count),
+		}
+	}
+	return out
+}
+
+// calculateVariantStats returns per-bucket statistics in GroupByVariant order.
+func calculateVariantStats(listings []types.Listing, attr types.VariantAttribute) []VariantStat {
+	groups := types.GroupByVariant(listings, attr)
+	if len(groups) == 0 {
+		return nil
+	}
+	out := make([]VariantStat, len(groups))
+	for i, group := range groups {
+		out[i] = VariantStat{Label: group.Label, Statistics: types.CalculateStats(group.Listings)}
+	}
+	return out
+}
+
+type weightedPrice struct {
+	price  float64
+	weight float64
+}
+
+// calculateRecencyWeighted weights each price by 0.5^(age/RecencyHalfLife).
+// Undated listings are treated as one half-life old.
+func calculateRecencyWeighted(listings []types.Listing, now time.Time) (int, float64, float64) {
+	dated := 0
+	weighted := make([]weightedPrice, 0, len(listings))
+	var sum, totalWeight float64
+	for _, listing := range listings {
+		weight := 0.5
+		if !listing.Date.IsZero() {
+			dated++
+			age := max(0, now.Sub(listing.Date))
+			weight = math.Pow(0.5, float64(age)/float64(RecencyHalfLife))
+		}
+		weighted = append(weighted, weightedPrice{price: listing.Price, weight: weight})
+		sum += listing.Price * weight
+		totalWeight += weight
+	}
+	if dated == 0 || totalWeight <= 0 {
+		return dated, 0, 0
+	}
+
+	sort.Slice(weighted, func(i, j int) bool { return weighted[i].price < weighted[j].price })
+	half := totalWeight / 2
+	median := weighted[len(weighted)-1].price
+	var running float64
+	for _, item := range weighted {
+		running += item.weight
+		if running >= half {
+			median = item.price
+			break
+		}
+	}
+	return dated, sum / totalWeight, median
+}
+
 func normalizeBucketKey(raw, fallback string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
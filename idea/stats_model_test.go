@@ -4,6 +4,7 @@ import (
 	"math"
 	"mrktr/types"
 	"testing"
+	"time"
 )
 
 func TestCalculateExtendedStatsEmpty(t *testing.T) {
@@ -199,3 +200,103 @@ func assertFloatNear(t *testing.T, got, want, tol float64, label string) {
 		t.Fatalf("%s: expected %.12f, got %.12f", label, want, got)
 	}
 }
+
+func TestCalculateExtendedStatsAtWeightsRecentListings(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	listings := []types.Listing{
+		{Price: 100, Date: now.AddDate(0, 0, -1)},
+		{Price: 100, Date: now.AddDate(0, 0, -2)},
+		{Price: 300, Date: now.AddDate(-1, 0, 0)},
+	}
+
+	stats := CalculateExtendedStatsAt(listings, now)
+	if stats.DatedCount != 3 {
+		t.Fatalf("expected 3 dated listings, got %d", stats.DatedCount)
+	}
+	if stats.RecentAverage >= stats.Average || stats.RecentAverage > 101 {
+		t.Fatalf("expected year-old sale to barely count, got recent avg %.2f vs avg %.2f", stats.RecentAverage, stats.Average)
+	}
+	if stats.RecentMedian != 100 {
+		t.Fatalf("expected recent median 100, got %.2f", stats.RecentMedian)
+	}
+}
+
+func TestCalculateExtendedStatsAtUndatedMatchesPlainStats(t *testing.T) {
+	stats := CalculateExtendedStatsAt([]types.Listing{{Price: 10}, {Price: 30}}, time.Now())
+	if stats.DatedCount != 0 || stats.RecentAverage != stats.Average || stats.RecentMedian != stats.Median {
+		t.Fatalf("expected undated results to mirror plain stats, got %+v", stats)
+	}
+}
+
+func TestCalculateExtendedStatsExcludesPartsByDefault(t *testing.T) {
+	listings := []types.Listing{
+		{Condition: "Used", Price: 400, ConditionConfidence: 0.8},
+		{Condition: "Used", Price: 420, ConditionConfidence: 0.4},
+		{Condition: "For Parts", Price: 60, ConditionConfidence: 0.9},
+	}
+
+	stats := CalculateExtendedStatsWith(listings, StatsOptions{})
+	if stats.Count != 2 || stats.Min != 400 || stats.PartsCount != 1 {
+		t.Fatalf("expected parts listing excluded from price stats, got count=%d min=%.2f parts=%d", stats.Count, stats.Min, stats.PartsCount)
+	}
+	parts, ok := stats.ConditionStats["For Parts"]
+	if !ok || parts.Count != 1 || parts.Average != 60 {
+		t.Fatalf("expected parts bucket in condition stats, got %+v", parts)
+	}
+	assertFloatNear(t, stats.ConditionStats["Used"].Confidence, 0.6, 1e-9, "Used confidence")
+
+	included := CalculateExtendedStatsWith(listings, StatsOptions{IncludeParts: true})
+	if included.Count != 3 || included.Min != 60 || included.PartsCount != 0 {
+		t.Fatalf("expected parts listing included on request, got count=%d min=%.2f parts=%d", included.Count, included.Min, included.PartsCount)
+	}
+}
+
+func TestCalculateExtendedStatsGroupsByVariant(t *testing.T) {
+	listings := []types.Listing{
+		{Price: 600, Variant: types.Variant{Storage: "128GB"}},
+		{Price: 640, Variant: types.Variant{Storage: "128GB"}},
+		{Price: 1100, Variant: types.Variant{Storage: "1TB"}},
+	}
+
+	stats := CalculateExtendedStatsWith(listings, StatsOptions{GroupBy: types.VariantStorage})
+	if stats.GroupBy != types.VariantStorage || len(stats.VariantStats) != 2 {
+		t.Fatalf("expected two storage buckets, got %+v", stats.VariantStats)
+	}
+	small := stats.VariantStats[0]
+	if small.Label != "128GB" || small.Count != 2 || small.Median != 620 {
+		t.Fatalf("unexpected 128GB bucket: %+v", small)
+	}
+	if stats.VariantStats[1].Median != 1100 {
+		t.Fatalf("unexpected 1TB bucket: %+v", stats.VariantStats[1])
+	}
+
+	if plain := CalculateExtendedStats(listings); plain.VariantStats != nil {
+		t.Fatalf("expected no variant stats without GroupBy, got %+v", plain.VariantStats)
+	}
+}
+
+func TestCalculateExtendedStatsUnitPricesAndBundles(t *testing.T) {
+	listings := []types.Listing{
+		{Price: 12},
+		{Price: 60, Quantity: 5},
+		{Price: 520, Bundle: true},
+	}
+
+	listed := CalculateExtendedStatsWith(listings, StatsOptions{})
+	if listed.Count != 3 || listed.Max != 520 {
+		t.Fatalf("expected listed prices with bundles by default, got %+v", listed.Statistics)
+	}
+
+	unit := CalculateExtendedStatsWith(listings, StatsOptions{UnitPrices: true, Bundles: BundlesExclude})
+	if unit.Count != 2 || unit.Min != 12 || unit.Max != 12 || unit.BundleCount != 1 {
+		t.Fatalf("expected unit prices without bundles, got %+v bundles=%d", unit.Statistics, unit.BundleCount)
+	}
+	if unit.BundleStats.Count != 0 {
+		t.Fatalf("expected no bundle summary when excluded, got %+v", unit.BundleStats)
+	}
+
+	separate := CalculateExtendedStatsWith(listings, StatsOptions{UnitPrices: true, Bundles: BundlesSeparate})
+	if separate.Count != 2 || separate.BundleStats.Count != 1 || separate.BundleStats.Median != 520 {
+		t.Fatalf("expected bundles summarized separately, got main=%+v bundles=%+v", separate.Statistics, separate.BundleStats)
+	}
+}
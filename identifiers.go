@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mrktr/api"
+	"mrktr/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const learnedIdentifiersMaxEntries = 1000
+
+// LearnedIdentifierStore persists product codes learned from search results.
+type LearnedIdentifierStore = listStore[api.LearnedIdentifier]
+
+type FileLearnedIdentifierStore = jsonFileStore[api.LearnedIdentifier]
+
+func NewFileLearnedIdentifierStore() (*FileLearnedIdentifierStore, error) {
+	return newJSONFileStore("identifiers.json", "learned identifiers", normalizeLearnedIdentifiers)
+}
+
+func NewFileLearnedIdentifierStoreAt(path string) *FileLearnedIdentifierStore {
+	return newJSONFileStoreAt(path, "learned identifiers", normalizeLearnedIdentifiers)
+}
+
+// normalizeLearnedIdentifiers drops entries without a code or title and
+// keeps the newest title per code, newest first.
+func normalizeLearnedIdentifiers(learned []api.LearnedIdentifier) []api.LearnedIdentifier {
+	if len(learned) == 0 {
+		return []api.LearnedIdentifier{}
+	}
+
+	out := make([]api.LearnedIdentifier, 0, min(len(learned), learnedIdentifiersMaxEntries))
+	index := make(map[string]int, len(learned))
+	for _, entry := range learned {
+		entry.Code = strings.TrimSpace(entry.Code)
+		entry.Title = strings.TrimSpace(entry.Title)
+		key := api.NormalizeIdentifier(entry.Code)
+		if key == "" || entry.Title == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			if !entry.Learned.Before(out[i].Learned) {
+				out[i] = entry
+			}
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, entry)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Learned.After(out[j].Learned)
+	})
+	if len(out) > learnedIdentifiersMaxEntries {
+		out = out[:learnedIdentifiersMaxEntries]
+	}
+	return out
+}
+
+// learnIdentifier records the product title for a code that was searched
+// as-is because neither the catalog nor earlier searches knew it.
+func (m *Model) learnIdentifier(results []types.Listing) tea.Cmd {
+	code := m.pendingIdentifier
+	m.pendingIdentifier = ""
+	if code == "" || len(results) == 0 {
+		return nil
+	}
+
+	titles := make([]string, 0, len(results))
+	for _, listing := range results {
+		titles = append(titles, listing.Title)
+	}
+	title := api.IdentifierTitle(code, titles)
+	if title == "" {
+		return nil
+	}
+
+	m.learnedIdentifiers = normalizeLearnedIdentifiers(append(m.learnedIdentifiers, api.LearnedIdentifier{
+		Code:    code,
+		Title:   title,
+		Learned: time.Now().UTC(),
+	}))
+	m.productIndex.SetLearnedIdentifiers(m.learnedIdentifiers)
+	return tea.Batch(
+		saveStoreCmd(m.identifierStore, m.learnedIdentifiers),
+		m.setStatusFlash(fmt.Sprintf("Learned %s = %q", code, title), 2500*time.Millisecond),
+	)
+}
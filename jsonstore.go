@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listStore persists a list of T between runs.
+type listStore[T any] interface {
+	Load() ([]T, error)
+	Save(items []T) error
+}
+
+// jsonFileStore keeps a list as an indented JSON array in one file. The list
+// goes through normalize on every load and save, so a hand-edited file is
+// cleaned up the same way as one the app wrote.
+type jsonFileStore[T any] struct {
+	path      string
+	what      string // plural noun used in errors, e.g. "query stats"
+	normalize func([]T) []T
+}
+
+// newJSONFileStore returns a store for name in the config directory.
+func newJSONFileStore[T any](name, what string, normalize func([]T) []T) (*jsonFileStore[T], error) {
+	path, err := configFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return newJSONFileStoreAt(path, what, normalize), nil
+}
+
+func newJSONFileStoreAt[T any](path, what string, normalize func([]T) []T) *jsonFileStore[T] {
+	return &jsonFileStore[T]{path: path, what: what, normalize: normalize}
+}
+
+func (s *jsonFileStore[T]) Load() ([]T, error) {
+	if s == nil || strings.TrimSpace(s.path) == "" {
+		return nil, fmt.Errorf("store path is empty")
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []T{}, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", s.what, err)
+	}
+
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", s.what, err)
+	}
+
+	return s.normalize(items), nil
+}
+
+func (s *jsonFileStore[T]) Save(items []T) error {
+	if s == nil || strings.TrimSpace(s.path) == "" {
+		return fmt.Errorf("store path is empty")
+	}
+
+	normalized := s.normalize(items)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create %s directory: %w", s.what, err)
+	}
+
+	body, err := json.MarshalIndent(normalized, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", s.what, err)
+	}
+	body = append(body, '\n')
+
+	if err := os.WriteFile(s.path, body, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", s.what, err)
+	}
+	return nil
+}
+
+type storeLoadedMsg[T any] struct {
+	Items []T
+	Err   error
+}
+
+type storeSavedMsg struct {
+	Err error
+}
+
+func loadStoreCmd[T any](store listStore[T]) tea.Cmd {
+	return func() tea.Msg {
+		if store == nil {
+			return storeLoadedMsg[T]{Items: []T{}}
+		}
+		items, err := store.Load()
+		return storeLoadedMsg[T]{Items: items, Err: err}
+	}
+}
+
+func saveStoreCmd[T any](store listStore[T], items []T) tea.Cmd {
+	snapshot := append([]T(nil), items...)
+	return func() tea.Msg {
+		if store == nil {
+			return storeSavedMsg{}
+		}
+		return storeSavedMsg{Err: store.Save(snapshot)}
+	}
+}
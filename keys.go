@@ -21,16 +21,39 @@ type keyMap struct {
 	HistPrev     key.Binding
 	SortCycle    key.Binding
 	SortReverse  key.Binding
+	GroupBy      key.Binding
 	FilterToggle key.Binding
 	FilterPlat   key.Binding
 	FilterNew    key.Binding
 	FilterUsed   key.Binding
+	FilterRefurb key.Binding
+	FilterParts  key.Binding
+	FilterLots   key.Binding
+	FilterBundle key.Binding
 	FilterStatus key.Binding
+	FilterAge    key.Binding
+	FilterMiles  key.Binding
 	CopyURL      key.Binding
 	CopyListing  key.Binding
 	ExportCSV    key.Binding
 	ExportJSON   key.Binding
 	CalcPlatform key.Binding
+	Market       key.Binding
+	Track        key.Binding
+	Tracked      key.Binding
+	RefreshTrack key.Binding
+	FollowRelist key.Binding
+	IgnoreRelist key.Binding
+	Categories   key.Binding
+	RankCategory key.Binding
+	PickPrice    key.Binding
+	Capture      key.Binding
+	SetCondition key.Binding
+	SetStatus    key.Binding
+	CatalogAdd   key.Binding
+	Literal      key.Binding
+	NeverExpand  key.Binding
+	PinExpand    key.Binding
 }
 
 func defaultKeyMap() keyMap {
@@ -107,6 +130,10 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("S"),
 			key.WithHelp("S", "sort dir"),
 		),
+		GroupBy: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "group by variant"),
+		),
 		FilterToggle: key.NewBinding(
 			key.WithKeys("f"),
 			key.WithHelp("f", "filters"),
@@ -123,10 +150,34 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("u"),
 			key.WithHelp("u", "used"),
 		),
+		FilterRefurb: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refurbished"),
+		),
+		FilterParts: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "parts in stats"),
+		),
+		FilterLots: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "unit/lot prices"),
+		),
+		FilterBundle: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "bundles"),
+		),
 		FilterStatus: key.NewBinding(
 			key.WithKeys("a"),
 			key.WithHelp("a", "status"),
 		),
+		FilterAge: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "age"),
+		),
+		FilterMiles: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "pickup distance"),
+		),
 		CopyURL: key.NewBinding(
 			key.WithKeys("y"),
 			key.WithHelp("y", "copy url"),
@@ -147,6 +198,70 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("p"),
 			key.WithHelp("p", "calc platform"),
 		),
+		Market: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "market"),
+		),
+		Track: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "track listing"),
+		),
+		Tracked: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "tracked"),
+		),
+		RefreshTrack: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "refresh tracked"),
+		),
+		FollowRelist: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "follow relist"),
+		),
+		IgnoreRelist: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "ignore relist"),
+		),
+		Categories: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "browse categories"),
+		),
+		RankCategory: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rank category"),
+		),
+		PickPrice: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "pick price"),
+		),
+		Capture: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "save to parser corpus"),
+		),
+		SetCondition: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "set condition"),
+		),
+		SetStatus: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "set sold/active"),
+		),
+		CatalogAdd: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "add query to catalog"),
+		),
+		Literal: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "re-run literal/expanded"),
+		),
+		NeverExpand: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "never expand query"),
+		),
+		PinExpand: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pin expansion"),
+		),
 	}
 }
 
@@ -158,9 +273,9 @@ func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Search, k.Enter, k.Escape},
 		{k.Down, k.Up, k.HistNext, k.HistPrev},
-		{k.SortCycle, k.SortReverse, k.FilterToggle, k.FilterPlat, k.FilterNew, k.FilterUsed},
-		{k.FilterStatus, k.CopyURL, k.CopyListing, k.ExportCSV, k.ExportJSON, k.CalcPlatform},
+		{k.SortCycle, k.SortReverse, k.GroupBy, k.FilterToggle, k.FilterPlat, k.FilterNew, k.FilterUsed, k.FilterRefurb},
+		{k.FilterStatus, k.FilterAge, k.FilterMiles, k.FilterParts, k.FilterLots, k.FilterBundle, k.CopyURL, k.CopyListing, k.ExportCSV, k.ExportJSON, k.CalcPlatform},
 		{k.StatsSum, k.StatsDist, k.StatsMkt, k.Tab, k.ShiftTab, k.ToggleAnim},
-		{k.Calculator, k.Quit, k.ForceQuit},
+		{k.Calculator, k.Market, k.PickPrice, k.SetCondition, k.SetStatus, k.Capture, k.CatalogAdd, k.Literal, k.NeverExpand, k.PinExpand, k.Track, k.Tracked, k.RefreshTrack, k.FollowRelist, k.IgnoreRelist, k.Categories, k.RankCategory, k.Quit, k.ForceQuit},
 	}
 }
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"mrktr/api"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "parser-eval" {
+		os.Exit(runParserEval(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "catalog" {
+		os.Exit(runCatalog(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	var catalogs stringListFlag
+	flag.Var(&catalogs, "catalog", "extra product catalog JSON file layered over the built-in one (repeatable)")
+	code := flag.String("code", "", "product code (UPC/EAN, ASIN, MPN or set number) to look up and search at startup")
+	flag.Parse()
+
 	if err := loadDotEnvFile(".env"); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to load .env: %v\n", err)
 	}
@@ -19,9 +34,21 @@ func main() {
 		)
 	}
 
+	model := NewModel()
+	model.catalogPaths = append(model.catalogPaths, catalogs...)
+	model.startupCode = strings.TrimSpace(*code)
+	if rulesPath, err := parseRulesPath(); err == nil {
+		rules, err := api.LoadParseRules(rulesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading parsing rules: %v\n", err)
+			os.Exit(1)
+		}
+		model.parseRules = rules
+	}
+
 	// Create new program with our model
 	p := tea.NewProgram(
-		NewModel(),
+		model,
 		tea.WithAltScreen(),       // Use alternate screen buffer
 		tea.WithMouseCellMotion(), // Enable mouse support
 	)
@@ -42,3 +69,15 @@ func hasAnyProviderKeyConfigured() bool {
 	}
 	return false
 }
+
+// stringListFlag collects every value of a repeated flag.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
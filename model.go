@@ -6,6 +6,7 @@ import (
 	"mrktr/idea"
 	"mrktr/types"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,13 @@ const (
 
 const layoutOverhead = 14
 
+// defaultPickupPerMile is the driving cost charged per mile of a pickup
+// round trip when MRKTR_PICKUP_COST is unset.
+const defaultPickupPerMile = 0.67
+
+// searchEventBuffer bounds queued progress events per search; extra events are dropped.
+const searchEventBuffer = 32
+
 // IntroAnimation groups intro animation state.
 type IntroAnimation struct {
 	Show      bool
@@ -95,6 +103,43 @@ type Model struct {
 	// Query enhancement
 	productIndex *api.ProductIndex
 
+	// Product catalogs layered over the embedded one, watched for edits
+	catalogPaths      []string
+	catalogIndexPath  string // cache of the index built from catalogPaths
+	userCatalogPath   string // where queries added with A are saved
+	catalogStamp      string
+	catalogReloading  bool // a reload is building the index; watch ticks wait
+	catalogPrompt     textinput.Model
+	catalogPromptOpen bool
+	catalogQuery      string // query being added to the catalog
+
+	// How the last search was expanded, and rules pinning expansions
+	lastExpansion        api.Expansion
+	lastSearchLiteral    bool // the last search skipped expansion
+	expansionRules       []api.ExpansionRule
+	expansionStore       ExpansionRuleStore
+	expansionPrompt      textinput.Model
+	expansionPromptOpen  bool
+	expansionPromptIndex int // candidate shown in the prompt; tab cycles
+
+	// Product codes learned from the results of searching an unknown code
+	learnedIdentifiers []api.LearnedIdentifier
+	identifierStore    LearnedIdentifierStore
+	pendingIdentifier  string // code searched as-is, learned when results arrive
+	identifiersLoaded  bool
+
+	// startupCode is the -code argument, searched once the catalog and the
+	// learned codes it resolves through have loaded
+	startupCode   string
+	catalogLoaded bool
+
+	// How often each query was searched and how it did, for ranking
+	// suggestions and offering frequent queries to the catalog
+	queryStats       []QueryStat
+	queryStatStore   QueryStatStore
+	pendingQueryStat string          // query searched by the user, counted when results arrive
+	promotionOffered map[string]bool // queries already offered for the catalog
+
 	// Results
 	rawResults      []types.Listing
 	results         []types.Listing
@@ -103,6 +148,15 @@ type Model struct {
 	sortField       types.SortField
 	sortDirection   types.SortDirection
 	resultFilter    types.ResultFilter
+	queryFilter     types.ResultFilter // filters parsed from the search query syntax
+	productFilter   types.ResultFilter // required and excluded title terms of the searched product
+	productExcluded int                // listings productFilter dropped from the results
+	recencyDays     int                // listing-age filter window; zero shows all ages
+	includeParts    bool               // count for-parts listings in price stats
+	listedPrices    bool               // use lot prices as listed instead of per unit
+	bundleMode      idea.BundleMode
+	groupBy         types.VariantAttribute
+	distanceMiles   int // pickup distance filter from resultFilter.Home; zero shows all
 	filterBarActive bool
 	detailOpen      bool
 	stats           types.Statistics
@@ -110,9 +164,11 @@ type Model struct {
 	statsViewMode   idea.StatsViewMode
 
 	// Profit calculator
-	costInput    textinput.Model
-	cost         float64
-	calcPlatform string
+	costInput     textinput.Model
+	cost          float64 // total purchase cost
+	lotUnits      int     // units bought for cost, from "cost/units" input
+	calcPlatform  string
+	pickupPerMile float64 // driving cost per mile for local pickups; zero ignores distance
 
 	// History
 	history      []string
@@ -120,6 +176,28 @@ type Model struct {
 	historyMeta  map[string]HistoryEntry
 	historyStore HistoryStore
 
+	// Tracked listings
+	tracked         []TrackedListing
+	trackedStore    TrackedStore
+	trackedOpen     bool
+	trackedIndex    int
+	trackedRefresh  time.Duration // background refresh interval; zero disables
+	trackRefreshing bool
+	trackCancel     context.CancelFunc // cancels the running refresh
+
+	// Catalog category browser and the ranking of a category's products
+	browse CategoryBrowser
+
+	// Price corrections picked in the detail view, keyed by canonical URL
+	priceCorrections map[string]PriceCorrection
+	correctionStore  CorrectionStore
+
+	// User parsing rules applied ahead of the built-in patterns; nil when unset
+	parseRules *api.ParseRules
+
+	// Labeled parser corpus for "mrktr parser-eval"; empty disables capture
+	corpusPath string
+
 	// State
 	loading        bool
 	loadingDots    int
@@ -135,9 +213,19 @@ type Model struct {
 	// API
 	apiClient *api.Client
 
+	// Search depth and paging limits
+	searchDepth    int
+	searchMaxPages int
+
+	// Regional market: search locale, listing currency and fee schedule
+	market api.Market
+
 	// Search cancellation and stale-response protection
-	searchCancel context.CancelFunc
-	searchGen    int
+	searchCancel    context.CancelFunc
+	searchGen       int
+	searchEvents    <-chan tea.Msg
+	searchProviders []providerStatus
+	searchStreamed  bool
 
 	// Animations
 	focusFlash  FocusFlash
@@ -183,30 +271,90 @@ func NewModel() Model {
 	} else {
 		historyStore = store
 	}
+	var trackedStore TrackedStore
+	if store, err := NewFileTrackedStore(); err == nil {
+		trackedStore = store
+	}
+	var correctionStore CorrectionStore
+	if store, err := NewFileCorrectionStore(); err == nil {
+		correctionStore = store
+	}
+	var expansionStore ExpansionRuleStore
+	if store, err := NewFileExpansionRuleStore(); err == nil {
+		expansionStore = store
+	}
+	var identifierStore LearnedIdentifierStore
+	if store, err := NewFileLearnedIdentifierStore(); err == nil {
+		identifierStore = store
+	}
+	var queryStatStore QueryStatStore
+	if store, err := NewFileQueryStatStore(); err == nil {
+		queryStatStore = store
+	}
+	corpusPath, _ := defaultCorpusPath()
+	var catalogPaths []string
+	userCatalogPath, err := defaultCatalogPath()
+	if err == nil {
+		catalogPaths = []string{userCatalogPath}
+	}
+	catalogIndexPath, _ := defaultCatalogIndexPath()
+
+	cp := textinput.New()
+	cp.CharLimit = 200
+	cp.Width = 40
+	cp.Placeholder = "synonyms, comma separated"
+	ep := textinput.New()
+	ep.CharLimit = 200
+	ep.Width = 40
+	ep.Placeholder = "product name; empty removes the pin"
+	home, homeOK := parseHomeEnv(os.Getenv("MRKTR_HOME"))
+	if !homeOK && startupWarning == "" {
+		startupWarning = "Home location not recognized; set MRKTR_HOME to a US ZIP or \"City, ST\"."
+	}
 
 	return Model{
-		keys:          defaultKeyMap(),
-		help:          hp,
-		intro:         IntroAnimation{Show: true},
-		focusedPanel:  panelSearch,
-		searchInput:   si,
-		productIndex:  api.NewProductIndex(),
-		costInput:     ci,
-		spinner:       sp,
-		rawResults:    []types.Listing{},
-		results:       []types.Listing{},
-		sortField:     types.SortFieldPrice,
-		sortDirection: types.SortDirectionAsc,
-		resultFilter:  types.ResultFilter{},
-		calcPlatform:  "eBay",
-		statsViewMode: idea.StatsViewSummary,
-		extendedStats: idea.CalculateExtendedStats(nil),
-		reduceMotion:  shouldReduceMotionFromEnv(),
-		history:       []string{},
-		historyMeta:   map[string]HistoryEntry{},
-		historyStore:  historyStore,
-		apiClient:     api.NewEnvClient(),
-		warning:       startupWarning,
+		keys:             defaultKeyMap(),
+		help:             hp,
+		intro:            IntroAnimation{Show: true},
+		focusedPanel:     panelSearch,
+		searchInput:      si,
+		productIndex:     api.NewProductIndex(),
+		catalogPaths:     catalogPaths,
+		catalogIndexPath: catalogIndexPath,
+		userCatalogPath:  userCatalogPath,
+		catalogPrompt:    cp,
+		expansionStore:   expansionStore,
+		expansionPrompt:  ep,
+		identifierStore:  identifierStore,
+		queryStatStore:   queryStatStore,
+		costInput:        ci,
+		spinner:          sp,
+		rawResults:       []types.Listing{},
+		results:          []types.Listing{},
+		sortField:        types.SortFieldPrice,
+		sortDirection:    types.SortDirectionAsc,
+		resultFilter:     types.ResultFilter{Home: home},
+		calcPlatform:     "eBay",
+		lotUnits:         1,
+		pickupPerMile:    parsePositiveFloatEnv(os.Getenv("MRKTR_PICKUP_COST"), defaultPickupPerMile),
+		bundleMode:       idea.BundlesSeparate,
+		statsViewMode:    idea.StatsViewSummary,
+		extendedStats:    idea.CalculateExtendedStats(nil),
+		reduceMotion:     shouldReduceMotionFromEnv(),
+		history:          []string{},
+		historyMeta:      map[string]HistoryEntry{},
+		historyStore:     historyStore,
+		tracked:          []TrackedListing{},
+		trackedStore:     trackedStore,
+		trackedRefresh:   time.Duration(parsePositiveIntEnv(os.Getenv("MRKTR_TRACK_REFRESH"), 0)) * time.Minute,
+		priceCorrections: map[string]PriceCorrection{},
+		correctionStore:  correctionStore,
+		corpusPath:       corpusPath,
+		apiClient:        api.NewEnvClient(),
+		searchDepth:      parsePositiveIntEnv(os.Getenv("MRKTR_RESULT_DEPTH"), api.DefaultResultDepth),
+		searchMaxPages:   parsePositiveIntEnv(os.Getenv("MRKTR_MAX_PAGES"), api.DefaultMaxPageRequests),
+		market:           parseMarketEnv(os.Getenv("MRKTR_MARKET")),
+		warning:          startupWarning,
 	}
 }
 
@@ -216,6 +364,13 @@ func (m Model) Init() tea.Cmd {
 		textinput.Blink,
 		m.spinner.Tick,
 		loadHistoryCmd(m.historyStore),
+		loadStoreCmd(m.trackedStore),
+		loadStoreCmd(m.correctionStore),
+		loadStoreCmd(m.expansionStore),
+		loadStoreCmd(m.identifierStore),
+		loadStoreCmd(m.queryStatStore),
+		scheduleTrackedRefresh(m.trackedRefresh),
+		loadCatalogCmd(m.catalogIndexPath, m.catalogPaths, false),
 		tea.Tick(40*time.Millisecond, func(time.Time) tea.Msg {
 			return introTickMsg{}
 		}),
@@ -242,6 +397,34 @@ type SearchResultsMsg struct {
 	gen            int
 }
 
+// searchProgressMsg reports provider paging progress for an in-flight search.
+type searchProgressMsg struct {
+	Progress api.SearchProgress
+	gen      int
+}
+
+// providerResultsMsg delivers one provider's results while a search streams.
+type providerResultsMsg struct {
+	Result api.ProviderResult
+	gen    int
+}
+
+type providerState int
+
+const (
+	providerPending providerState = iota
+	providerFinished
+	providerFailed
+)
+
+// providerStatus tracks one provider's progress within the current search.
+type providerStatus struct {
+	Name     string
+	State    providerState
+	Listings int
+	Page     int
+}
+
 type openURLResultMsg struct {
 	Err error
 }
@@ -264,6 +447,19 @@ type historySavedMsg struct {
 	Err error
 }
 
+// trackedRefreshMsg carries listings found by re-running tracked searches.
+type trackedRefreshMsg struct {
+	Results []types.Listing
+	Err     error
+}
+
+type trackedRefreshTickMsg struct{}
+
+type corpusCapturedMsg struct {
+	Labeled bool // entry carries at least one user-set label
+	Err     error
+}
+
 type statusFlashClearMsg struct {
 	gen int
 }
@@ -291,6 +487,38 @@ func shouldReduceMotionFromEnv() bool {
 		parseBoolishEnv(os.Getenv("MRKTR_REDUCE_MOTION"))
 }
 
+func parsePositiveIntEnv(raw string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func parsePositiveFloatEnv(raw string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// parseHomeEnv resolves MRKTR_HOME. Unset is fine; a value that names no
+// known place reports false.
+func parseHomeEnv(raw string) (types.Location, bool) {
+	if strings.TrimSpace(raw) == "" {
+		return types.Location{}, true
+	}
+	return api.LookupLocation(raw)
+}
+
+func parseMarketEnv(raw string) api.Market {
+	if market, ok := api.LookupMarket(raw); ok {
+		return market
+	}
+	return api.DefaultMarket()
+}
+
 func parseBoolishEnv(raw string) bool {
 	switch strings.ToLower(strings.TrimSpace(raw)) {
 	case "1", "true", "yes", "on":
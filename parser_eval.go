@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"mrktr/api"
+)
+
+// runParserEval implements "mrktr parser-eval": it re-parses the labeled
+// corpus, prints precision and recall per field and per price pattern, and
+// diffs the outcome against the previous run.
+func runParserEval(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("parser-eval", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	corpusFlag := flags.String("corpus", "", "labeled corpus file (default: parser-corpus.jsonl in the mrktr config directory)")
+	baselineFlag := flags.String("baseline", "", "report to diff against (default: the last saved run)")
+	save := flags.Bool("save", true, "save this report as the last run")
+	rulesFlag := flags.String("rules", "", "parsing rules file (default: $MRKTR_RULES or rules.json in the mrktr config directory)")
+	failOnRegression := flags.Bool("fail-on-regression", false, "exit with status 2 when a field regressed")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	corpusPath := *corpusFlag
+	if corpusPath == "" {
+		path, err := defaultCorpusPath()
+		if err != nil {
+			fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+			return 1
+		}
+		corpusPath = path
+	}
+	lastRunPath, err := configFilePath("parser-eval-last.json")
+	if err != nil && (*save || *baselineFlag == "") {
+		fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+		return 1
+	}
+	baselinePath := *baselineFlag
+	if baselinePath == "" {
+		baselinePath = lastRunPath
+	}
+
+	entries, err := LoadCorpus(corpusPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(stderr, "parser-eval: corpus %s is empty; press w in a listing's detail view to capture entries\n", corpusPath)
+		return 1
+	}
+
+	rulesPath := *rulesFlag
+	if rulesPath == "" {
+		if rulesPath, err = parseRulesPath(); err != nil {
+			fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+			return 1
+		}
+	}
+	rules, err := api.LoadParseRules(rulesPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+		return 1
+	}
+
+	report := api.EvaluateCorpus(entries, rules)
+	writeEvalReport(stdout, report, corpusPath)
+
+	regressed := false
+	previous, err := loadEvalReport(baselinePath)
+	switch {
+	case err == nil:
+		changes := api.DiffReports(previous, report)
+		writeEvalChanges(stdout, changes)
+		for _, change := range changes {
+			regressed = regressed || !change.Fixed
+		}
+	case !errors.Is(err, os.ErrNotExist) || *baselineFlag != "":
+		fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+		return 1
+	}
+
+	if *save {
+		if err := saveEvalReport(lastRunPath, report); err != nil {
+			fmt.Fprintf(stderr, "parser-eval: %v\n", err)
+			return 1
+		}
+	}
+	if regressed && *failOnRegression {
+		return 2
+	}
+	return 0
+}
+
+func writeEvalReport(w io.Writer, report api.EvalReport, corpusPath string) {
+	fmt.Fprintf(w, "Parser evaluation: %d entries from %s\n\n", report.Entries, corpusPath)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "field\tlabeled\tpredicted\tcorrect\tprecision\trecall")
+	writeScoreRow(tw, "price", report.Price)
+	writeScoreRow(tw, "condition", report.Condition)
+	writeScoreRow(tw, "status", report.Status)
+	tw.Flush()
+
+	if len(report.Patterns) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nPrice patterns (predicted = chosen, labeled = found the labeled price)")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "pattern\tlabeled\tpredicted\tcorrect\tprecision\trecall")
+	for _, name := range report.PatternNames() {
+		writeScoreRow(tw, name, report.Patterns[name])
+	}
+	tw.Flush()
+}
+
+func writeScoreRow(w io.Writer, name string, score api.FieldScore) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f%%\t%.1f%%\n",
+		name, score.Labeled, score.Predicted, score.Correct, score.Precision()*100, score.Recall()*100)
+}
+
+func writeEvalChanges(w io.Writer, changes []api.EvalChange) {
+	fixed := 0
+	for _, change := range changes {
+		if change.Fixed {
+			fixed++
+		}
+	}
+	fmt.Fprintf(w, "\nSince last run: %d fixed, %d regressed\n", fixed, len(changes)-fixed)
+	for _, change := range changes {
+		marker := "- regressed"
+		if change.Fixed {
+			marker = "+ fixed"
+		}
+		fmt.Fprintf(w, "  %-11s %-9s %s: %s -> %s\n", marker, change.Field, change.ID, change.Before, change.After)
+	}
+}
+
+func loadEvalReport(path string) (api.EvalReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return api.EvalReport{}, err
+		}
+		return api.EvalReport{}, fmt.Errorf("read eval report: %w", err)
+	}
+	var report api.EvalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return api.EvalReport{}, fmt.Errorf("decode eval report: %w", err)
+	}
+	return report, nil
+}
+
+func saveEvalReport(path string, report api.EvalReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create eval report directory: %w", err)
+	}
+	body, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode eval report: %w", err)
+	}
+	body = append(body, '\n')
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return fmt.Errorf("write eval report: %w", err)
+	}
+	return nil
+}
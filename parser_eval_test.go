@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mrktr/api"
+	"mrktr/types"
+)
+
+func TestCorpusAppendAndLoadKeepsLatestCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	listing := types.Listing{
+		Price:     15,
+		Condition: "Used",
+		Status:    "Active",
+		Source:    &types.SearchResult{URL: "https://www.ebay.com/itm/123456789012", Description: "price 300, shipping $15"},
+	}
+
+	entry, ok := corpusEntryFor(listing, PriceCorrection{}, "USD", time.Now().UTC())
+	if !ok {
+		t.Fatal("expected listing with a source to be capturable")
+	}
+	if entry.Label != (api.CorpusLabel{}) {
+		t.Fatalf("expected nothing labeled without a correction, got %+v", entry.Label)
+	}
+	corrected, _ := corpusEntryFor(listing, PriceCorrection{Price: 300, Condition: "For Parts", Status: "Sold"}, "USD", time.Now().UTC())
+	if corrected.Label.Price != 300 || corrected.Label.Condition != "For Parts" || corrected.Label.Status != "Sold" {
+		t.Fatalf("expected corrected price, condition and status labels, got %+v", corrected.Label)
+	}
+	if err := AppendCorpusEntry(path, entry); err != nil {
+		t.Fatalf("append corpus entry: %v", err)
+	}
+	entry.Label.Price = 300
+	if err := AppendCorpusEntry(path, entry); err != nil {
+		t.Fatalf("append corpus entry: %v", err)
+	}
+
+	got, err := LoadCorpus(path)
+	if err != nil {
+		t.Fatalf("load corpus: %v", err)
+	}
+	if len(got) != 1 || got[0].Label.Price != 300 {
+		t.Fatalf("expected the recapture to replace the first entry, got %+v", got)
+	}
+
+	if _, ok := corpusEntryFor(types.Listing{Price: 10}, PriceCorrection{}, "USD", time.Now()); ok {
+		t.Fatal("expected listing without a source to be skipped")
+	}
+}
+
+func TestRunParserEvalReportsScoresAndRegressions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	corpus := filepath.Join(t.TempDir(), "corpus.jsonl")
+	entry := api.CorpusEntry{
+		Result: api.SearchResult{URL: "https://www.ebay.com/itm/123456789012", Title: "Switch", Description: "Sold $250.00"},
+		Label:  api.CorpusLabel{Price: 250, Status: "Sold"},
+	}
+	if err := AppendCorpusEntry(corpus, entry); err != nil {
+		t.Fatalf("append corpus entry: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runParserEval([]string{"-corpus", corpus}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"1 entries", "price", "100.0%", "snippet/symbol-prefix"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Since last run") {
+		t.Fatal("expected no diff on the first run")
+	}
+
+	// Relabeling makes the same parser output a regression against the saved run.
+	entry.Label.Price = 275
+	if err := AppendCorpusEntry(corpus, entry); err != nil {
+		t.Fatalf("append corpus entry: %v", err)
+	}
+	stdout.Reset()
+	code := runParserEval([]string{"-corpus", corpus, "-fail-on-regression"}, &stdout, &stderr)
+	if code != 2 {
+		t.Fatalf("expected regression exit code 2, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "0 fixed, 1 regressed") {
+		t.Fatalf("expected regression diff, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunParserEvalRejectsEmptyCorpus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var stdout, stderr bytes.Buffer
+	code := runParserEval([]string{"-corpus", filepath.Join(t.TempDir(), "missing.jsonl")}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "is empty") {
+		t.Fatalf("expected empty corpus error, got %d: %s", code, stderr.String())
+	}
+}
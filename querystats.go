@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"mrktr/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	queryStatsMaxEntries = 500
+	// queryStatHalfLife is how long until a past search counts half as much
+	// when ranking suggestions.
+	queryStatHalfLife = 14 * 24 * time.Hour
+	// promoteAfterSearches is how often a free-text query must have been
+	// searched before it is offered for the catalog.
+	promoteAfterSearches = 3
+	// productSuggestionWeight is what the top catalog suggestion scores
+	// against learned queries; a query searched once recently with results
+	// scores about 1.
+	productSuggestionWeight = 0.5
+	maxSuggestions          = 8
+)
+
+// QueryStat is what is known about how a query has performed: how often it
+// was searched, how often that returned nothing, and the latest result count.
+// Unlike the history it is not limited to the last few queries.
+type QueryStat struct {
+	Query        string    `json:"query"`
+	Searches     int       `json:"searches"`
+	ZeroResults  int       `json:"zero_results"`
+	LastCount    int       `json:"last_count"`
+	LastSearched time.Time `json:"last_searched"`
+}
+
+// QueryStatStore persists query statistics between runs.
+type QueryStatStore = listStore[QueryStat]
+
+type FileQueryStatStore = jsonFileStore[QueryStat]
+
+func NewFileQueryStatStore() (*FileQueryStatStore, error) {
+	return newJSONFileStore("query_stats.json", "query stats", normalizeQueryStats)
+}
+
+func NewFileQueryStatStoreAt(path string) *FileQueryStatStore {
+	return newJSONFileStoreAt(path, "query stats", normalizeQueryStats)
+}
+
+// normalizeQueryStats merges entries for the same query (case-insensitive),
+// drops empty ones and keeps the most recently searched, newest first.
+func normalizeQueryStats(stats []QueryStat) []QueryStat {
+	if len(stats) == 0 {
+		return []QueryStat{}
+	}
+
+	out := make([]QueryStat, 0, len(stats))
+	index := make(map[string]int, len(stats))
+	for _, stat := range stats {
+		stat.Query = strings.Join(strings.Fields(stat.Query), " ")
+		if stat.Query == "" || stat.Searches <= 0 {
+			continue
+		}
+		stat.ZeroResults = min(max(0, stat.ZeroResults), stat.Searches)
+		stat.LastCount = max(0, stat.LastCount)
+		key := strings.ToLower(stat.Query)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, stat)
+			continue
+		}
+		merged := out[i]
+		merged.Searches += stat.Searches
+		merged.ZeroResults += stat.ZeroResults
+		if stat.LastSearched.After(merged.LastSearched) {
+			merged.Query = stat.Query
+			merged.LastCount = stat.LastCount
+			merged.LastSearched = stat.LastSearched
+		}
+		out[i] = merged
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].LastSearched.After(out[j].LastSearched)
+	})
+	if len(out) > queryStatsMaxEntries {
+		out = out[:queryStatsMaxEntries]
+	}
+	return out
+}
+
+// withQueryResult returns stats with one more search for query that
+// returned count results.
+func withQueryResult(stats []QueryStat, query string, count int, now time.Time) []QueryStat {
+	stat := QueryStat{Query: query, Searches: 1, LastCount: count, LastSearched: now}
+	if count == 0 {
+		stat.ZeroResults = 1
+	}
+	return normalizeQueryStats(append([]QueryStat{stat}, stats...))
+}
+
+// score ranks a query for suggestions: more searches count for more with
+// diminishing returns, old searches fade, and queries that mostly came back
+// empty sink below catalog suggestions.
+func (s QueryStat) score(now time.Time) float64 {
+	if s.Searches <= 0 {
+		return 0
+	}
+	frequency := math.Log2(1 + float64(s.Searches))
+	recency := 1.0
+	if age := now.Sub(s.LastSearched); age > 0 {
+		recency = math.Exp2(-float64(age) / float64(queryStatHalfLife))
+	}
+	yield := 1 - float64(s.ZeroResults)/float64(s.Searches)
+	if s.LastCount == 0 {
+		yield /= 2
+	}
+	return frequency * recency * max(0.05, yield)
+}
+
+// unproductive reports whether the query has never returned anything.
+func (s QueryStat) unproductive() bool {
+	return s.Searches > 0 && s.ZeroResults == s.Searches
+}
+
+// rankSuggestions orders the history and catalog suggestions for prefix by
+// the learned score of each query. Catalog suggestions keep their order and
+// score by position; a catalog product that was also searched adds both.
+func rankSuggestions(prefix string, history []string, meta map[string]HistoryEntry, stats []QueryStat, products []string, limit int, now time.Time) []string {
+	p := strings.ToLower(strings.TrimSpace(prefix))
+	if p == "" || limit <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		text  string
+		score float64
+		order int
+	}
+	candidates := map[string]*candidate{}
+	add := func(text string, score float64) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		key := strings.ToLower(text)
+		if c, ok := candidates[key]; ok {
+			c.score += score
+			return
+		}
+		candidates[key] = &candidate{text: text, score: score, order: len(candidates)}
+	}
+
+	learned := make(map[string]struct{}, len(stats))
+	for _, stat := range stats {
+		if strings.HasPrefix(strings.ToLower(stat.Query), p) {
+			add(stat.Query, stat.score(now))
+		}
+		learned[strings.ToLower(stat.Query)] = struct{}{}
+	}
+	// Queries saved before statistics were kept count as one search.
+	for _, query := range history {
+		key := strings.ToLower(query)
+		if _, ok := learned[key]; ok || !strings.HasPrefix(key, p) {
+			continue
+		}
+		entry := meta[key]
+		stat := QueryStat{Query: query, Searches: 1, LastCount: entry.ResultCount, LastSearched: entry.Timestamp}
+		if entry.Timestamp.IsZero() {
+			stat.LastSearched = now
+			stat.LastCount = 1
+		}
+		if stat.LastCount == 0 {
+			stat.ZeroResults = 1
+		}
+		add(query, stat.score(now))
+	}
+	for i, product := range products {
+		add(product, productSuggestionWeight/float64(1+i))
+	}
+
+	ranked := make([]*candidate, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].order < ranked[j].order
+	})
+
+	out := make([]string, 0, min(limit, len(ranked)))
+	for _, c := range ranked {
+		out = append(out, c.text)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// queryStat returns the statistics recorded for query.
+func (m Model) queryStat(query string) (QueryStat, bool) {
+	for _, stat := range m.queryStats {
+		if strings.EqualFold(stat.Query, query) {
+			return stat, true
+		}
+	}
+	return QueryStat{}, false
+}
+
+// recordQueryResult counts the search the user ran for query, once its
+// results are in, and offers to add a frequent free-text query to the
+// catalog.
+func (m *Model) recordQueryResult(query string, count int) tea.Cmd {
+	if m.pendingQueryStat == "" || !strings.EqualFold(m.pendingQueryStat, query) {
+		return nil
+	}
+	m.pendingQueryStat = ""
+	m.queryStats = withQueryResult(m.queryStats, query, count, time.Now().UTC())
+	cmds := []tea.Cmd{saveStoreCmd(m.queryStatStore, m.queryStats)}
+	if stat, ok := m.queryStat(query); ok && m.shouldOfferPromotion(stat) {
+		if m.promotionOffered == nil {
+			m.promotionOffered = map[string]bool{}
+		}
+		m.promotionOffered[strings.ToLower(stat.Query)] = true
+		cmds = append(cmds, m.setStatusFlash(
+			fmt.Sprintf("Searched %q %d times; it is not in the catalog · A to add it", stat.Query, stat.Searches),
+			3*time.Second,
+		))
+	}
+	return tea.Batch(cmds...)
+}
+
+// shouldOfferPromotion reports whether a query that keeps being searched
+// as free text, and keeps finding listings, should become a catalog product.
+// Each query is offered once per session.
+func (m Model) shouldOfferPromotion(stat QueryStat) bool {
+	if stat.Searches < promoteAfterSearches || stat.LastCount == 0 || stat.unproductive() {
+		return false
+	}
+	if m.userCatalogPath == "" || m.promotionOffered[strings.ToLower(stat.Query)] {
+		return false
+	}
+	if m.lastSearchLiteral || m.lastExpansion.Changed() || m.lastExpansion.Rule != nil {
+		return false
+	}
+	if _, ok := api.ClassifyIdentifier(stat.Query); ok {
+		return false
+	}
+	_, inCatalog := m.productIndex.Product(stat.Query)
+	return !inCatalog
+}
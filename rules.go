@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// parseRulesPath returns the parsing rules file: $MRKTR_RULES when set,
+// otherwise rules.json in the mrktr config directory.
+func parseRulesPath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv("MRKTR_RULES")); path != "" {
+		return path, nil
+	}
+	return configFilePath("rules.json")
+}
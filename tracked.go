@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"mrktr/types"
+)
+
+const (
+	trackedMaxListings       = 50
+	trackedMaxEvents         = 40
+	trackedMaxIgnoredRelists = 20
+	// relistPriceTolerance is how far a relist's price may be from the sold
+	// price, as a fraction of it.
+	relistPriceTolerance = 0.10
+)
+
+// TrackEventKind names a change recorded on a tracked listing's timeline.
+type TrackEventKind string
+
+const (
+	TrackEventTracked   TrackEventKind = "tracked"
+	TrackEventPriceDrop TrackEventKind = "price_drop"
+	TrackEventPriceRise TrackEventKind = "price_rise"
+	TrackEventSold      TrackEventKind = "sold"
+	TrackEventRelisted  TrackEventKind = "relisted"
+)
+
+// Label returns the timeline text for the event kind.
+func (k TrackEventKind) Label() string {
+	switch k {
+	case TrackEventTracked:
+		return "tracked"
+	case TrackEventPriceDrop:
+		return "price drop"
+	case TrackEventPriceRise:
+		return "price rise"
+	case TrackEventSold:
+		return "sold"
+	case TrackEventRelisted:
+		return "relisted"
+	default:
+		return string(k)
+	}
+}
+
+// TrackEvent is one entry on a tracked listing's timeline.
+type TrackEvent struct {
+	Kind   TrackEventKind `json:"kind"`
+	Time   time.Time      `json:"time"`
+	Price  float64        `json:"price"`
+	Status string         `json:"status"`
+}
+
+// TrackedListing is a listing the user follows across searches.
+type TrackedListing struct {
+	Key      string        `json:"key"`
+	Query    string        `json:"query"` // search that found it; refreshes re-run it
+	Platform string        `json:"platform"`
+	Title    string        `json:"title"`
+	Variant  types.Variant `json:"variant"`
+	URL      string        `json:"url"`
+	Price    float64       `json:"price"`
+	Status   string        `json:"status"`
+	LastSeen time.Time     `json:"last_seen"`
+	Events   []TrackEvent  `json:"events"`
+
+	// PossibleRelist is an unsold listing that may be this one relisted,
+	// waiting for the user to follow or ignore it.
+	PossibleRelist *TrackedRelist `json:"possible_relist,omitempty"`
+	IgnoredRelists []string       `json:"ignored_relists,omitempty"` // keys the user ignored
+}
+
+// TrackedRelist is a listing suggested as a sold tracked listing put back on
+// sale under a new item ID.
+type TrackedRelist struct {
+	Key    string    `json:"key"`
+	URL    string    `json:"url"`
+	Price  float64   `json:"price"`
+	Status string    `json:"status"`
+	Seen   time.Time `json:"seen"`
+}
+
+func newTrackedListing(listing types.Listing, query string, now time.Time) TrackedListing {
+	return TrackedListing{
+		Key:      listing.Key(),
+		Query:    strings.TrimSpace(query),
+		Platform: listing.Platform,
+		Title:    listing.Title,
+		Variant:  listing.Variant,
+		URL:      listing.URL,
+		Price:    listing.Price,
+		Status:   listing.Status,
+		LastSeen: now,
+		Events: []TrackEvent{{
+			Kind:   TrackEventTracked,
+			Time:   now,
+			Price:  listing.Price,
+			Status: listing.Status,
+		}},
+	}
+}
+
+// Observe updates the tracked listing from a fresh sighting and reports the
+// timeline event it produced, if the price or status changed.
+func (t *TrackedListing) Observe(listing types.Listing, now time.Time) (TrackEvent, bool) {
+	event := TrackEvent{Time: now, Price: listing.Price, Status: listing.Status}
+	wasSold := strings.EqualFold(t.Status, "Sold")
+	isSold := strings.EqualFold(listing.Status, "Sold")
+	priceDelta := listing.Price - t.Price
+
+	if wasSold && !isSold {
+		// A sold item ID does not go back on sale: marketplaces relist under
+		// a new ID (see Relist), so this is a stale snippet.
+		t.LastSeen = now
+		return TrackEvent{}, false
+	}
+	switch {
+	case isSold && !wasSold:
+		event.Kind = TrackEventSold
+	case listing.Price > 0 && priceDelta <= -0.01:
+		event.Kind = TrackEventPriceDrop
+	case listing.Price > 0 && priceDelta >= 0.01:
+		event.Kind = TrackEventPriceRise
+	}
+
+	t.update(listing, now)
+	if event.Kind == "" {
+		return TrackEvent{}, false
+	}
+	t.appendEvent(event)
+	return event, true
+}
+
+// SuggestRelist records listing as a possible relist of the sold tracked
+// listing and reports whether it is new. Titles repeat across sellers, so
+// tracking only moves once the user follows it (see FollowRelist).
+func (t *TrackedListing) SuggestRelist(listing types.Listing, now time.Time) bool {
+	key := listing.Key()
+	if t.PossibleRelist != nil && t.PossibleRelist.Key == key {
+		t.PossibleRelist.Price = listing.Price
+		t.PossibleRelist.Seen = now
+		return false
+	}
+	t.PossibleRelist = &TrackedRelist{Key: key, URL: listing.URL, Price: listing.Price, Status: listing.Status, Seen: now}
+	return true
+}
+
+// FollowRelist moves tracking onto the possible relist and records the
+// relist on the timeline.
+func (t *TrackedListing) FollowRelist(now time.Time) (TrackEvent, bool) {
+	relist := t.PossibleRelist
+	if relist == nil {
+		return TrackEvent{}, false
+	}
+	event := TrackEvent{Kind: TrackEventRelisted, Time: now, Price: relist.Price, Status: relist.Status}
+	t.Key = relist.Key
+	t.update(types.Listing{Price: relist.Price, Status: relist.Status, URL: relist.URL}, now)
+	t.PossibleRelist = nil
+	t.appendEvent(event)
+	return event, true
+}
+
+// IgnoreRelist drops the possible relist and stops suggesting it.
+func (t *TrackedListing) IgnoreRelist() {
+	if t.PossibleRelist == nil {
+		return
+	}
+	t.IgnoredRelists = append(t.IgnoredRelists, t.PossibleRelist.Key)
+	if len(t.IgnoredRelists) > trackedMaxIgnoredRelists {
+		t.IgnoredRelists = t.IgnoredRelists[len(t.IgnoredRelists)-trackedMaxIgnoredRelists:]
+	}
+	t.PossibleRelist = nil
+}
+
+// soldAt returns when the listing was first seen sold, or the zero time.
+func (t TrackedListing) soldAt() time.Time {
+	for _, event := range t.Events {
+		if event.Kind == TrackEventSold {
+			return event.Time
+		}
+	}
+	return time.Time{}
+}
+
+func (t *TrackedListing) update(listing types.Listing, now time.Time) {
+	t.LastSeen = now
+	if listing.Price > 0 {
+		t.Price = listing.Price
+	}
+	if listing.Status != "" {
+		t.Status = listing.Status
+	}
+	if listing.URL != "" {
+		t.URL = listing.URL
+	}
+}
+
+func (t *TrackedListing) appendEvent(event TrackEvent) {
+	t.Events = append(t.Events, event)
+	if len(t.Events) > trackedMaxEvents {
+		// Keep the original "tracked" entry so the timeline start is preserved.
+		t.Events = append([]TrackEvent{t.Events[0]}, t.Events[len(t.Events)-trackedMaxEvents+1:]...)
+	}
+}
+
+// TrackedStore persists tracked listings between runs.
+type TrackedStore = listStore[TrackedListing]
+
+type FileTrackedStore = jsonFileStore[TrackedListing]
+
+func NewFileTrackedStore() (*FileTrackedStore, error) {
+	return newJSONFileStore("tracked.json", "tracked listings", normalizeTrackedListings)
+}
+
+func NewFileTrackedStoreAt(path string) *FileTrackedStore {
+	return newJSONFileStoreAt(path, "tracked listings", normalizeTrackedListings)
+}
+
+func normalizeTrackedListings(listings []TrackedListing) []TrackedListing {
+	if len(listings) == 0 {
+		return []TrackedListing{}
+	}
+
+	out := make([]TrackedListing, 0, min(len(listings), trackedMaxListings))
+	seen := make(map[string]struct{}, len(listings))
+
+	for _, listing := range listings {
+		key := strings.TrimSpace(listing.Key)
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		listing.Key = key
+		listing.Query = strings.TrimSpace(listing.Query)
+		out = append(out, listing)
+		if len(out) == trackedMaxListings {
+			break
+		}
+	}
+
+	return out
+}
+
+// trackedIndexOf returns the position of the tracked listing with key, or -1.
+func trackedIndexOf(tracked []TrackedListing, key string) int {
+	if key == "" {
+		return -1
+	}
+	for i, t := range tracked {
+		if t.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// relistIndexOf returns the position of the sold tracked listing that
+// listing may relist, or -1. Listings carry no seller, so a possible relist
+// is an unsold listing on the same platform with the same title and variant
+// as a sold one, a different item ID, a price close to the sold price and,
+// when dated, listed after the sale.
+func relistIndexOf(tracked []TrackedListing, listing types.Listing) int {
+	key := listing.Key()
+	if key == "" || strings.EqualFold(listing.Status, "Sold") || listing.Status == "" || listing.Price <= 0 {
+		return -1
+	}
+	title := normalizeTrackedTitle(listing.Title)
+	if title == "" {
+		return -1
+	}
+	for i, t := range tracked {
+		if t.Key == key || !strings.EqualFold(t.Status, "Sold") || t.Platform != listing.Platform {
+			continue
+		}
+		if normalizeTrackedTitle(t.Title) != title {
+			continue
+		}
+		if t.Variant != (types.Variant{}) && t.Variant != listing.Variant {
+			continue
+		}
+		if t.Price <= 0 || math.Abs(listing.Price-t.Price) > relistPriceTolerance*t.Price {
+			continue
+		}
+		if sold := t.soldAt(); !listing.Date.IsZero() && !sold.IsZero() && listing.Date.Before(sold) {
+			continue
+		}
+		if slices.Contains(t.IgnoredRelists, key) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func normalizeTrackedTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// trackedQueries returns the distinct searches that refresh tracked listings.
+func trackedQueries(tracked []TrackedListing) []string {
+	out := make([]string, 0, len(tracked))
+	seen := make(map[string]struct{}, len(tracked))
+	for _, t := range tracked {
+		query := strings.TrimSpace(t.Query)
+		if query == "" {
+			continue
+		}
+		key := strings.ToLower(query)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, query)
+	}
+	return out
+}
+
+// formatTrackEvent renders one timeline entry relative to the previous price.
+func formatTrackEvent(event TrackEvent, prevPrice float64) string {
+	text := fmt.Sprintf("%s  %-10s $%.2f", event.Time.Local().Format("2006-01-02"), event.Kind.Label(), event.Price)
+	switch event.Kind {
+	case TrackEventPriceDrop, TrackEventPriceRise:
+		if prevPrice > 0 {
+			text += fmt.Sprintf(" (%+.2f)", event.Price-prevPrice)
+		}
+	}
+	return text
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mrktr/types"
+)
+
+func TestFileTrackedStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracked.json")
+	store := NewFileTrackedStoreAt(path)
+
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	listing := types.Listing{Platform: "eBay", Price: 120, Status: "Active", ID: "ebay:123", Title: "Switch OLED"}
+	in := []TrackedListing{newTrackedListing(listing, "switch oled", now)}
+	if err := store.Save(in); err != nil {
+		t.Fatalf("save tracked listings: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("load tracked listings: %v", err)
+	}
+	if len(got) != 1 || got[0].Key != "ebay:123" || got[0].Query != "switch oled" {
+		t.Fatalf("unexpected tracked listings: %+v", got)
+	}
+	if len(got[0].Events) != 1 || got[0].Events[0].Kind != TrackEventTracked {
+		t.Fatalf("expected a single tracked event, got %+v", got[0].Events)
+	}
+}
+
+func TestNormalizeTrackedListingsDropsEmptyAndDuplicateKeys(t *testing.T) {
+	got := normalizeTrackedListings([]TrackedListing{
+		{Key: "ebay:1"},
+		{Key: " "},
+		{Key: "ebay:1"},
+		{Key: "mercari:m2"},
+	})
+	if len(got) != 2 || got[0].Key != "ebay:1" || got[1].Key != "mercari:m2" {
+		t.Fatalf("unexpected normalized tracked listings: %+v", got)
+	}
+}
+
+func TestTrackedListingObserveRecordsTimeline(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	listing := types.Listing{Price: 120, Status: "Active", ID: "ebay:123"}
+	tracked := newTrackedListing(listing, "switch", start)
+
+	steps := []struct {
+		price  float64
+		status string
+		want   TrackEventKind
+	}{
+		{price: 120, status: "Active"},
+		{price: 110, status: "Active", want: TrackEventPriceDrop},
+		{price: 115, status: "Active", want: TrackEventPriceRise},
+		{price: 115, status: "Sold", want: TrackEventSold},
+		// A sold item ID flipping back to active is a stale snippet.
+		{price: 105, status: "Active"},
+	}
+	for i, step := range steps {
+		listing.Price = step.price
+		listing.Status = step.status
+		event, ok := tracked.Observe(listing, start.AddDate(0, 0, i+1))
+		if step.want == "" {
+			if ok {
+				t.Fatalf("step %d: expected no event, got %+v", i, event)
+			}
+			continue
+		}
+		if !ok || event.Kind != step.want {
+			t.Fatalf("step %d: expected %q event, got %+v (ok=%v)", i, step.want, event, ok)
+		}
+	}
+
+	if len(tracked.Events) != 4 {
+		t.Fatalf("expected 4 timeline events, got %d", len(tracked.Events))
+	}
+	if tracked.Price != 115 || tracked.Status != "Sold" {
+		t.Fatalf("expected the sold price and status, got $%.2f %s", tracked.Price, tracked.Status)
+	}
+}
+
+func TestTrackedListingSuggestsRelistUntilFollowed(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	soldAt := start.AddDate(0, 0, 2)
+	variant := types.Variant{Storage: "64GB"}
+	listing := types.Listing{Platform: "eBay", Price: 300, Status: "Active", ID: "ebay:1", Title: "Switch OLED  64GB White", Variant: variant}
+	tracked := []TrackedListing{newTrackedListing(listing, "switch oled", start)}
+	listing.Status = "Sold"
+	tracked[0].Observe(listing, soldAt)
+
+	for _, listing := range []types.Listing{
+		{Platform: "Mercari", Price: 280, Status: "Active", ID: "mercari:m2", Title: "Switch OLED 64GB White", Variant: variant},
+		{Platform: "eBay", Price: 280, Status: "Active", ID: "ebay:3", Title: "Switch OLED 64GB White", Variant: types.Variant{Storage: "128GB"}},
+		{Platform: "eBay", Price: 280, Status: "Active", ID: "ebay:4", Title: "Switch OLED 64GB Red", Variant: variant},
+		{Platform: "eBay", Price: 280, Status: "Sold", ID: "ebay:5", Title: "Switch OLED 64GB White", Variant: variant},
+		{Platform: "eBay", Price: 199, Status: "Active", ID: "ebay:6", Title: "Switch OLED 64GB White", Variant: variant},
+		{Platform: "eBay", Price: 290, Status: "Active", ID: "ebay:7", Title: "Switch OLED 64GB White", Variant: variant, Date: start},
+	} {
+		if i := relistIndexOf(tracked, listing); i >= 0 {
+			t.Fatalf("expected %+v not to be a relist", listing)
+		}
+	}
+
+	relist := types.Listing{Platform: "eBay", Price: 280, Status: "Active", ID: "ebay:9", Title: "switch oled 64gb white", Variant: variant, Date: soldAt.AddDate(0, 0, 1)}
+	i := relistIndexOf(tracked, relist)
+	if i != 0 {
+		t.Fatalf("expected the relist to match the sold listing, got %d", i)
+	}
+	if !tracked[i].SuggestRelist(relist, soldAt.AddDate(0, 0, 1)) || tracked[i].SuggestRelist(relist, soldAt.AddDate(0, 0, 2)) {
+		t.Fatalf("expected one new relist suggestion, got %+v", tracked[i].PossibleRelist)
+	}
+	if tracked[i].Key != "ebay:1" || tracked[i].Status != "Sold" {
+		t.Fatalf("expected tracking to stay on the sold listing until followed, got %+v", tracked[i])
+	}
+
+	event, ok := tracked[i].FollowRelist(soldAt.AddDate(0, 0, 3))
+	if !ok || event.Kind != TrackEventRelisted || tracked[i].Key != "ebay:9" || tracked[i].Status != "Active" || tracked[i].Price != 280 || tracked[i].PossibleRelist != nil {
+		t.Fatalf("expected the tracked listing to follow the relist, got %+v", tracked[i])
+	}
+}
+
+func TestTrackedListingIgnoredRelistIsNotSuggestedAgain(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	sold := types.Listing{Platform: "eBay", Price: 300, Status: "Sold", ID: "ebay:1", Title: "Nintendo Switch OLED Console"}
+	tracked := []TrackedListing{newTrackedListing(sold, "switch oled", start)}
+	stranger := types.Listing{Platform: "eBay", Price: 295, Status: "Active", ID: "ebay:2", Title: "Nintendo Switch OLED Console"}
+
+	tracked[0].SuggestRelist(stranger, start.Add(time.Hour))
+	tracked[0].IgnoreRelist()
+	if tracked[0].PossibleRelist != nil || tracked[0].Key != "ebay:1" {
+		t.Fatalf("expected the ignored relist to be dropped, got %+v", tracked[0])
+	}
+	if i := relistIndexOf(tracked, stranger); i >= 0 {
+		t.Fatalf("expected the ignored listing not to be suggested again")
+	}
+}
+
+func TestTrackedListingObserveKeepsFirstEventWhenTrimming(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	listing := types.Listing{Price: 500, Status: "Active", ID: "ebay:123"}
+	tracked := newTrackedListing(listing, "switch", start)
+
+	for i := 0; i < trackedMaxEvents+5; i++ {
+		listing.Price--
+		tracked.Observe(listing, start.Add(time.Duration(i+1)*time.Hour))
+	}
+	if len(tracked.Events) != trackedMaxEvents {
+		t.Fatalf("expected %d events, got %d", trackedMaxEvents, len(tracked.Events))
+	}
+	if tracked.Events[0].Kind != TrackEventTracked {
+		t.Fatalf("expected timeline to keep its start, got %+v", tracked.Events[0])
+	}
+	if last := tracked.Events[len(tracked.Events)-1]; last.Price != listing.Price {
+		t.Fatalf("expected newest event last, got %+v", last)
+	}
+}
+
+func TestTrackedQueriesDedupes(t *testing.T) {
+	got := trackedQueries([]TrackedListing{
+		{Key: "a", Query: "switch"},
+		{Key: "b", Query: "Switch"},
+		{Key: "c", Query: ""},
+		{Key: "d", Query: "ps5"},
+	})
+	if len(got) != 2 || got[0] != "switch" || got[1] != "ps5" {
+		t.Fatalf("unexpected tracked queries: %v", got)
+	}
+}
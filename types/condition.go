@@ -0,0 +1,78 @@
+package types
+
+import "strings"
+
+// Canonical listing conditions, best first.
+const (
+	ConditionSealed               = "Sealed"
+	ConditionNew                  = "New"
+	ConditionOpenBox              = "Open Box"
+	ConditionLikeNew              = "Like New"
+	ConditionCertifiedRefurbished = "Certified Refurbished"
+	ConditionRefurbished          = "Refurbished" // seller or unspecified refurbishment
+	ConditionUsed                 = "Used"
+	ConditionGood                 = "Good"
+	ConditionFair                 = "Fair"
+	ConditionForParts             = "For Parts"
+)
+
+// Conditions lists the canonical conditions in sort order.
+var Conditions = []string{
+	ConditionSealed,
+	ConditionNew,
+	ConditionOpenBox,
+	ConditionLikeNew,
+	ConditionCertifiedRefurbished,
+	ConditionRefurbished,
+	ConditionUsed,
+	ConditionGood,
+	ConditionFair,
+	ConditionForParts,
+}
+
+// conditionFamilies groups conditions under the broad filters "New",
+// "Refurbished" and "Used". Filtering by a family matches all its members.
+var conditionFamilies = map[string][]string{
+	"new":         {ConditionSealed, ConditionNew, ConditionOpenBox},
+	"refurbished": {ConditionCertifiedRefurbished, ConditionRefurbished},
+	"used":        {ConditionLikeNew, ConditionUsed, ConditionGood, ConditionFair},
+}
+
+// CanonicalCondition maps a condition label to its canonical spelling.
+// Unknown labels are returned trimmed.
+func CanonicalCondition(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	for _, condition := range Conditions {
+		if strings.EqualFold(trimmed, condition) {
+			return condition
+		}
+	}
+	return trimmed
+}
+
+// IsForParts reports whether a condition marks a non-working item.
+func IsForParts(condition string) bool {
+	return strings.EqualFold(strings.TrimSpace(condition), ConditionForParts)
+}
+
+func conditionSortRank(condition string) int {
+	canonical := CanonicalCondition(condition)
+	for i, known := range Conditions {
+		if canonical == known {
+			return i
+		}
+	}
+	return len(Conditions)
+}
+
+func conditionMatches(condition, target string) bool {
+	if members, ok := conditionFamilies[strings.ToLower(strings.TrimSpace(target))]; ok {
+		for _, member := range members {
+			if strings.EqualFold(strings.TrimSpace(condition), member) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(condition), strings.TrimSpace(target))
+}
@@ -0,0 +1,40 @@
+package types
+
+import "testing"
+
+func TestConditionMatchesFamilies(t *testing.T) {
+	tests := []struct {
+		condition string
+		target    string
+		want      bool
+	}{
+		{condition: "Sealed", target: "New", want: true},
+		{condition: "Open Box", target: "new", want: true},
+		{condition: "Like New", target: "Used", want: true},
+		{condition: "Fair", target: "Used", want: true},
+		{condition: "Certified Refurbished", target: "Refurbished", want: true},
+		{condition: "For Parts", target: "Used", want: false},
+		{condition: "Refurbished", target: "New", want: false},
+		{condition: "Like New", target: "Like New", want: true},
+		{condition: "Used", target: "Good", want: false},
+	}
+	for _, tc := range tests {
+		if got := conditionMatches(tc.condition, tc.target); got != tc.want {
+			t.Fatalf("conditionMatches(%q, %q): expected %v, got %v", tc.condition, tc.target, tc.want, got)
+		}
+	}
+}
+
+func TestConditionSortRankFollowsTaxonomy(t *testing.T) {
+	for i := 1; i < len(Conditions); i++ {
+		if conditionSortRank(Conditions[i-1]) >= conditionSortRank(Conditions[i]) {
+			t.Fatalf("expected %q to rank before %q", Conditions[i-1], Conditions[i])
+		}
+	}
+	if conditionSortRank("open box") != conditionSortRank(ConditionOpenBox) {
+		t.Fatal("expected rank lookup to ignore case")
+	}
+	if conditionSortRank("Mystery") != len(Conditions) {
+		t.Fatal("expected unknown conditions to sort last")
+	}
+}
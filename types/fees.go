@@ -8,6 +8,9 @@ type PlatformFee struct {
 	Flat    float64
 }
 
+// DefaultFeeRegion is the market whose schedule backs FeeSchedule and FeeForPlatform.
+const DefaultFeeRegion = "US"
+
 var platformFees = map[string]PlatformFee{
 	"ebay":     {Percent: 13.25, Flat: 0.30},
 	"mercari":  {Percent: 10.0, Flat: 0.00},
@@ -15,10 +18,103 @@ var platformFees = map[string]PlatformFee{
 	"facebook": {Percent: 5.0, Flat: 0.00},
 }
 
+// regionalFees holds private-seller schedules per market, in local currency.
+// Platforms absent from a market are not sold on there.
+var regionalFees = map[string]map[string]PlatformFee{
+	"US": platformFees,
+	"UK": {
+		"ebay":     {Percent: 12.8, Flat: 0.30},
+		"amazon":   {Percent: 15.3, Flat: 0.00},
+		"facebook": {Percent: 5.0, Flat: 0.00},
+	},
+	"CA": {
+		"ebay":     {Percent: 13.6, Flat: 0.40},
+		"amazon":   {Percent: 15.0, Flat: 0.00},
+		"facebook": {Percent: 5.0, Flat: 0.00},
+	},
+	"DE": {
+		"ebay":   {Percent: 0.0, Flat: 0.00}, // private sellers pay no final value fee
+		"amazon": {Percent: 15.0, Flat: 0.00},
+	},
+	"AU": {
+		"ebay":     {Percent: 13.4, Flat: 0.30},
+		"amazon":   {Percent: 15.0, Flat: 0.00},
+		"facebook": {Percent: 5.0, Flat: 0.00},
+	},
+	"JP": {
+		"mercari": {Percent: 10.0, Flat: 0.00},
+		"amazon":  {Percent: 15.0, Flat: 0.00},
+	},
+}
+
+// FeeClass names a product category whose selling fees differ from a
+// platform's standard rate. The zero value is the standard rate.
+type FeeClass string
+
+const (
+	FeeClassStandard    FeeClass = ""
+	FeeClassElectronics FeeClass = "electronics"
+	FeeClassSneakers    FeeClass = "sneakers"
+	FeeClassMedia       FeeClass = "media"
+	FeeClassInstruments FeeClass = "instruments"
+	FeeClassJewelry     FeeClass = "jewelry"
+)
+
+// FeeClasses lists the known non-standard fee classes.
+var FeeClasses = []FeeClass{FeeClassElectronics, FeeClassSneakers, FeeClassMedia, FeeClassInstruments, FeeClassJewelry}
+
+// classFees overrides a market's platform fees for a fee class. Rates are
+// approximate private-seller (eBay) and referral (Amazon) rates; platforms
+// and markets without an entry use the standard schedule.
+var classFees = map[string]map[FeeClass]map[string]PlatformFee{
+	"US": {
+		FeeClassElectronics: {
+			"amazon": {Percent: 8.0, Flat: 0.00},
+		},
+		FeeClassSneakers: {
+			"ebay":   {Percent: 8.0, Flat: 0.00},
+			"amazon": {Percent: 15.0, Flat: 0.00},
+		},
+		FeeClassMedia: {
+			"ebay":   {Percent: 14.95, Flat: 0.30},
+			"amazon": {Percent: 15.0, Flat: 1.80},
+		},
+		FeeClassInstruments: {
+			"ebay": {Percent: 6.35, Flat: 0.30},
+		},
+		FeeClassJewelry: {
+			"ebay":   {Percent: 15.0, Flat: 0.30},
+			"amazon": {Percent: 20.0, Flat: 0.00},
+		},
+	},
+}
+
+// ParseFeeClass returns the fee class named by s, case-insensitively. An
+// empty name is the standard class.
+func ParseFeeClass(s string) (FeeClass, bool) {
+	name := FeeClass(strings.ToLower(strings.TrimSpace(s)))
+	if name == FeeClassStandard {
+		return FeeClassStandard, true
+	}
+	for _, class := range FeeClasses {
+		if class == name {
+			return class, true
+		}
+	}
+	return FeeClassStandard, false
+}
+
 // FeeSchedule returns a copy of the default platform fee schedule.
 func FeeSchedule() map[string]PlatformFee {
-	out := make(map[string]PlatformFee, len(platformFees))
-	for k, v := range platformFees {
+	return FeeScheduleFor(DefaultFeeRegion)
+}
+
+// FeeScheduleFor returns a copy of a market's fee schedule. Unknown markets
+// use the default schedule.
+func FeeScheduleFor(region string) map[string]PlatformFee {
+	schedule := regionSchedule(region)
+	out := make(map[string]PlatformFee, len(schedule))
+	for k, v := range schedule {
 		out[k] = v
 	}
 	return out
@@ -26,16 +122,52 @@ func FeeSchedule() map[string]PlatformFee {
 
 // FeeForPlatform returns fee settings for a platform.
 func FeeForPlatform(platform string) PlatformFee {
+	return FeeForPlatformIn(DefaultFeeRegion, platform)
+}
+
+// FeeForPlatformIn returns fee settings for a platform in a market.
+func FeeForPlatformIn(region, platform string) PlatformFee {
 	key := strings.ToLower(strings.TrimSpace(platform))
-	if fee, ok := platformFees[key]; ok {
+	if fee, ok := regionSchedule(region)[key]; ok {
 		return fee
 	}
 	return PlatformFee{}
 }
 
+// FeeForPlatformClassIn returns fee settings for a platform in a market for
+// products of a fee class, falling back to the platform's standard rate.
+func FeeForPlatformClassIn(region string, class FeeClass, platform string) PlatformFee {
+	key := strings.ToLower(strings.TrimSpace(platform))
+	if _, sold := regionSchedule(region)[key]; !sold {
+		return PlatformFee{}
+	}
+	if fee, ok := classFees[strings.ToUpper(strings.TrimSpace(region))][class][key]; ok {
+		return fee
+	}
+	return FeeForPlatformIn(region, platform)
+}
+
+func regionSchedule(region string) map[string]PlatformFee {
+	if schedule, ok := regionalFees[strings.ToUpper(strings.TrimSpace(region))]; ok {
+		return schedule
+	}
+	return platformFees
+}
+
 // CalculateNetProfit computes net profit after platform fees.
 func CalculateNetProfit(cost, sell float64, platform string) (net float64, fee float64, pct float64) {
-	rule := FeeForPlatform(platform)
+	return CalculateNetProfitIn(DefaultFeeRegion, cost, sell, platform)
+}
+
+// CalculateNetProfitIn computes net profit after a market's platform fees.
+func CalculateNetProfitIn(region string, cost, sell float64, platform string) (net float64, fee float64, pct float64) {
+	return CalculateNetProfitFor(region, FeeClassStandard, cost, sell, platform)
+}
+
+// CalculateNetProfitFor computes net profit after a market's platform fees
+// for products of a fee class.
+func CalculateNetProfitFor(region string, class FeeClass, cost, sell float64, platform string) (net float64, fee float64, pct float64) {
+	rule := FeeForPlatformClassIn(region, class, platform)
 	fee = (sell * (rule.Percent / 100.0)) + rule.Flat
 	if fee < 0 {
 		fee = 0
@@ -1,6 +1,9 @@
 package types
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestCalculateNetProfit(t *testing.T) {
 	tests := []struct {
@@ -57,3 +60,48 @@ func TestFeeScheduleReturnsCopy(t *testing.T) {
 		t.Fatal("expected fee schedule copy mutation not to affect defaults")
 	}
 }
+
+func TestCalculateNetProfitInUsesRegionalSchedule(t *testing.T) {
+	_, ukFee, _ := CalculateNetProfitIn("uk", 50, 100, "eBay")
+	if math.Abs(ukFee-13.10) > 1e-9 {
+		t.Fatalf("expected UK eBay fee 13.10, got %.2f", ukFee)
+	}
+
+	_, jpFee, _ := CalculateNetProfitIn("JP", 5000, 10000, "eBay")
+	if jpFee != 0 {
+		t.Fatalf("expected no fee for a platform absent from the market, got %.2f", jpFee)
+	}
+
+	_, fallbackFee, _ := CalculateNetProfitIn("XX", 50, 100, "Mercari")
+	if fallbackFee != 10 {
+		t.Fatalf("expected unknown market to use default schedule, got %.2f", fallbackFee)
+	}
+
+	if _, ok := FeeScheduleFor("DE")["mercari"]; ok {
+		t.Fatal("expected DE schedule to omit Mercari")
+	}
+}
+
+func TestCalculateNetProfitForUsesFeeClass(t *testing.T) {
+	_, sneakerFee, _ := CalculateNetProfitFor("US", FeeClassSneakers, 100, 200, "eBay")
+	if math.Abs(sneakerFee-16) > 1e-9 {
+		t.Fatalf("expected sneaker eBay fee 16.00, got %.2f", sneakerFee)
+	}
+
+	_, mercariFee, _ := CalculateNetProfitFor("US", FeeClassSneakers, 100, 200, "Mercari")
+	if mercariFee != 20 {
+		t.Fatalf("expected platforms without a class rate to use the standard fee, got %.2f", mercariFee)
+	}
+
+	_, ukFee, _ := CalculateNetProfitFor("UK", FeeClassSneakers, 50, 100, "eBay")
+	if math.Abs(ukFee-13.10) > 1e-9 {
+		t.Fatalf("expected markets without class rates to use their schedule, got %.2f", ukFee)
+	}
+
+	if _, ok := ParseFeeClass(" Sneakers "); !ok {
+		t.Fatal("expected fee class names to be case-insensitive")
+	}
+	if _, ok := ParseFeeClass("furniture"); ok {
+		t.Fatal("expected unknown fee class to be rejected")
+	}
+}
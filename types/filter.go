@@ -1,12 +1,27 @@
 package types
 
-import "strings"
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 // ResultFilter constrains visible listings in the results panel.
 type ResultFilter struct {
 	Platform  string
 	Condition string
 	Status    string
+	MinPrice  float64   // zero means no lower bound
+	MaxPrice  float64   // zero means no upper bound
+	Exclude   []string  // lower-case terms that drop a listing when in its title
+	Require   []string  // lower-case terms a listing's title must all contain
+	Since     time.Time // drops listings dated before this, keeping undated ones; zero disables
+
+	// MaxDistance drops listings farther than this many miles from Home.
+	// Zero disables; listings without a known location are kept.
+	MaxDistance float64
+	Home        Location
 }
 
 // ApplyFilter returns only listings matching the configured filter values.
@@ -30,6 +45,23 @@ func ApplyFilter(in []Listing, f ResultFilter) []Listing {
 		if status != "" && !strings.EqualFold(listing.Status, status) {
 			continue
 		}
+		if f.MinPrice > 0 && listing.Price < f.MinPrice {
+			continue
+		}
+		if f.MaxPrice > 0 && listing.Price > f.MaxPrice {
+			continue
+		}
+		if titleContainsAny(listing.Title, f.Exclude) || !titleContainsAll(listing.Title, f.Require) {
+			continue
+		}
+		if !f.Since.IsZero() && !listing.Date.IsZero() && listing.Date.Before(f.Since) {
+			continue
+		}
+		if f.MaxDistance > 0 {
+			if miles, ok := listing.DistanceFrom(f.Home); ok && miles > f.MaxDistance {
+				continue
+			}
+		}
 		out = append(out, listing)
 	}
 	return out
@@ -43,14 +75,54 @@ func normalizeFilterToken(raw string) string {
 	return trimmed
 }
 
-func conditionMatches(condition, target string) bool {
-	if strings.EqualFold(target, "Used") {
-		switch strings.ToLower(strings.TrimSpace(condition)) {
-		case "used", "good", "fair":
+func titleContainsAny(title string, terms []string) bool {
+	if len(terms) == 0 {
+		return false
+	}
+	lower := strings.ToLower(title)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && containsWord(lower, term) {
 			return true
-		default:
+		}
+	}
+	return false
+}
+
+func titleContainsAll(title string, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	lower := strings.ToLower(title)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && !containsWord(lower, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsWord reports whether term occurs in text as whole words, so "pro"
+// matches "Pro Max" but not "product".
+func containsWord(text, term string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
 			return false
 		}
+		start, end := offset+i, offset+i+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
 	}
-	return strings.EqualFold(condition, target)
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
 }
@@ -1,6 +1,9 @@
 package types
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestApplyFilter(t *testing.T) {
 	in := []Listing{
@@ -70,3 +73,78 @@ func TestApplyFilterNoMatch(t *testing.T) {
 		t.Fatalf("expected zero results, got %d", len(got))
 	}
 }
+
+func TestApplyFilterPriceBoundsAndExclusions(t *testing.T) {
+	in := []Listing{
+		{Title: "Switch OLED", Price: 250},
+		{Title: "Switch OLED broken screen", Price: 120},
+		{Title: "Switch Lite", Price: 90},
+		{Title: "Switch bundle", Price: 400},
+	}
+
+	got := ApplyFilter(in, ResultFilter{MinPrice: 100, MaxPrice: 300})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 listings within price bounds, got %d", len(got))
+	}
+
+	got = ApplyFilter(in, ResultFilter{MinPrice: 100, Exclude: []string{"Broken"}})
+	if len(got) != 2 || got[0].Title != "Switch OLED" || got[1].Title != "Switch bundle" {
+		t.Fatalf("expected broken listing excluded, got %+v", got)
+	}
+
+	got = ApplyFilter(in, ResultFilter{Require: []string{"switch", "oled"}})
+	if len(got) != 2 || got[0].Title != "Switch OLED" || got[1].Title != "Switch OLED broken screen" {
+		t.Fatalf("expected only listings with every required term, got %+v", got)
+	}
+}
+
+func TestApplyFilterExclusionsMatchWholeWords(t *testing.T) {
+	in := []Listing{
+		{Title: "AirPods Pro 2nd Gen"},
+		{Title: "AirPods product box only"},
+		{Title: "Professional studio headphones"},
+		{Title: "Refurbished AirPods"},
+		{Title: "iPhone 15 Pro-Max red"},
+	}
+
+	got := ApplyFilter(in, ResultFilter{Exclude: []string{"pro"}})
+	if len(got) != 3 || got[0].Title != "AirPods product box only" || got[1].Title != "Professional studio headphones" || got[2].Title != "Refurbished AirPods" {
+		t.Fatalf("expected -pro to drop only whole-word matches, got %+v", got)
+	}
+	got = ApplyFilter(in, ResultFilter{Exclude: []string{"red"}})
+	if len(got) != 4 || got[3].Title != "Refurbished AirPods" {
+		t.Fatalf("expected -red to keep refurbished listings, got %+v", got)
+	}
+	got = ApplyFilter(in, ResultFilter{Exclude: []string{"box only"}})
+	if len(got) != 4 {
+		t.Fatalf("expected a phrase exclusion to drop one listing, got %+v", got)
+	}
+}
+
+func TestApplyFilterSinceDropsOldAndKeepsUndatedListings(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	in := []Listing{
+		{Title: "recent", Date: now.AddDate(0, 0, -2)},
+		{Title: "old", Date: now.AddDate(0, -3, 0)},
+		{Title: "undated"},
+	}
+
+	got := ApplyFilter(in, ResultFilter{Since: now.AddDate(0, 0, -7)})
+	if len(got) != 2 || got[0].Title != "recent" || got[1].Title != "undated" {
+		t.Fatalf("expected the recent and undated listings, got %+v", got)
+	}
+}
+
+func TestApplyFilterMaxDistanceKeepsUnknownLocations(t *testing.T) {
+	home := Location{City: "Dallas", State: "TX", Lat: 32.7767, Lon: -96.7970}
+	in := []Listing{
+		{Title: "fort worth", Location: Location{City: "Fort Worth", State: "TX", Lat: 32.7555, Lon: -97.3308}},
+		{Title: "houston", Location: Location{City: "Houston", State: "TX", Lat: 29.7604, Lon: -95.3698}},
+		{Title: "shipped"},
+	}
+
+	got := ApplyFilter(in, ResultFilter{Home: home, MaxDistance: 50})
+	if len(got) != 2 || got[0].Title != "fort worth" || got[1].Title != "shipped" {
+		t.Fatalf("expected nearby and unknown-location listings, got %+v", got)
+	}
+}
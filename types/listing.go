@@ -3,16 +3,67 @@ package types
 import (
 	"math"
 	"sort"
+	"strings"
+	"time"
 )
 
 // Listing represents a single price listing from a marketplace
 type Listing struct {
-	Platform  string  // "eBay", "Mercari", "Amazon", "Facebook"
-	Price     float64 // Price in USD
-	Condition string  // "New", "Used", "Good", "Fair"
-	Status    string  // "Sold", "Active"
-	URL       string  // Link to the listing
-	Title     string  // Item title/description
+	Platform  string    // "eBay", "Mercari", "Amazon", "Facebook"
+	Price     float64   // Price in the search market's currency
+	Condition string    // one of Conditions, e.g. "New", "Open Box", "For Parts"
+	Status    string    // "Sold", "Active"
+	URL       string    // Link to the listing, canonicalized when parsed
+	ID        string    // stable marketplace identity such as "ebay:1234567890"; "" if unknown
+	Title     string    // Item title/description
+	Date      time.Time // When the item sold or was listed; zero if unknown
+
+	// ConditionConfidence is how sure the parser is of Condition, from 0 to 1.
+	ConditionConfidence float64
+
+	Variant Variant // attributes extracted from the title and snippets
+
+	Quantity int  // units sold together in a lot; zero or one means a single unit
+	Bundle   bool // sold with extras such as controllers or games
+
+	Location Location // pickup location from snippets or the URL; zero if unknown
+
+	// PriceCandidates lists every amount the parser considered for Price.
+	PriceCandidates []PriceCandidate
+
+	// Source is the provider payload the listing was parsed from, with page
+	// text trimmed to what the parser reads. Nil for listings built in code.
+	Source *SearchResult `json:"-"`
+}
+
+// PriceCandidate is one amount found while parsing a listing's price.
+type PriceCandidate struct {
+	Amount  float64
+	Source  string // "offer", "snippet" or "page"
+	Pattern string // name of the rule that matched, e.g. "symbol-prefix"
+	Context string // text surrounding the match
+}
+
+// Key identifies the listing across searches: its marketplace ID when known,
+// otherwise its trimmed URL.
+func (l Listing) Key() string {
+	if l.ID != "" {
+		return l.ID
+	}
+	return strings.TrimSpace(l.URL)
+}
+
+// Units returns how many units the listing covers, at least one.
+func (l Listing) Units() int {
+	if l.Quantity < 1 {
+		return 1
+	}
+	return l.Quantity
+}
+
+// UnitPrice returns the price of one unit in a lot.
+func (l Listing) UnitPrice() float64 {
+	return l.Price / float64(l.Units())
 }
 
 // Statistics holds calculated price statistics
@@ -88,3 +88,14 @@ func TestCalculateProfit(t *testing.T) {
 		})
 	}
 }
+
+func TestListingUnitPrice(t *testing.T) {
+	lot := Listing{Price: 60, Quantity: 5}
+	if lot.Units() != 5 || lot.UnitPrice() != 12 {
+		t.Fatalf("expected 5 units at 12, got %d at %.2f", lot.Units(), lot.UnitPrice())
+	}
+	single := Listing{Price: 60}
+	if single.Units() != 1 || single.UnitPrice() != 60 {
+		t.Fatalf("expected zero quantity to mean one unit, got %d at %.2f", single.Units(), single.UnitPrice())
+	}
+}
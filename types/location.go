@@ -0,0 +1,64 @@
+package types
+
+import (
+	"math"
+	"strings"
+)
+
+const earthRadiusMiles = 3958.8
+
+// Location is where a listing is offered for local pickup, or the user's
+// home. Coordinates are approximate centroids; both zero means unknown.
+type Location struct {
+	City  string
+	State string // two-letter US state code
+	ZIP   string
+	Lat   float64
+	Lon   float64
+}
+
+// Known reports whether the location has coordinates to measure from.
+func (l Location) Known() bool {
+	return l.Lat != 0 || l.Lon != 0
+}
+
+// IsZero reports whether nothing about the location was found.
+func (l Location) IsZero() bool {
+	return l == Location{}
+}
+
+// String renders "Austin, TX 78701", omitting the parts that are unknown.
+func (l Location) String() string {
+	text := l.City
+	if l.State != "" {
+		if text != "" {
+			text += ", "
+		}
+		text += l.State
+	}
+	if l.ZIP != "" {
+		text = strings.TrimSpace(text + " " + l.ZIP)
+	}
+	return text
+}
+
+// DistanceMiles returns the great-circle distance between two locations, or
+// false when either has no coordinates.
+func DistanceMiles(a, b Location) (float64, bool) {
+	if !a.Known() || !b.Known() {
+		return 0, false
+	}
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h))), true
+}
+
+// DistanceFrom returns the listing's distance from home in miles, or false
+// when either location is unknown.
+func (l Listing) DistanceFrom(home Location) (float64, bool) {
+	return DistanceMiles(home, l.Location)
+}
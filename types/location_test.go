@@ -0,0 +1,36 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocationString(t *testing.T) {
+	tests := []struct {
+		in   Location
+		want string
+	}{
+		{Location{City: "Austin", State: "TX", ZIP: "78701"}, "Austin, TX 78701"},
+		{Location{State: "TX"}, "TX"},
+		{Location{ZIP: "78701"}, "78701"},
+		{Location{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Fatalf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestDistanceMiles(t *testing.T) {
+	newYork := Location{Lat: 40.7128, Lon: -74.0060}
+	losAngeles := Location{Lat: 34.0522, Lon: -118.2437}
+
+	miles, ok := DistanceMiles(newYork, losAngeles)
+	if !ok || math.Abs(miles-2445) > 15 {
+		t.Fatalf("expected about 2445 miles, got %.1f", miles)
+	}
+	if _, ok := DistanceMiles(newYork, Location{City: "Somewhere"}); ok {
+		t.Fatal("expected unknown coordinates to have no distance")
+	}
+}
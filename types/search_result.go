@@ -0,0 +1,13 @@
+package types
+
+// SearchResult is a provider payload normalized for parsing. Listings keep
+// the result they were parsed from so it can be replayed later.
+type SearchResult struct {
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	ExtraSnippets []string  `json:"extra_snippets,omitempty"`
+	RawContent    string    `json:"raw_content,omitempty"`
+	Prices        []float64 `json:"prices,omitempty"` // structured offer prices in the market currency
+	Age           string    `json:"age,omitempty"`    // provider-reported page age or date
+}
@@ -90,21 +90,6 @@ func compareListing(a, b Listing, field SortField) int {
 	}
 }
 
-func conditionSortRank(condition string) int {
-	switch strings.ToLower(strings.TrimSpace(condition)) {
-	case "new":
-		return 0
-	case "used":
-		return 1
-	case "good":
-		return 2
-	case "fair":
-		return 3
-	default:
-		return 4
-	}
-}
-
 func statusSortRank(status string) int {
 	switch strings.ToLower(strings.TrimSpace(status)) {
 	case "active":
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// Variant holds the product attributes that split one search into
+// differently priced units.
+type Variant struct {
+	Storage string // capacity such as "128GB" or "1TB"
+	Color   string // "Space Black", "Blue"
+	Carrier string // "Unlocked", "Verizon"
+	Size    string // shoe or clothing size such as "10.5" or "XL"
+	Model   string // model or part number such as "A2650" or "CFI-1215A"
+}
+
+// VariantAttribute selects which Variant field results are grouped by.
+type VariantAttribute string
+
+const (
+	VariantNone    VariantAttribute = ""
+	VariantStorage VariantAttribute = "storage"
+	VariantColor   VariantAttribute = "color"
+	VariantCarrier VariantAttribute = "carrier"
+	VariantSize    VariantAttribute = "size"
+	VariantModel   VariantAttribute = "model"
+)
+
+// VariantAttributes lists the groupable attributes in cycle order.
+var VariantAttributes = []VariantAttribute{
+	VariantStorage,
+	VariantColor,
+	VariantCarrier,
+	VariantSize,
+	VariantModel,
+}
+
+// VariantOther labels listings that lack the grouped attribute.
+const VariantOther = "Other"
+
+// Label returns the attribute's display name, e.g. "Storage".
+func (a VariantAttribute) Label() string {
+	if a == VariantNone {
+		return ""
+	}
+	return strings.ToUpper(string(a[:1])) + string(a[1:])
+}
+
+// Value returns the variant's value for an attribute, or "" when unknown.
+func (v Variant) Value(attr VariantAttribute) string {
+	switch attr {
+	case VariantStorage:
+		return v.Storage
+	case VariantColor:
+		return v.Color
+	case VariantCarrier:
+		return v.Carrier
+	case VariantSize:
+		return v.Size
+	case VariantModel:
+		return v.Model
+	default:
+		return ""
+	}
+}
+
+// IsZero reports whether no attribute was extracted.
+func (v Variant) IsZero() bool {
+	return v == Variant{}
+}
+
+// String joins the known attributes, e.g. "128GB · Blue · Unlocked".
+func (v Variant) String() string {
+	parts := make([]string, 0, len(VariantAttributes))
+	for _, attr := range VariantAttributes {
+		if value := v.Value(attr); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// VariantKey returns the bucket a listing falls in when grouped by attr.
+func VariantKey(listing Listing, attr VariantAttribute) string {
+	if value := strings.TrimSpace(listing.Variant.Value(attr)); value != "" {
+		return value
+	}
+	return VariantOther
+}
+
+// VariantGroup is one bucket of listings sharing an attribute value.
+type VariantGroup struct {
+	Label    string
+	Listings []Listing
+}
+
+// GroupByVariant buckets listings by attr, largest bucket first and
+// VariantOther last. Listings keep their input order inside a bucket.
+func GroupByVariant(in []Listing, attr VariantAttribute) []VariantGroup {
+	if len(in) == 0 || attr == VariantNone {
+		return nil
+	}
+	index := map[string]int{}
+	var groups []VariantGroup
+	for _, listing := range in {
+		key := VariantKey(listing, attr)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, VariantGroup{Label: key})
+		}
+		groups[i].Listings = append(groups[i].Listings, listing)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Label == VariantOther) != (b.Label == VariantOther) {
+			return b.Label == VariantOther
+		}
+		if len(a.Listings) != len(b.Listings) {
+			return len(a.Listings) > len(b.Listings)
+		}
+		return strings.ToLower(a.Label) < strings.ToLower(b.Label)
+	})
+	return groups
+}
+
+// SortByVariant reorders listings so each variant bucket is contiguous,
+// preserving the existing order within a bucket.
+func SortByVariant(in []Listing, attr VariantAttribute) []Listing {
+	groups := GroupByVariant(in, attr)
+	if groups == nil {
+		return append([]Listing(nil), in...)
+	}
+	out := make([]Listing, 0, len(in))
+	for _, group := range groups {
+		out = append(out, group.Listings...)
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package types
+
+import "testing"
+
+func TestGroupByVariant(t *testing.T) {
+	listings := []Listing{
+		{Price: 900, Variant: Variant{Storage: "1TB"}},
+		{Price: 600, Variant: Variant{Storage: "128GB"}},
+		{Price: 500},
+		{Price: 650, Variant: Variant{Storage: "128GB"}},
+	}
+
+	groups := GroupByVariant(listings, VariantStorage)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	labels := []string{groups[0].Label, groups[1].Label, groups[2].Label}
+	if labels[0] != "128GB" || labels[1] != "1TB" || labels[2] != VariantOther {
+		t.Fatalf("expected largest group first and Other last, got %v", labels)
+	}
+	if groups[0].Listings[0].Price != 600 || groups[0].Listings[1].Price != 650 {
+		t.Fatalf("expected input order kept inside a group, got %+v", groups[0].Listings)
+	}
+
+	sorted := SortByVariant(listings, VariantStorage)
+	if sorted[0].Price != 600 || sorted[1].Price != 650 || sorted[3].Price != 500 {
+		t.Fatalf("expected contiguous buckets, got %+v", sorted)
+	}
+	if got := GroupByVariant(listings, VariantNone); got != nil {
+		t.Fatalf("expected no groups without an attribute, got %+v", got)
+	}
+}
+
+func TestVariantString(t *testing.T) {
+	v := Variant{Storage: "256GB", Color: "Blue", Carrier: "Unlocked"}
+	if got := v.String(); got != "256GB · Blue · Unlocked" {
+		t.Fatalf("unexpected variant label %q", got)
+	}
+	if !(Variant{}).IsZero() || v.IsZero() {
+		t.Fatal("unexpected IsZero result")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -66,6 +67,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case SearchResultsMsg:
 		return m.handleSearchResults(msg)
 
+	case searchProgressMsg:
+		if msg.gen != m.searchGen || !m.loading {
+			return m, nil
+		}
+		status := m.providerStatusFor(msg.Progress.Provider)
+		status.Listings = msg.Progress.Listings
+		status.Page = msg.Progress.Page
+		return m, waitForSearchEvent(m.searchEvents)
+
+	case providerResultsMsg:
+		return m.handleProviderResults(msg)
+
 	case openURLResultMsg:
 		if msg.Err != nil {
 			m.err = msg.Err
@@ -100,6 +113,144 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case storeLoadedMsg[TrackedListing]:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.tracked = normalizeTrackedListings(msg.Items)
+		m.trackedIndex = 0
+		return m, nil
+
+	case storeSavedMsg:
+		if msg.Err != nil {
+			m.err = msg.Err
+		}
+		return m, nil
+
+	case trackedRefreshTickMsg:
+		next := scheduleTrackedRefresh(m.trackedRefresh)
+		if m.trackRefreshing || len(trackedQueries(m.tracked)) == 0 {
+			return m, next
+		}
+		m.trackRefreshing = true
+		cmd := m.refreshTrackedCmd()
+		return m, tea.Batch(next, cmd)
+
+	case categoryBatchResultMsg:
+		return m.updateCategoryBatch(msg)
+
+	case categoryBatchNextMsg:
+		if msg.Gen != m.browse.Batch.Gen {
+			return m, nil
+		}
+		cmd := m.categoryBatchSearchCmd()
+		return m, cmd
+
+	case trackedRefreshMsg:
+		m.trackRefreshing = false
+		m.cancelTrackedRefresh()
+		if msg.Err != nil && len(msg.Results) == 0 {
+			m.err = msg.Err
+			return m, nil
+		}
+		return m, m.observeTracked(applyPriceCorrections(msg.Results, m.priceCorrections))
+
+	case storeLoadedMsg[PriceCorrection]:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.priceCorrections = make(map[string]PriceCorrection, len(msg.Items))
+		for _, correction := range normalizeCorrections(msg.Items) {
+			m.priceCorrections[correction.URL] = correction
+		}
+		if len(m.rawResults) > 0 {
+			m.rawResults = applyPriceCorrections(m.rawResults, m.priceCorrections)
+			m.applySortAndFilter()
+		}
+		return m, nil
+
+	case catalogLoadedMsg:
+		if msg.Reload {
+			m.catalogReloading = false
+		}
+		m.productIndex = msg.Index
+		m.productIndex.SetExpansionRules(m.expansionRules)
+		m.productIndex.SetLearnedIdentifiers(m.learnedIdentifiers)
+		m.catalogStamp = msg.Stamp
+		m.refreshSearchSuggestions()
+		var cmds []tea.Cmd
+		if !msg.Reload {
+			cmds = append(cmds, scheduleCatalogWatch(m.catalogPaths))
+		}
+		if warning := catalogWarning(msg.Index.CatalogErrors()); warning != "" {
+			m.warning = warning
+		} else if strings.HasPrefix(m.warning, "Catalog: ") {
+			m.warning = ""
+		}
+		if msg.Reload {
+			cmds = append(cmds, m.setStatusFlash(fmt.Sprintf("Catalog reloaded: %d products", msg.Index.Len()), 1800*time.Millisecond))
+		}
+		m.catalogLoaded = true
+		updated, cmd := m.runStartupCode()
+		return updated, tea.Batch(append(cmds, cmd)...)
+
+	case catalogWatchTickMsg:
+		if msg.Stamp != m.catalogStamp {
+			cmd := m.reloadCatalogCmd()
+			return m, tea.Batch(cmd, scheduleCatalogWatch(m.catalogPaths))
+		}
+		return m, scheduleCatalogWatch(m.catalogPaths)
+
+	case catalogSavedMsg:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		reload := m.reloadCatalogCmd()
+		return m, tea.Batch(
+			m.setStatusFlash(fmt.Sprintf("Added %q to catalog", msg.Name), 1800*time.Millisecond),
+			reload,
+		)
+
+	case storeLoadedMsg[api.ExpansionRule]:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.expansionRules = normalizeExpansionRules(msg.Items)
+		m.productIndex.SetExpansionRules(m.expansionRules)
+		return m, nil
+
+	case storeLoadedMsg[api.LearnedIdentifier]:
+		m.identifiersLoaded = true
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m.runStartupCode()
+		}
+		m.learnedIdentifiers = normalizeLearnedIdentifiers(msg.Items)
+		m.productIndex.SetLearnedIdentifiers(m.learnedIdentifiers)
+		return m.runStartupCode()
+
+	case storeLoadedMsg[QueryStat]:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.queryStats = normalizeQueryStats(msg.Items)
+		return m, nil
+
+	case corpusCapturedMsg:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		if !msg.Labeled {
+			return m, m.setStatusFlash("Saved to parser corpus unlabeled; correct it with 1-9, C or O and save again", 2500*time.Millisecond)
+		}
+		return m, m.setStatusFlash("Saved to parser corpus", 1500*time.Millisecond)
+
 	case statusFlashClearMsg:
 		if msg.gen == m.statusFlashGen {
 			m.statusFlash = ""
@@ -212,6 +363,7 @@ func (m Model) handleSearchResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
 	m.loading = false
 	m.dataMode = msg.Mode
 	m.warning = msg.Warning
+	m.settleProviderStatuses(msg.ProviderErrors)
 	if msg.Err != nil {
 		if errors.Is(msg.Err, context.Canceled) {
 			return m, nil
@@ -227,6 +379,11 @@ func (m Model) handleSearchResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	msg.Results = applyPriceCorrections(msg.Results, m.priceCorrections)
+	if m.searchStreamed && msg.gen != 0 {
+		return m.reconcileStreamedResults(msg)
+	}
+
 	prevStats := m.extendedStats
 	m.rawResults = append([]types.Listing(nil), msg.Results...)
 	m.applySortAndFilter()
@@ -235,60 +392,81 @@ func (m Model) handleSearchResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
 	m.detailOpen = false
 	m.err = nil
 
-	cmds := make([]tea.Cmd, 0, 3)
+	cmds := make([]tea.Cmd, 0, 4)
 	if m.lastQuery != "" {
 		m.updateHistoryResultCount(m.lastQuery, len(m.results))
 		cmds = append(cmds, saveHistoryCmd(m.historyStore, m.historyEntries()))
+		cmds = append(cmds, m.recordQueryResult(m.lastQuery, len(m.results)))
 	}
+	cmds = append(cmds, m.observeTracked(msg.Results))
+	cmds = append(cmds, m.learnIdentifier(msg.Results))
+	cmds = append(cmds, m.restartResultsAnimation(prevStats)...)
 
-	if len(m.results) > 0 {
-		m.reveal.Gen++
-		m.statsReveal.Gen++
-		if m.reduceMotion {
-			m.reveal.Rows = min(len(m.results), m.visibleResultRowsForList())
-			m.reveal.Revealing = false
-			m.statsReveal.Revealed = m.statsRevealTargetLines()
-		} else {
-			m.reveal.Rows = 0
-			m.reveal.Revealing = true
-			revealGen := m.reveal.Gen
-			cmds = append(cmds, tea.Tick(30*time.Millisecond, func(time.Time) tea.Msg {
-				return revealRowTickMsg{gen: revealGen}
-			}))
+	if len(cmds) > 0 {
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
 
-			m.statsReveal.Revealed = 0
-			statsGen := m.statsReveal.Gen
-			cmds = append(cmds, tea.Tick(m.statsRevealTickDuration(), func(time.Time) tea.Msg {
-				return statsRevealTickMsg{gen: statsGen}
-			}))
-		}
+// reconcileStreamedResults applies the merged final response of a streamed
+// search without replaying the reveal for rows that are already visible.
+func (m Model) reconcileStreamedResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
+	prevStats := m.currentAnimatedStats()
+	prevCount := len(m.results)
+	m.rawResults = append([]types.Listing(nil), msg.Results...)
+	m.applySortAndFilter()
+	m.err = nil
 
-		m.statsAnim.ValueTweenOn = false
-		m.statsAnim.ValueStep = 0
-		m.statsAnim.ValueSteps = 0
-		m.statsAnim.DeltaTicks = 0
+	cmds := make([]tea.Cmd, 0, 5)
+	if m.lastQuery != "" {
+		m.updateHistoryResultCount(m.lastQuery, len(m.results))
+		cmds = append(cmds, saveHistoryCmd(m.historyStore, m.historyEntries()))
+		cmds = append(cmds, m.recordQueryResult(m.lastQuery, len(m.results)))
+	}
+	cmds = append(cmds, m.observeTracked(msg.Results))
+	cmds = append(cmds, m.learnIdentifier(msg.Results))
+	if len(m.results) != prevCount {
+		cmds = append(cmds, m.extendResultsAnimation(prevStats)...)
+	}
+	return m, tea.Batch(cmds...)
+}
 
-		if prevStats.Count > 0 && !m.reduceMotion {
-			m.statsAnim.FromStats = prevStats
-			m.statsAnim.ToStats = m.extendedStats
-			m.statsAnim.ValueTweenOn = true
-			m.statsAnim.ValueStep = 0
-			m.statsAnim.ValueSteps = 15
-			m.statsAnim.DeltaTotal = 24
-			m.statsAnim.DeltaTicks = m.statsAnim.DeltaTotal
-			m.statsAnim.DeltaMin = m.extendedStats.Min - prevStats.Min
-			m.statsAnim.DeltaMax = m.extendedStats.Max - prevStats.Max
-			m.statsAnim.DeltaAvg = m.extendedStats.Average - prevStats.Average
-			m.statsAnim.DeltaMedian = m.extendedStats.Median - prevStats.Median
-			m.statsAnim.DeltaP25 = m.extendedStats.P25 - prevStats.P25
-			m.statsAnim.DeltaP75 = m.extendedStats.P75 - prevStats.P75
-			m.statsAnim.ValueTweenGen++
-			valueGen := m.statsAnim.ValueTweenGen
-			cmds = append(cmds, tea.Tick(33*time.Millisecond, func(time.Time) tea.Msg {
-				return statsValueTickMsg{gen: valueGen}
-			}))
-		}
-	} else {
+func (m Model) handleProviderResults(msg providerResultsMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.searchGen || !m.loading {
+		return m, nil
+	}
+
+	m.markProviderDone(msg.Result)
+	next := waitForSearchEvent(m.searchEvents)
+	if msg.Result.Err != nil || len(msg.Result.Results) == 0 {
+		return m, next
+	}
+
+	msg.Result.Results = applyPriceCorrections(msg.Result.Results, m.priceCorrections)
+	prevStats := m.currentAnimatedStats()
+	cmds := []tea.Cmd{next}
+	if !m.searchStreamed {
+		// The first streamed batch replaces the previous search's rows.
+		m.searchStreamed = true
+		m.rawResults = append([]types.Listing(nil), msg.Result.Results...)
+		m.applySortAndFilter()
+		m.selectedIndex = 0
+		m.resultsOffset = 0
+		m.detailOpen = false
+		m.err = nil
+		cmds = append(cmds, m.restartResultsAnimation(prevStats)...)
+		return m, tea.Batch(cmds...)
+	}
+
+	m.rawResults = mergeListings(m.rawResults, msg.Result.Results)
+	m.applySortAndFilter()
+	cmds = append(cmds, m.extendResultsAnimation(prevStats)...)
+	return m, tea.Batch(cmds...)
+}
+
+// restartResultsAnimation reveals a fresh result set from the first row.
+func (m *Model) restartResultsAnimation(prevStats idea.ExtendedStatistics) []tea.Cmd {
+	if len(m.results) == 0 {
 		m.reveal.Revealing = false
 		m.reveal.Rows = 0
 		m.statsReveal.Revealed = 0
@@ -296,12 +474,137 @@ func (m Model) handleSearchResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
 		m.statsAnim.ValueStep = 0
 		m.statsAnim.ValueSteps = 0
 		m.statsAnim.DeltaTicks = 0
+		return nil
 	}
 
-	if len(cmds) > 0 {
-		return m, tea.Batch(cmds...)
+	var cmds []tea.Cmd
+	m.reveal.Gen++
+	m.statsReveal.Gen++
+	if m.reduceMotion {
+		m.reveal.Rows = min(len(m.results), m.visibleResultRowsForList())
+		m.reveal.Revealing = false
+		m.statsReveal.Revealed = m.statsRevealTargetLines()
+	} else {
+		m.reveal.Rows = 0
+		m.reveal.Revealing = true
+		revealGen := m.reveal.Gen
+		cmds = append(cmds, tea.Tick(30*time.Millisecond, func(time.Time) tea.Msg {
+			return revealRowTickMsg{gen: revealGen}
+		}))
+
+		m.statsReveal.Revealed = 0
+		statsGen := m.statsReveal.Gen
+		cmds = append(cmds, tea.Tick(m.statsRevealTickDuration(), func(time.Time) tea.Msg {
+			return statsRevealTickMsg{gen: statsGen}
+		}))
+	}
+
+	m.statsAnim.ValueTweenOn = false
+	m.statsAnim.ValueStep = 0
+	m.statsAnim.ValueSteps = 0
+	m.statsAnim.DeltaTicks = 0
+
+	if prevStats.Count > 0 && !m.reduceMotion {
+		cmds = append(cmds, m.startStatsValueTween(prevStats))
+	}
+	return cmds
+}
+
+// extendResultsAnimation continues the reveal for rows added to a visible
+// result set and tweens the statistics toward their new values.
+func (m *Model) extendResultsAnimation(prevStats idea.ExtendedStatistics) []tea.Cmd {
+	if len(m.results) == 0 {
+		return m.restartResultsAnimation(prevStats)
+	}
+
+	targetRows := min(len(m.results), m.visibleResultRowsForList())
+	if m.reduceMotion {
+		m.reveal.Revealing = false
+		m.reveal.Rows = targetRows
+		m.statsReveal.Revealed = m.statsRevealTargetLines()
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	if !m.reveal.Revealing && m.reveal.Rows < targetRows {
+		m.reveal.Gen++
+		m.reveal.Revealing = true
+		revealGen := m.reveal.Gen
+		cmds = append(cmds, tea.Tick(30*time.Millisecond, func(time.Time) tea.Msg {
+			return revealRowTickMsg{gen: revealGen}
+		}))
+	}
+	if m.statsReveal.Revealed < m.statsRevealTargetLines() {
+		m.statsReveal.Gen++
+		statsGen := m.statsReveal.Gen
+		cmds = append(cmds, tea.Tick(m.statsRevealTickDuration(), func(time.Time) tea.Msg {
+			return statsRevealTickMsg{gen: statsGen}
+		}))
+	}
+	if prevStats.Count > 0 {
+		cmds = append(cmds, m.startStatsValueTween(prevStats))
+	}
+	return cmds
+}
+
+func (m *Model) startStatsValueTween(prevStats idea.ExtendedStatistics) tea.Cmd {
+	m.statsAnim.FromStats = prevStats
+	m.statsAnim.ToStats = m.extendedStats
+	m.statsAnim.ValueTweenOn = true
+	m.statsAnim.ValueStep = 0
+	m.statsAnim.ValueSteps = 15
+	m.statsAnim.DeltaTotal = 24
+	m.statsAnim.DeltaTicks = m.statsAnim.DeltaTotal
+	m.statsAnim.DeltaMin = m.extendedStats.Min - prevStats.Min
+	m.statsAnim.DeltaMax = m.extendedStats.Max - prevStats.Max
+	m.statsAnim.DeltaAvg = m.extendedStats.Average - prevStats.Average
+	m.statsAnim.DeltaMedian = m.extendedStats.Median - prevStats.Median
+	m.statsAnim.DeltaP25 = m.extendedStats.P25 - prevStats.P25
+	m.statsAnim.DeltaP75 = m.extendedStats.P75 - prevStats.P75
+	m.statsAnim.ValueTweenGen++
+	valueGen := m.statsAnim.ValueTweenGen
+	return tea.Tick(33*time.Millisecond, func(time.Time) tea.Msg {
+		return statsValueTickMsg{gen: valueGen}
+	})
+}
+
+func (m *Model) providerStatusFor(name string) *providerStatus {
+	for i := range m.searchProviders {
+		if m.searchProviders[i].Name == name {
+			return &m.searchProviders[i]
+		}
+	}
+	m.searchProviders = append(m.searchProviders, providerStatus{Name: name})
+	return &m.searchProviders[len(m.searchProviders)-1]
+}
+
+func (m *Model) markProviderDone(result api.ProviderResult) {
+	status := m.providerStatusFor(result.Provider)
+	if result.Err != nil {
+		status.State = providerFailed
+		return
+	}
+	status.State = providerFinished
+	status.Listings = len(result.Results)
+}
+
+// settleProviderStatuses resolves providers still pending when the final
+// response arrives, e.g. because their streamed event was dropped.
+func (m *Model) settleProviderStatuses(providerErrors []api.ProviderError) {
+	failed := make(map[string]struct{}, len(providerErrors))
+	for _, providerErr := range providerErrors {
+		failed[providerErr.Provider] = struct{}{}
+	}
+	for i := range m.searchProviders {
+		if m.searchProviders[i].State != providerPending {
+			continue
+		}
+		if _, ok := failed[m.searchProviders[i].Name]; ok {
+			m.searchProviders[i].State = providerFailed
+		} else {
+			m.searchProviders[i].State = providerFinished
+		}
 	}
-	return m, nil
 }
 
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -311,6 +614,13 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.catalogPromptOpen {
+		return m.handleCatalogPromptKeys(msg)
+	}
+	if m.expansionPromptOpen {
+		return m.handleExpansionPromptKeys(msg)
+	}
+
 	// Let text inputs accept literal "m". Use motion toggle from non-input panels.
 	if key.Matches(msg, m.keys.ToggleAnim) &&
 		m.focusedPanel != panelSearch &&
@@ -319,14 +629,34 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Market switching shares the text-input guard above.
+	if key.Matches(msg, m.keys.Market) &&
+		m.focusedPanel != panelSearch &&
+		m.focusedPanel != panelCalculator {
+		return m.cycleMarket()
+	}
+
+	if key.Matches(msg, m.keys.Tracked) &&
+		m.focusedPanel != panelSearch &&
+		m.focusedPanel != panelCalculator {
+		return m.toggleTrackedPanel()
+	}
+	if key.Matches(msg, m.keys.Categories) &&
+		m.focusedPanel != panelSearch &&
+		m.focusedPanel != panelCalculator {
+		return m.toggleCategoryBrowser()
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.ForceQuit):
 		m.cancelActiveSearch()
+		m.cancelBackgroundSearches()
 		return m, tea.Quit
 
 	case key.Matches(msg, m.keys.Quit):
 		if m.focusedPanel != panelSearch && m.focusedPanel != panelCalculator {
 			m.cancelActiveSearch()
+			m.cancelBackgroundSearches()
 			return m, tea.Quit
 		}
 
@@ -358,6 +688,17 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Escape):
 		if m.focusedPanel == panelResults {
+			if m.trackedOpen {
+				m.trackedOpen = false
+				return m, nil
+			}
+			if m.browse.Open {
+				if m.browse.Category != "" {
+					return m.closeCategory(), nil
+				}
+				m.browse.Open = false
+				return m, nil
+			}
 			if m.detailOpen {
 				m.detailOpen = false
 				return m, nil
@@ -387,6 +728,151 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openCatalogPrompt asks for synonyms to save the current query as a product.
+func (m Model) openCatalogPrompt() (tea.Model, tea.Cmd) {
+	query := strings.TrimSpace(m.searchInput.Value())
+	if query == "" {
+		query = m.lastQuery
+	}
+	if query == "" || m.userCatalogPath == "" {
+		return m, m.setStatusFlash("Search for something to add it to the catalog", 1500*time.Millisecond)
+	}
+	m.catalogQuery = query
+	m.catalogPromptOpen = true
+	m.catalogPrompt.SetValue("")
+	m.catalogPrompt.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleCatalogPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.ForceQuit):
+		m.cancelActiveSearch()
+		m.cancelBackgroundSearches()
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Escape):
+		m.catalogPromptOpen = false
+		m.catalogPrompt.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		m.catalogPromptOpen = false
+		m.catalogPrompt.Blur()
+		entry := api.ProductEntry{
+			Name:     m.catalogQuery,
+			Category: catalogCategory,
+			Synonyms: parseSynonymsInput(m.catalogPrompt.Value()),
+		}
+		return m, saveCatalogEntryCmd(m.userCatalogPath, entry)
+	}
+
+	var cmd tea.Cmd
+	m.catalogPrompt, cmd = m.catalogPrompt.Update(msg)
+	return m, cmd
+}
+
+// toggleLiteralSearch re-runs the last query without expansion, or with it
+// again when the last search was already literal.
+func (m Model) toggleLiteralSearch() (tea.Model, tea.Cmd) {
+	if m.lastQuery == "" {
+		return m, m.setStatusFlash("Search for something first", 1500*time.Millisecond)
+	}
+	expand := m.lastSearchLiteral
+	if !expand && !m.lastExpansion.Changed() {
+		return m, m.setStatusFlash("Last search was not expanded", 1500*time.Millisecond)
+	}
+	return m.runSearch(m.lastQuery, false, expand)
+}
+
+// toggleNeverExpand pins the last query to never be expanded, or lifts
+// that pin.
+func (m Model) toggleNeverExpand() (tea.Model, tea.Cmd) {
+	query := m.lastExpansion.Query
+	if query == "" {
+		return m, m.setStatusFlash("Search for something first", 1500*time.Millisecond)
+	}
+	if rule := m.lastExpansion.Rule; rule != nil && rule.Never {
+		return m.setExpansionRule(api.ExpansionRule{}, fmt.Sprintf("Expansion allowed for %q", query))
+	}
+	return m.setExpansionRule(api.ExpansionRule{Never: true}, fmt.Sprintf("Never expanding %q", query))
+}
+
+// openExpansionPrompt asks which product the last query should always be
+// expanded to, starting from the current expansion or best candidate.
+func (m Model) openExpansionPrompt() (tea.Model, tea.Cmd) {
+	if m.lastExpansion.Query == "" {
+		return m, m.setStatusFlash("Search for something first", 1500*time.Millisecond)
+	}
+	value := ""
+	switch {
+	case m.lastExpansion.Changed():
+		value = m.lastExpansion.Expanded
+	case len(m.lastExpansion.Candidates) > 0:
+		value = m.lastExpansion.Candidates[0].Name
+	}
+	m.expansionPromptIndex = 0
+	m.expansionPromptOpen = true
+	m.expansionPrompt.SetValue(value)
+	m.expansionPrompt.CursorEnd()
+	m.expansionPrompt.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleExpansionPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.ForceQuit):
+		m.cancelActiveSearch()
+		m.cancelBackgroundSearches()
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Escape):
+		m.expansionPromptOpen = false
+		m.expansionPrompt.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Tab):
+		if candidates := m.lastExpansion.Candidates; len(candidates) > 0 {
+			m.expansionPromptIndex = (m.expansionPromptIndex + 1) % len(candidates)
+			m.expansionPrompt.SetValue(candidates[m.expansionPromptIndex].Name)
+			m.expansionPrompt.CursorEnd()
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		m.expansionPromptOpen = false
+		m.expansionPrompt.Blur()
+		target := strings.TrimSpace(m.expansionPrompt.Value())
+		query := m.lastExpansion.Query
+		if target == "" {
+			return m.setExpansionRule(api.ExpansionRule{}, fmt.Sprintf("Unpinned %q", query))
+		}
+		return m.setExpansionRule(api.ExpansionRule{Target: target}, fmt.Sprintf("Always expanding %q to %q", query, target))
+	}
+
+	var cmd tea.Cmd
+	m.expansionPrompt, cmd = m.expansionPrompt.Update(msg)
+	return m, cmd
+}
+
+// setExpansionRule saves rule for the last query (an empty rule removes it)
+// and re-runs the search when that changes what would be searched.
+func (m Model) setExpansionRule(rule api.ExpansionRule, flash string) (tea.Model, tea.Cmd) {
+	query := m.lastExpansion.Query
+	m.expansionRules = withExpansionRule(m.expansionRules, query, rule)
+	m.productIndex.SetExpansionRules(m.expansionRules)
+	cmds := []tea.Cmd{
+		saveStoreCmd(m.expansionStore, m.expansionRules),
+		m.setStatusFlash(flash, 1800*time.Millisecond),
+	}
+	if m.lastSearchLiteral || m.productIndex == nil {
+		return m, tea.Batch(cmds...)
+	}
+
+	expansion := m.productIndex.Explain(query)
+	if expansion.Expanded == m.lastExpansion.Expanded {
+		m.lastExpansion = expansion
+		return m, tea.Batch(cmds...)
+	}
+	next, cmd := m.startSearch(m.lastQuery, false)
+	return next, tea.Batch(append(cmds, cmd)...)
+}
+
 func (m Model) handleStatsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.StatsSum):
@@ -400,6 +886,21 @@ func (m Model) handleStatsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// cycleMarket switches to the next regional market. Listings from another
+// market are priced in a different currency, so the last query is re-run.
+func (m Model) cycleMarket() (tea.Model, tea.Cmd) {
+	m.market = api.NextMarket(m.market.Code)
+	if !m.hasCalcPlatform(m.calcPlatform) {
+		m.calcPlatform = m.calcPlatforms()[0]
+	}
+	flash := m.setStatusFlash(fmt.Sprintf("Market: %s (%s)", m.market.Code, m.market.Currency), 2*time.Second)
+	if m.lastQuery == "" {
+		return m, flash
+	}
+	next, cmd := m.startSearch(m.lastQuery, false)
+	return next, tea.Batch(flash, cmd)
+}
+
 func (m Model) toggleReduceMotion() Model {
 	m.reduceMotion = !m.reduceMotion
 	if !m.reduceMotion {
@@ -478,6 +979,13 @@ func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.trackedOpen {
+		return m.handleTrackedKeys(msg)
+	}
+	if m.browse.Open {
+		return m.handleCategoryKeys(msg)
+	}
+
 	if key.Matches(msg, m.keys.SortCycle) {
 		m.sortField = m.nextSortField()
 		m.applySortAndFilter()
@@ -496,6 +1004,26 @@ func (m Model) handleResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if key.Matches(msg, m.keys.GroupBy) {
+		m.groupBy = m.nextGroupBy()
+		m.applySortAndFilter()
+		m.resetResultsSelection()
+		return m, nil
+	}
+
+	if !m.filterBarActive && !m.detailOpen {
+		switch {
+		case key.Matches(msg, m.keys.CatalogAdd):
+			return m.openCatalogPrompt()
+		case key.Matches(msg, m.keys.Literal):
+			return m.toggleLiteralSearch()
+		case key.Matches(msg, m.keys.NeverExpand):
+			return m.toggleNeverExpand()
+		case key.Matches(msg, m.keys.PinExpand):
+			return m.openExpansionPrompt()
+		}
+	}
+
 	if key.Matches(msg, m.keys.FilterToggle) {
 		m.filterBarActive = !m.filterBarActive
 		if m.filterBarActive {
@@ -513,22 +1041,27 @@ func (m Model) handleResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.resetResultsSelection()
 			return m, nil
 		case key.Matches(msg, m.keys.FilterNew):
-			if strings.EqualFold(m.resultFilter.Condition, "New") {
-				m.resultFilter.Condition = ""
-			} else {
-				m.resultFilter.Condition = "New"
-			}
-			m.applySortAndFilter()
-			m.resetResultsSelection()
+			m.toggleConditionFilter(types.ConditionNew)
 			return m, nil
 		case key.Matches(msg, m.keys.FilterUsed):
-			if strings.EqualFold(m.resultFilter.Condition, "Used") {
-				m.resultFilter.Condition = ""
-			} else {
-				m.resultFilter.Condition = "Used"
-			}
+			m.toggleConditionFilter(types.ConditionUsed)
+			return m, nil
+		case key.Matches(msg, m.keys.FilterRefurb):
+			m.toggleConditionFilter(types.ConditionRefurbished)
+			return m, nil
+		case key.Matches(msg, m.keys.FilterParts):
+			m.includeParts = !m.includeParts
 			m.applySortAndFilter()
-			m.resetResultsSelection()
+			m.statsReveal.Revealed = m.statsRevealTargetLines()
+			return m, nil
+		case key.Matches(msg, m.keys.FilterLots):
+			m.listedPrices = !m.listedPrices
+			m.applySortAndFilter()
+			return m, nil
+		case key.Matches(msg, m.keys.FilterBundle):
+			m.bundleMode = (m.bundleMode + 1) % (idea.BundlesSeparate + 1)
+			m.applySortAndFilter()
+			m.statsReveal.Revealed = m.statsRevealTargetLines()
 			return m, nil
 		case key.Matches(msg, m.keys.FilterStatus):
 			switch strings.ToLower(strings.TrimSpace(m.resultFilter.Status)) {
@@ -542,6 +1075,21 @@ func (m Model) handleResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.applySortAndFilter()
 			m.resetResultsSelection()
 			return m, nil
+		case key.Matches(msg, m.keys.FilterAge):
+			m.recencyDays = nextRecencyWindow(m.recencyDays)
+			m.resultFilter.Since = recencyCutoff(m.recencyDays, time.Now())
+			m.applySortAndFilter()
+			m.resetResultsSelection()
+			return m, nil
+		case key.Matches(msg, m.keys.FilterMiles):
+			if !m.resultFilter.Home.Known() {
+				return m, m.setStatusFlash("Set MRKTR_HOME to filter by pickup distance", 2*time.Second)
+			}
+			m.distanceMiles = nextDistanceWindow(m.distanceMiles)
+			m.resultFilter.MaxDistance = float64(m.distanceMiles)
+			m.applySortAndFilter()
+			m.resetResultsSelection()
+			return m, nil
 		}
 	}
 
@@ -572,6 +1120,27 @@ func (m Model) handleResultsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, openURLCmd(selected.URL)
 			}
 		}
+		if key.Matches(msg, m.keys.Track) {
+			return m.toggleTrackListing(selected)
+		}
+		if key.Matches(msg, m.keys.PickPrice) {
+			n, _ := strconv.Atoi(msg.String())
+			return m.pickPriceCandidate(selected, n-1)
+		}
+		if key.Matches(msg, m.keys.SetCondition) {
+			return m.correctListing(selected, nextCondition(selected.Condition), "")
+		}
+		if key.Matches(msg, m.keys.SetStatus) {
+			return m.correctListing(selected, "", nextStatus(selected.Status))
+		}
+		if key.Matches(msg, m.keys.Capture) {
+			correction := m.priceCorrections[strings.TrimSpace(selected.URL)]
+			entry, ok := corpusEntryFor(selected, correction, m.market.Currency, time.Now().UTC())
+			if !ok || m.corpusPath == "" {
+				return m, m.setStatusFlash("Nothing to capture for this listing", 1500*time.Millisecond)
+			}
+			return m, captureCorpusCmd(m.corpusPath, entry)
+		}
 		return m, nil
 	}
 
@@ -619,24 +1188,34 @@ func (m Model) handleCalculatorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	if key.Matches(msg, m.keys.Enter) {
-		if val, err := strconv.ParseFloat(m.costInput.Value(), 64); err == nil {
-			m.cost = val
-		} else {
-			m.cost = 0
-		}
+		m.cost, m.lotUnits = parseCostInput(m.costInput.Value())
 		return m, nil
 	}
 
 	var cmd tea.Cmd
 	m.costInput, cmd = m.costInput.Update(msg)
-	if val, err := strconv.ParseFloat(m.costInput.Value(), 64); err == nil {
-		m.cost = val
-	} else {
-		m.cost = 0
-	}
+	m.cost, m.lotUnits = parseCostInput(m.costInput.Value())
 	return m, cmd
 }
 
+// parseCostInput reads "60" as a single unit costing $60 and "60/5" as a
+// lot of five bought for $60 in total. Invalid input yields a zero cost.
+func parseCostInput(raw string) (float64, int) {
+	costText, unitsText, isLot := strings.Cut(strings.TrimSpace(raw), "/")
+	cost, err := strconv.ParseFloat(strings.TrimSpace(costText), 64)
+	if err != nil {
+		return 0, 1
+	}
+	if !isLot {
+		return cost, 1
+	}
+	units, err := strconv.Atoi(strings.TrimSpace(unitsText))
+	if err != nil || units < 1 {
+		return cost, 1
+	}
+	return cost, units
+}
+
 func (m Model) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if key.Matches(msg, m.keys.HistNext) {
 		if m.historyIndex < len(m.history)-1 {
@@ -701,6 +1280,310 @@ func (m Model) changeFocus(newPanel int) (tea.Model, tea.Cmd) {
 	})
 }
 
+// pickPriceCandidate corrects the listing's price to one of its parsed
+// candidates and saves the correction for later searches. Picking the
+// parser's own choice removes the correction.
+func (m Model) pickPriceCandidate(listing types.Listing, index int) (tea.Model, tea.Cmd) {
+	if index < 0 || index >= len(listing.PriceCandidates) {
+		return m, nil
+	}
+	url := strings.TrimSpace(listing.URL)
+	if url == "" {
+		return m, m.setStatusFlash("Listing has no URL to correct", 1500*time.Millisecond)
+	}
+
+	candidate := listing.PriceCandidates[index]
+	parsed := listing.Price
+	if existing, ok := m.priceCorrections[url]; ok && existing.Price > 0 {
+		parsed = existing.Parsed
+	}
+
+	corrections := make(map[string]PriceCorrection, len(m.priceCorrections)+1)
+	for k, v := range m.priceCorrections {
+		corrections[k] = v
+	}
+	flash := fmt.Sprintf("Price set to $%.2f", candidate.Amount)
+	correction := corrections[url]
+	correction.URL = url
+	correction.Timestamp = time.Now().UTC()
+	if candidate.Amount == parsed {
+		correction.Price, correction.Parsed, correction.Pattern = 0, 0, ""
+		flash = "Price reset to parsed value"
+	} else {
+		correction.Price = candidate.Amount
+		correction.Parsed = parsed
+		correction.Pattern = candidate.Pattern
+	}
+	if correction.empty() {
+		delete(corrections, url)
+	} else {
+		corrections[url] = correction
+	}
+	m.priceCorrections = corrections
+
+	raw := append([]types.Listing(nil), m.rawResults...)
+	for i := range raw {
+		if strings.TrimSpace(raw[i].URL) == url {
+			raw[i].Price = candidate.Amount
+		}
+	}
+	return m.applyCorrection(listing, raw, flash)
+}
+
+// correctListing sets the listing's condition or status, whichever is
+// non-empty, and saves it with the listing's correction so it applies to
+// later searches and labels the listing in the parser corpus.
+func (m Model) correctListing(listing types.Listing, condition, status string) (tea.Model, tea.Cmd) {
+	url := strings.TrimSpace(listing.URL)
+	if url == "" {
+		return m, m.setStatusFlash("Listing has no URL to correct", 1500*time.Millisecond)
+	}
+
+	corrections := make(map[string]PriceCorrection, len(m.priceCorrections)+1)
+	for k, v := range m.priceCorrections {
+		corrections[k] = v
+	}
+	correction := corrections[url]
+	correction.URL = url
+	correction.Timestamp = time.Now().UTC()
+	flash := ""
+	if condition != "" {
+		correction.Condition = condition
+		flash = "Condition set to " + condition
+	}
+	if status != "" {
+		correction.Status = status
+		flash = "Status set to " + status
+	}
+	corrections[url] = correction
+	m.priceCorrections = corrections
+
+	raw := append([]types.Listing(nil), m.rawResults...)
+	for i := range raw {
+		if strings.TrimSpace(raw[i].URL) == url {
+			correction.applyTo(&raw[i])
+		}
+	}
+	return m.applyCorrection(listing, raw, flash)
+}
+
+// applyCorrection shows corrected raw results, keeping the corrected listing
+// open in the detail view while the filters still show it, and saves the
+// corrections.
+func (m Model) applyCorrection(listing types.Listing, raw []types.Listing, flash string) (tea.Model, tea.Cmd) {
+	m.rawResults = raw
+	m.applySortAndFilter()
+	m.detailOpen = false
+	for i, result := range m.results {
+		if result.Key() == listing.Key() {
+			m.selectedIndex = i
+			m.detailOpen = true
+			break
+		}
+	}
+	m.clampResultsOffset()
+
+	return m, tea.Batch(
+		m.setStatusFlash(flash, 1500*time.Millisecond),
+		saveStoreCmd(m.correctionStore, m.correctionList()),
+	)
+}
+
+func (m Model) correctionList() []PriceCorrection {
+	out := make([]PriceCorrection, 0, len(m.priceCorrections))
+	for _, correction := range m.priceCorrections {
+		out = append(out, correction)
+	}
+	return normalizeCorrections(out)
+}
+
+// toggleTrackedPanel shows or hides the tracked listings in the results panel.
+func (m Model) toggleTrackedPanel() (tea.Model, tea.Cmd) {
+	m.trackedOpen = !m.trackedOpen
+	if !m.trackedOpen {
+		return m, nil
+	}
+	m.browse.Open = false
+	m.detailOpen = false
+	m.filterBarActive = false
+	m.trackedIndex = min(m.trackedIndex, max(0, len(m.tracked)-1))
+	return m.changeFocus(panelResults)
+}
+
+func (m Model) handleTrackedKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.RefreshTrack):
+		if m.trackRefreshing {
+			return m, nil
+		}
+		if len(trackedQueries(m.tracked)) == 0 {
+			return m, m.setStatusFlash("Nothing to refresh", 1500*time.Millisecond)
+		}
+		m.trackRefreshing = true
+		cmd := m.refreshTrackedCmd()
+		return m, cmd
+	case len(m.tracked) == 0:
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		if m.trackedIndex < len(m.tracked)-1 {
+			m.trackedIndex++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.trackedIndex > 0 {
+			m.trackedIndex--
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if url := m.tracked[m.trackedIndex].URL; url != "" {
+			return m, openURLCmd(url)
+		}
+	case key.Matches(msg, m.keys.FollowRelist), key.Matches(msg, m.keys.IgnoreRelist):
+		if m.tracked[m.trackedIndex].PossibleRelist == nil {
+			return m, nil
+		}
+		tracked := append([]TrackedListing(nil), m.tracked...)
+		flash := "Ignored possible relist"
+		if key.Matches(msg, m.keys.FollowRelist) {
+			tracked[m.trackedIndex].FollowRelist(time.Now().UTC())
+			flash = "Following relist"
+		} else {
+			tracked[m.trackedIndex].IgnoreRelist()
+		}
+		m.tracked = tracked
+		cmd := m.setStatusFlash(flash, 1500*time.Millisecond)
+		return m, tea.Batch(cmd, saveStoreCmd(m.trackedStore, m.tracked))
+	case key.Matches(msg, m.keys.Track):
+		m.tracked = append(m.tracked[:m.trackedIndex:m.trackedIndex], m.tracked[m.trackedIndex+1:]...)
+		m.trackedIndex = min(m.trackedIndex, max(0, len(m.tracked)-1))
+		return m, saveStoreCmd(m.trackedStore, m.tracked)
+	}
+	return m, nil
+}
+
+// toggleTrackListing starts or stops following the listing in the detail view.
+func (m Model) toggleTrackListing(listing types.Listing) (tea.Model, tea.Cmd) {
+	key := listing.Key()
+	if key == "" {
+		return m, m.setStatusFlash("Listing has no URL to track", 1500*time.Millisecond)
+	}
+
+	if i := trackedIndexOf(m.tracked, key); i >= 0 {
+		m.tracked = append(m.tracked[:i:i], m.tracked[i+1:]...)
+		m.trackedIndex = min(m.trackedIndex, max(0, len(m.tracked)-1))
+		flash := m.setStatusFlash("Untracked", 1500*time.Millisecond)
+		return m, tea.Batch(flash, saveStoreCmd(m.trackedStore, m.tracked))
+	}
+
+	if len(m.tracked) >= trackedMaxListings {
+		return m, m.setStatusFlash(fmt.Sprintf("Tracking limit of %d reached", trackedMaxListings), 2*time.Second)
+	}
+	entry := newTrackedListing(listing, m.lastQuery, time.Now().UTC())
+	m.tracked = append([]TrackedListing{entry}, m.tracked...)
+	flash := m.setStatusFlash("Tracking listing", 1500*time.Millisecond)
+	return m, tea.Batch(flash, saveStoreCmd(m.trackedStore, m.tracked))
+}
+
+// observeTracked records price and status changes for tracked listings that
+// appear in listings and flashes the first change.
+func (m *Model) observeTracked(listings []types.Listing) tea.Cmd {
+	if len(m.tracked) == 0 || len(listings) == 0 {
+		return nil
+	}
+
+	// Copy before updating so earlier model values keep their timelines.
+	tracked := append([]TrackedListing(nil), m.tracked...)
+	now := time.Now().UTC()
+	matched := false
+	var changes []string
+	for _, listing := range listings {
+		i := trackedIndexOf(tracked, listing.Key())
+		if i < 0 {
+			if i = relistIndexOf(tracked, listing); i >= 0 {
+				matched = true
+				if tracked[i].SuggestRelist(listing, now) {
+					changes = append(changes, fmt.Sprintf("%s: possible relist", truncate(sanitizeDisplayText(tracked[i].Title), 24)))
+				}
+			}
+			continue
+		}
+		matched = true
+		if event, ok := tracked[i].Observe(listing, now); ok {
+			changes = append(changes, fmt.Sprintf("%s: %s", truncate(sanitizeDisplayText(tracked[i].Title), 24), event.Kind.Label()))
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	m.tracked = tracked
+	cmds := []tea.Cmd{saveStoreCmd(m.trackedStore, m.tracked)}
+	if len(changes) > 0 {
+		text := "Tracked " + changes[0]
+		if len(changes) > 1 {
+			text += fmt.Sprintf(" (+%d more)", len(changes)-1)
+		}
+		cmds = append(cmds, m.setStatusFlash(text, 3*time.Second))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshTrackedCmd re-runs the searches that found tracked listings without
+// touching the visible results. The refresh is canceled on quit.
+func (m *Model) refreshTrackedCmd() tea.Cmd {
+	m.cancelTrackedRefresh()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.trackCancel = cancel
+	client := m.apiClient
+	if client == nil {
+		client = api.NewEnvClient()
+	}
+
+	var requests []api.SearchRequest
+	for _, query := range trackedQueries(m.tracked) {
+		if req, ok := m.backgroundRequest(query); ok {
+			requests = append(requests, req)
+		}
+	}
+
+	return func() tea.Msg {
+		var results []types.Listing
+		var firstErr error
+		for _, req := range requests {
+			if ctx.Err() != nil {
+				break
+			}
+			response := client.SearchPricesRequest(ctx, req)
+			if response.Err != nil && firstErr == nil {
+				firstErr = response.Err
+			}
+			results = append(results, response.Results...)
+		}
+		return trackedRefreshMsg{Results: results, Err: firstErr}
+	}
+}
+
+// backgroundRequest builds the provider request for a search run without
+// touching the visible results, expanding and narrowing it like a typed
+// search.
+func (m Model) backgroundRequest(query string) (api.SearchRequest, bool) {
+	parsed, err := api.ParseQuery(query)
+	if err != nil {
+		return api.SearchRequest{}, false
+	}
+	keywords := parsed.Keywords()
+	if m.productIndex != nil && keywords != "" {
+		keywords = m.productIndex.Expand(keywords)
+	}
+	req := parsed.Request(keywords)
+	if product, ok := m.productIndex.Product(keywords); ok {
+		req = product.Narrow(req)
+	}
+	req.Depth = m.searchDepth
+	req.MaxRequests = m.searchMaxPages
+	req.Rules = m.parseRules
+	req.Market = m.market
+	return req, true
+}
+
 // addToHistory adds a search query to history (avoiding duplicates).
 func (m *Model) addToHistory(query string, ts time.Time) {
 	query = strings.TrimSpace(query)
@@ -783,68 +1666,12 @@ func (m *Model) refreshSearchSuggestions() {
 		return
 	}
 
-	history := filterHistory(m.history, prefix, 4)
 	var products []string
 	if m.productIndex != nil {
 		products = m.productIndex.Suggest(prefix)
 	}
 
-	m.searchInput.SetSuggestions(mergeSuggestions(history, products, 8))
-}
-
-func filterHistory(history []string, prefix string, limit int) []string {
-	if len(history) == 0 || limit <= 0 {
-		return nil
-	}
-
-	p := strings.ToLower(strings.TrimSpace(prefix))
-	if p == "" {
-		return nil
-	}
-
-	out := make([]string, 0, limit)
-	for _, item := range history {
-		if strings.HasPrefix(strings.ToLower(item), p) {
-			out = append(out, item)
-			if len(out) == limit {
-				break
-			}
-		}
-	}
-	return out
-}
-
-func mergeSuggestions(first, second []string, limit int) []string {
-	if limit <= 0 {
-		return nil
-	}
-
-	out := make([]string, 0, limit)
-	seen := map[string]struct{}{}
-
-	appendUnique := func(items []string) {
-		for _, item := range items {
-			trimmed := strings.TrimSpace(item)
-			if trimmed == "" {
-				continue
-			}
-			key := strings.ToLower(trimmed)
-			if _, ok := seen[key]; ok {
-				continue
-			}
-			seen[key] = struct{}{}
-			out = append(out, trimmed)
-			if len(out) == limit {
-				return
-			}
-		}
-	}
-
-	appendUnique(first)
-	if len(out) < limit {
-		appendUnique(second)
-	}
-	return out
+	m.searchInput.SetSuggestions(rankSuggestions(prefix, m.history, m.historyMeta, m.queryStats, products, maxSuggestions, time.Now().UTC()))
 }
 
 func (m Model) hasSearchSuggestionMatch() bool {
@@ -873,21 +1700,68 @@ func (m *Model) cancelActiveSearch() {
 	m.searchCancel = nil
 }
 
+func (m *Model) cancelTrackedRefresh() {
+	if m.trackCancel == nil {
+		return
+	}
+	m.trackCancel()
+	m.trackCancel = nil
+}
+
+// cancelBackgroundSearches stops searches run outside the visible results.
+func (m *Model) cancelBackgroundSearches() {
+	m.cancelTrackedRefresh()
+	m.stopCategoryBatch()
+}
+
+// runStartupCode searches the -code argument once the catalog and learned
+// codes have loaded, so a known code resolves to its product first.
+func (m Model) runStartupCode() (tea.Model, tea.Cmd) {
+	if m.startupCode == "" || !m.catalogLoaded || !m.identifiersLoaded {
+		return m, nil
+	}
+	code := m.startupCode
+	m.startupCode = ""
+	m.searchInput.SetValue(code)
+	return m.startSearch(code, true)
+}
+
 func (m Model) startSearch(rawQuery string, addToHistory bool) (tea.Model, tea.Cmd) {
+	return m.runSearch(rawQuery, addToHistory, true)
+}
+
+// runSearch starts a search for rawQuery. With expand unset the keywords are
+// searched as typed, skipping product expansion and its rules.
+func (m Model) runSearch(rawQuery string, addToHistory, expand bool) (tea.Model, tea.Cmd) {
 	query := strings.TrimSpace(rawQuery)
 	if query == "" {
 		return m, nil
 	}
 
-	expandedQuery := query
-	if m.productIndex != nil {
-		expandedQuery = m.productIndex.Expand(query)
+	parsed, err := api.ParseQuery(query)
+	if err != nil {
+		// The search panel shows the validation error inline.
+		return m, nil
+	}
+	keywords := parsed.Keywords()
+	expansion := api.Expansion{Query: keywords, Expanded: keywords}
+	if expand && m.productIndex != nil && keywords != "" {
+		expansion = m.productIndex.Explain(keywords)
 	}
 
 	m.cancelActiveSearch()
 	ctx, cancel := context.WithCancel(context.Background())
 	m.searchCancel = cancel
 	m.searchGen++
+	events := make(chan tea.Msg, searchEventBuffer)
+	m.searchEvents = events
+	m.searchStreamed = false
+	m.searchProviders = m.searchProviders[:0:0]
+	if m.apiClient != nil {
+		for _, name := range m.apiClient.ConfiguredProviderNames() {
+			m.searchProviders = append(m.searchProviders, providerStatus{Name: name})
+		}
+	}
 
 	m.loading = true
 	m.loadingDots = 0
@@ -899,30 +1773,65 @@ func (m Model) startSearch(rawQuery string, addToHistory bool) (tea.Model, tea.C
 	m.warning = ""
 	m.err = nil
 	m.lastQuery = query
+	m.lastExpansion = expansion
+	m.lastSearchLiteral = !expand
+	m.pendingIdentifier = ""
+	var prepCmds []tea.Cmd
+	if expand && !expansion.Changed() && expansion.Rule == nil {
+		if _, ok := api.ClassifyIdentifier(keywords); ok {
+			m.pendingIdentifier = keywords
+		} else if api.LooksLikeGTIN(keywords) {
+			prepCmds = append(prepCmds, m.setStatusFlash("UPC/EAN check digit is wrong; searching the code as typed", 2500*time.Millisecond))
+		}
+	}
+	m.queryFilter = parsed.Filter()
+	m.productFilter = types.ResultFilter{}
+	req := parsed.Request(expansion.Expanded)
+	if product, ok := m.productIndex.Product(expansion.Expanded); ok && expand {
+		m.productFilter = product.TitleFilter()
+		req = product.Narrow(req)
+	}
+	m.resultFilter.Since = recencyCutoff(m.recencyDays, time.Now())
 	m.detailOpen = false
+	m.pendingQueryStat = ""
 	if addToHistory {
 		m.addToHistory(query, time.Now().UTC())
+		m.pendingQueryStat = query
 	}
 
-	var prepCmds []tea.Cmd
 	if addToHistory {
 		prepCmds = append(prepCmds, saveHistoryCmd(m.historyStore, m.historyEntries()))
 	}
-	return m, m.doSearch(ctx, expandedQuery, m.searchGen, prepCmds...)
+	return m, m.doSearch(ctx, req, m.searchGen, events, prepCmds...)
 }
 
-// doSearch creates a command to fetch search results.
-func (m Model) doSearch(ctx context.Context, query string, gen int, prepCmds ...tea.Cmd) tea.Cmd {
+// doSearch creates a command to fetch search results. Progress and
+// per-provider results are published on events, which is closed once the
+// merged response is ready.
+func (m Model) doSearch(ctx context.Context, req api.SearchRequest, gen int, events chan tea.Msg, prepCmds ...tea.Cmd) tea.Cmd {
 	client := m.apiClient
 	if client == nil {
 		client = api.NewEnvClient()
 	}
 
-	cmds := make([]tea.Cmd, 0, len(prepCmds)+2)
+	req.Depth = m.searchDepth
+	req.MaxRequests = m.searchMaxPages
+	req.Rules = m.parseRules
+	req.Market = m.market
+	req.MaxAge = time.Duration(m.recencyDays) * 24 * time.Hour
+	req.Progress = func(progress api.SearchProgress) {
+		publishSearchEvent(events, searchProgressMsg{Progress: progress, gen: gen})
+	}
+
+	cmds := make([]tea.Cmd, 0, len(prepCmds)+3)
 	cmds = append(cmds, prepCmds...)
 	cmds = append(cmds, m.spinner.Tick)
+	cmds = append(cmds, waitForSearchEvent(events))
 	cmds = append(cmds, func() tea.Msg {
-		response := client.SearchPricesContext(ctx, strings.TrimSpace(query))
+		response := client.StreamPrices(ctx, req, func(result api.ProviderResult) {
+			publishSearchEvent(events, providerResultsMsg{Result: result, gen: gen})
+		})
+		close(events)
 		return SearchResultsMsg{
 			Results:        response.Results,
 			Mode:           response.Mode,
@@ -936,13 +1845,37 @@ func (m Model) doSearch(ctx context.Context, query string, gen int, prepCmds ...
 	return tea.Batch(cmds...)
 }
 
+// publishSearchEvent queues an event without blocking the search goroutine.
+// The final SearchResultsMsg carries the full outcome, so dropping is safe.
+func publishSearchEvent(events chan<- tea.Msg, msg tea.Msg) {
+	select {
+	case events <- msg:
+	default:
+	}
+}
+
+// waitForSearchEvent delivers the next queued search event, if any.
+func waitForSearchEvent(events <-chan tea.Msg) tea.Cmd {
+	if events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
 func openURLCmd(url string) tea.Cmd {
 	return func() tea.Msg {
 		return openURLResultMsg{Err: openURL(url)}
 	}
 }
 
-// openURL opens a URL in the default browser.
+// validateOpenURL checks that a URL is safe to hand to the browser and
+// returns its canonical form without tracking parameters.
 func validateOpenURL(rawURL string) (*url.URL, error) {
 	trimmed := strings.TrimSpace(rawURL)
 	if trimmed == "" {
@@ -961,8 +1894,16 @@ func validateOpenURL(rawURL string) (*url.URL, error) {
 	if scheme != "http" && scheme != "https" {
 		return nil, fmt.Errorf("open URL: unsupported URL scheme %q", parsedURL.Scheme)
 	}
+	if parsedURL.User != nil {
+		// "https://ebay.com@evil.example" would open evil.example.
+		return nil, fmt.Errorf("open URL: credentials in URL %q", trimmed)
+	}
 
-	return parsedURL, nil
+	canonical, err := url.Parse(api.IdentifyListing(parsedURL.String()).URL)
+	if err != nil {
+		return parsedURL, nil
+	}
+	return canonical, nil
 }
 
 func openURL(rawURL string) error {
@@ -989,10 +1930,47 @@ func openURL(rawURL string) error {
 	return fmt.Errorf("open URL: unsupported platform %q", runtime.GOOS)
 }
 
+// mergeListings appends incoming listings whose identity is not already present.
+func mergeListings(existing, incoming []types.Listing) []types.Listing {
+	out := append([]types.Listing(nil), existing...)
+	seen := make(map[string]struct{}, len(existing)+len(incoming))
+	for _, listing := range existing {
+		if key := listing.Key(); key != "" {
+			seen[key] = struct{}{}
+		}
+	}
+	for _, listing := range incoming {
+		key := listing.Key()
+		if key != "" {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		out = append(out, listing)
+	}
+	return out
+}
+
+// toggleConditionFilter filters results to a condition family, or clears
+// the filter when that family is already selected.
+func (m *Model) toggleConditionFilter(family string) {
+	if strings.EqualFold(m.resultFilter.Condition, family) {
+		m.resultFilter.Condition = ""
+	} else {
+		m.resultFilter.Condition = family
+	}
+	m.applySortAndFilter()
+	m.resetResultsSelection()
+}
+
 func (m *Model) applySortAndFilter() {
-	filtered := types.ApplyFilter(m.rawResults, m.resultFilter)
-	m.results = types.SortResults(filtered, m.sortField, m.sortDirection)
-	m.extendedStats = idea.CalculateExtendedStats(m.results)
+	matched := types.ApplyFilter(m.rawResults, m.queryFilter)
+	kept := types.ApplyFilter(matched, m.productFilter)
+	m.productExcluded = len(matched) - len(kept)
+	filtered := types.ApplyFilter(kept, m.resultFilter)
+	m.results = types.SortByVariant(types.SortResults(filtered, m.sortField, m.sortDirection), m.groupBy)
+	m.extendedStats = idea.CalculateExtendedStatsWith(m.results, m.statsOptions())
 	m.stats = m.extendedStats.Statistics
 	if len(m.results) == 0 {
 		m.detailOpen = false
@@ -1000,6 +1978,18 @@ func (m *Model) applySortAndFilter() {
 	m.clampResultsOffset()
 }
 
+// statsOptions returns how the Statistics panel computes stats, so other
+// summaries of listings agree with it.
+func (m Model) statsOptions() idea.StatsOptions {
+	return idea.StatsOptions{
+		Now:          time.Now(),
+		IncludeParts: m.includeParts,
+		UnitPrices:   !m.listedPrices,
+		Bundles:      m.bundleMode,
+		GroupBy:      m.groupBy,
+	}
+}
+
 func (m *Model) resetResultsSelection() {
 	m.selectedIndex = 0
 	m.resultsOffset = 0
@@ -1030,6 +2020,7 @@ func (m Model) visibleResultRowsForList() int {
 	if m.filterBarActive {
 		rows--
 	}
+	rows -= len(m.expansionSummary())
 	if rows < 1 {
 		rows = 1
 	}
@@ -1059,6 +2050,74 @@ func (m Model) selectedListing() (types.Listing, bool) {
 	return m.results[m.selectedIndex], true
 }
 
+// selectedPickupCost returns the one-way distance to the selected listing
+// and the cost of driving there and back to collect it.
+func (m Model) selectedPickupCost() (float64, float64, bool) {
+	if m.pickupPerMile <= 0 {
+		return 0, 0, false
+	}
+	selected, ok := m.selectedListing()
+	if !ok {
+		return 0, 0, false
+	}
+	miles, ok := selected.DistanceFrom(m.resultFilter.Home)
+	if !ok {
+		return 0, 0, false
+	}
+	return miles, 2 * miles * m.pickupPerMile, true
+}
+
+// recencyWindows are the listing-age filters, in days; zero shows all ages.
+var recencyWindows = []int{0, 7, 30, 90}
+
+func nextRecencyWindow(current int) int {
+	for i, days := range recencyWindows {
+		if days == current {
+			return recencyWindows[(i+1)%len(recencyWindows)]
+		}
+	}
+	return recencyWindows[0]
+}
+
+// distanceWindows are the pickup distance filters, in miles; zero shows all.
+var distanceWindows = []int{0, 25, 50, 100, 250}
+
+func nextDistanceWindow(current int) int {
+	for i, miles := range distanceWindows {
+		if miles == current {
+			return distanceWindows[(i+1)%len(distanceWindows)]
+		}
+	}
+	return distanceWindows[0]
+}
+
+func recencyCutoff(days int, now time.Time) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -days)
+}
+
+// nextGroupBy cycles through the variant attributes present in the current
+// results, then back to ungrouped.
+func (m Model) nextGroupBy() types.VariantAttribute {
+	start := 0
+	for i, attr := range types.VariantAttributes {
+		if attr == m.groupBy {
+			start = i + 1
+			break
+		}
+	}
+	for _, attr := range types.VariantAttributes[start:] {
+		for _, listing := range m.rawResults {
+			if listing.Variant.Value(attr) != "" {
+				return attr
+			}
+		}
+	}
+	return types.VariantNone
+}
+
 func (m Model) nextFilterPlatform() string {
 	options := []string{"", "eBay", "Mercari", "Amazon", "Facebook", "Other"}
 	current := strings.ToLower(strings.TrimSpace(m.resultFilter.Platform))
@@ -1072,8 +2131,29 @@ func (m Model) nextFilterPlatform() string {
 	return options[(index+1)%len(options)]
 }
 
+// calcPlatforms lists the platforms with a fee schedule in the current market.
+func (m Model) calcPlatforms() []string {
+	schedule := types.FeeScheduleFor(m.market.Code)
+	options := make([]string, 0, len(schedule))
+	for _, platform := range []string{"eBay", "Mercari", "Amazon", "Facebook"} {
+		if _, ok := schedule[strings.ToLower(platform)]; ok {
+			options = append(options, platform)
+		}
+	}
+	return options
+}
+
+func (m Model) hasCalcPlatform(platform string) bool {
+	for _, option := range m.calcPlatforms() {
+		if strings.EqualFold(option, platform) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m Model) nextCalcPlatform() string {
-	options := []string{"eBay", "Mercari", "Amazon", "Facebook"}
+	options := m.calcPlatforms()
 	current := strings.ToLower(strings.TrimSpace(m.calcPlatform))
 	index := 0
 	for i, option := range options {
@@ -1105,6 +2185,23 @@ func saveHistoryCmd(store HistoryStore, entries []HistoryEntry) tea.Cmd {
 	}
 }
 
+func captureCorpusCmd(path string, entry api.CorpusEntry) tea.Cmd {
+	return func() tea.Msg {
+		return corpusCapturedMsg{Labeled: entry.Label != (api.CorpusLabel{}), Err: AppendCorpusEntry(path, entry)}
+	}
+}
+
+// scheduleTrackedRefresh fires the next background refresh of tracked
+// listings; a zero interval disables it.
+func scheduleTrackedRefresh(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return trackedRefreshTickMsg{}
+	})
+}
+
 func copyToClipboardCmd(value string) tea.Cmd {
 	return func() tea.Msg {
 		if err := clipboard.WriteAll(value); err != nil {
@@ -1175,11 +2272,20 @@ func (m Model) statsRevealTargetLines() int {
 			return 2
 		}
 		platformLines := len(stats.PlatformStats)
+		if len(stats.VariantStats) > 0 {
+			platformLines = len(stats.VariantStats)
+		}
 		if platformLines > 4 {
 			platformLines = 4
 		}
+		if stats.BundleStats.Count > 0 {
+			platformLines++
+		}
 		return platformLines + 2
 	default:
+		if m.extendedStats.DatedCount > 0 {
+			return 7
+		}
 		return 6
 	}
 }
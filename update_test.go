@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
 )
 
 type captureQueryProvider struct {
@@ -28,6 +33,10 @@ type cancelAwareProvider struct {
 	firstCancelCh  chan struct{}
 }
 
+type stubSearchProvider struct {
+	name string
+}
+
 type captureHistoryStore struct {
 	saved []HistoryEntry
 	err   error
@@ -50,8 +59,20 @@ func (p *captureQueryProvider) Configured() bool {
 	return true
 }
 
-func (p *captureQueryProvider) Search(_ context.Context, query string) ([]types.Listing, error) {
-	p.query = query
+func (p *captureQueryProvider) Search(_ context.Context, req api.SearchRequest) ([]types.Listing, error) {
+	p.query = req.Query
+	return []types.Listing{}, nil
+}
+
+func (p stubSearchProvider) Name() string {
+	return p.name
+}
+
+func (p stubSearchProvider) Configured() bool {
+	return true
+}
+
+func (p stubSearchProvider) Search(_ context.Context, _ api.SearchRequest) ([]types.Listing, error) {
 	return []types.Listing{}, nil
 }
 
@@ -63,7 +84,7 @@ func (p *cancelAwareProvider) Configured() bool {
 	return true
 }
 
-func (p *cancelAwareProvider) Search(ctx context.Context, query string) ([]types.Listing, error) {
+func (p *cancelAwareProvider) Search(ctx context.Context, req api.SearchRequest) ([]types.Listing, error) {
 	p.mu.Lock()
 	p.calls++
 	callNum := p.calls
@@ -87,7 +108,7 @@ func (p *cancelAwareProvider) Search(ctx context.Context, query string) ([]types
 			Condition: "Used",
 			Status:    "Active",
 			URL:       "https://example.com/item",
-			Title:     query,
+			Title:     req.Query,
 		},
 	}, nil
 }
@@ -1012,6 +1033,7 @@ func TestValidateOpenURL(t *testing.T) {
 		{name: "missing host", rawURL: "https:///missing-host", wantErr: true},
 		{name: "relative url", rawURL: "/relative/path", wantErr: true},
 		{name: "empty", rawURL: "   ", wantErr: true},
+		{name: "credentials", rawURL: "https://ebay.com@evil.example/itm/1", wantErr: true},
 	}
 
 	for _, tc := range tests {
@@ -1027,6 +1049,29 @@ func TestValidateOpenURL(t *testing.T) {
 	}
 }
 
+func TestValidateOpenURLReturnsCanonicalURL(t *testing.T) {
+	got, err := validateOpenURL("https://m.ebay.com/itm/Sony-PS5/256012345678?hash=item3b&_trksid=p2047675")
+	if err != nil {
+		t.Fatalf("expected valid URL, got %v", err)
+	}
+	if got.String() != "https://www.ebay.com/itm/256012345678" {
+		t.Fatalf("expected canonical eBay URL, got %q", got.String())
+	}
+}
+
+func TestMergeListingsDedupesByIdentity(t *testing.T) {
+	existing := []types.Listing{{ID: "ebay:256012345678", URL: "https://www.ebay.com/itm/256012345678", Price: 400}}
+	incoming := []types.Listing{
+		{ID: "ebay:256012345678", URL: "https://www.ebay.com/itm/256012345678", Price: 405},
+		{URL: "https://example.com/a", Price: 300},
+		{URL: "https://example.com/a", Price: 300},
+	}
+	got := mergeListings(existing, incoming)
+	if len(got) != 2 || got[0].Price != 400 {
+		t.Fatalf("expected one duplicate item and one duplicate URL dropped, got %+v", got)
+	}
+}
+
 func TestSaveHistoryCmdHandlesNilStore(t *testing.T) {
 	msg := saveHistoryCmd(nil, []HistoryEntry{{Query: "ps5"}})()
 	result, ok := msg.(historySavedMsg)
@@ -1174,6 +1219,114 @@ func TestStartingNewSearchCancelsPreviousAndIgnoresStaleResults(t *testing.T) {
 	}
 }
 
+func TestSearchProgressUpdatesLoadingText(t *testing.T) {
+	m := newTestModel()
+	m.searchInput.SetValue("switch")
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.loading {
+		t.Fatal("expected search to be loading")
+	}
+
+	updated, cmd := m.Update(searchProgressMsg{
+		Progress: api.SearchProgress{Provider: "Brave", Listings: 42, Page: 3},
+		gen:      m.searchGen,
+	})
+	um := updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected progress handler to keep listening for events")
+	}
+	if got := um.searchProgressText(); got != "42 listings · page 3" {
+		t.Fatalf("expected progress text, got %q", got)
+	}
+
+	stale, _ := um.Update(searchProgressMsg{
+		Progress: api.SearchProgress{Provider: "Brave", Listings: 5, Page: 1},
+		gen:      um.searchGen - 1,
+	})
+	if got := stale.(Model).searchProgressText(); got != "42 listings · page 3" {
+		t.Fatalf("expected stale progress to be ignored, got %q", got)
+	}
+}
+
+func TestProviderResultsStreamIntoModel(t *testing.T) {
+	m := newTestModel()
+	m.reduceMotion = true
+	m.apiClient = api.NewClient(
+		stubSearchProvider{name: "Brave"},
+		stubSearchProvider{name: "Tavily"},
+	)
+	m.searchInput.SetValue("switch")
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.searchProviders) != 2 || m.searchProviders[0].State != providerPending {
+		t.Fatalf("expected two pending providers, got %+v", m.searchProviders)
+	}
+
+	first := []types.Listing{
+		{Platform: "eBay", Price: 200, Condition: "Used", Status: "Active", URL: "https://ebay.com/1", Title: "Switch"},
+		{Platform: "eBay", Price: 220, Condition: "Used", Status: "Active", URL: "https://ebay.com/2", Title: "Switch"},
+	}
+	updated, cmd := m.Update(providerResultsMsg{
+		Result: api.ProviderResult{Provider: "Brave", Results: first},
+		gen:    m.searchGen,
+	})
+	um := updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected provider handler to keep listening for events")
+	}
+	if !um.loading {
+		t.Fatal("expected search to remain loading while providers are pending")
+	}
+	if len(um.results) != 2 || um.extendedStats.Count != 2 {
+		t.Fatalf("expected first batch to populate results, got %d results", len(um.results))
+	}
+	if got := um.providerStatusLine(); !strings.Contains(got, "Brave") || !strings.Contains(got, "✓2") || !strings.Contains(got, "…") {
+		t.Fatalf("expected Brave finished and Tavily pending, got %q", got)
+	}
+
+	second := []types.Listing{
+		first[0],
+		{Platform: "Mercari", Price: 180, Condition: "Used", Status: "Active", URL: "https://mercari.com/1", Title: "Switch"},
+	}
+	updated, _ = um.Update(providerResultsMsg{
+		Result: api.ProviderResult{Provider: "Tavily", Results: second},
+		gen:    um.searchGen,
+	})
+	um = updated.(Model)
+	if len(um.results) != 3 {
+		t.Fatalf("expected streamed batches to merge without duplicates, got %d", len(um.results))
+	}
+	if um.extendedStats.Min != 180 {
+		t.Fatalf("expected stats to include streamed batch, got min %.2f", um.extendedStats.Min)
+	}
+
+	stale, _ := um.Update(providerResultsMsg{
+		Result: api.ProviderResult{Provider: "Brave", Results: makeListings(5)},
+		gen:    um.searchGen - 1,
+	})
+	if got := len(stale.(Model).results); got != 3 {
+		t.Fatalf("expected stale provider results to be ignored, got %d", got)
+	}
+}
+
+func TestProviderFailureMarksStatus(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(stubSearchProvider{name: "Brave"})
+	m.searchInput.SetValue("switch")
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	updated, _ := m.Update(providerResultsMsg{
+		Result: api.ProviderResult{Provider: "Brave", Err: errors.New("boom")},
+		gen:    m.searchGen,
+	})
+	um := updated.(Model)
+	if um.searchProviders[0].State != providerFailed {
+		t.Fatalf("expected failed provider state, got %v", um.searchProviders[0].State)
+	}
+	if len(um.results) != 0 {
+		t.Fatalf("expected no results from failed provider, got %d", len(um.results))
+	}
+}
+
 func TestSortFilterStatsConsistency(t *testing.T) {
 	m := newTestModel()
 	m.rawResults = []types.Listing{
@@ -1231,3 +1384,1034 @@ func sendKey(t *testing.T, m Model, msg tea.KeyMsg) Model {
 	}
 	return um
 }
+
+func TestSearchQuerySyntaxFeedsProviderAndFilter(t *testing.T) {
+	provider := &captureQueryProvider{}
+	m := newTestModel()
+	m.productIndex = nil
+	m.apiClient = api.NewClient(provider)
+	m.searchInput.SetValue("switch -broken status:sold $100..$300")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	um := updated.(Model)
+	if !um.loading || cmd == nil {
+		t.Fatal("expected valid query syntax to start a search")
+	}
+	batchMsg, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("expected batch command message")
+	}
+	batchMsg[len(batchMsg)-1]()
+	if provider.query != "switch" {
+		t.Fatalf("expected provider keywords %q, got %q", "switch", provider.query)
+	}
+
+	updated, _ = um.Update(SearchResultsMsg{
+		Results: []types.Listing{
+			{Platform: "eBay", Price: 200, Status: "Sold", Title: "Switch OLED", URL: "https://ebay.com/1"},
+			{Platform: "eBay", Price: 150, Status: "Sold", Title: "Switch broken", URL: "https://ebay.com/2"},
+			{Platform: "eBay", Price: 350, Status: "Sold", Title: "Switch bundle", URL: "https://ebay.com/3"},
+			{Platform: "eBay", Price: 180, Status: "Active", Title: "Switch Lite", URL: "https://ebay.com/4"},
+		},
+		gen: um.searchGen,
+	})
+	um = updated.(Model)
+	if len(um.results) != 1 || um.results[0].Title != "Switch OLED" {
+		t.Fatalf("expected query filter to keep one listing, got %+v", um.results)
+	}
+}
+
+func TestInvalidQuerySyntaxShowsInlineError(t *testing.T) {
+	m := newTestModel()
+	m.searchInput.SetValue("switch cond:mint")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	um := updated.(Model)
+	if um.loading || cmd != nil {
+		t.Fatal("expected invalid query not to start a search")
+	}
+	if line := um.searchSyntaxLine(); !strings.Contains(line, "unknown cond") {
+		t.Fatalf("expected inline validation error, got %q", line)
+	}
+
+	um.searchInput.SetValue("switch oled")
+	if line := um.searchSyntaxLine(); line != "" {
+		t.Fatalf("expected no syntax line for plain keywords, got %q", line)
+	}
+}
+
+func TestMarketKeyCyclesMarketAndRerunsSearch(t *testing.T) {
+	m := newTestModel()
+	m.market = api.DefaultMarket()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m.calcPlatform = "Mercari"
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	if m.market.Code != "UK" {
+		t.Fatalf("expected market to advance to UK, got %q", m.market.Code)
+	}
+	if m.calcPlatform != "eBay" {
+		t.Fatalf("expected calc platform without a UK schedule to reset, got %q", m.calcPlatform)
+	}
+	if m.loading {
+		t.Fatal("expected no search without a previous query")
+	}
+
+	m.lastQuery = "switch"
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	um := updated.(Model)
+	if um.market.Code != "CA" || !um.loading || cmd == nil {
+		t.Fatalf("expected CA market with a re-run search, got market=%q loading=%v", um.market.Code, um.loading)
+	}
+	for _, option := range um.calcPlatforms() {
+		if option == "Mercari" {
+			t.Fatal("expected CA calculator platforms to omit Mercari")
+		}
+	}
+}
+
+func TestFilterAgeCyclesRecencyWindow(t *testing.T) {
+	m := newTestModel()
+	now := time.Now()
+	m.rawResults = []types.Listing{
+		{Platform: "eBay", Price: 100, URL: "https://ebay.com/1", Date: now.AddDate(0, 0, -3)},
+		{Platform: "eBay", Price: 120, URL: "https://ebay.com/2", Date: now.AddDate(0, 0, -20)},
+		{Platform: "eBay", Price: 140, URL: "https://ebay.com/3", Date: now.AddDate(0, 0, -60)},
+		{Platform: "eBay", Price: 160, URL: "https://ebay.com/4"},
+	}
+	m.applySortAndFilter()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+
+	// The undated listing is kept in every window.
+	wantCounts := []int{2, 3, 4, 4}
+	for _, want := range wantCounts {
+		m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		if len(m.results) != want {
+			t.Fatalf("window %dd: expected %d results, got %d", m.recencyDays, want, len(m.results))
+		}
+		if m.recencyDays == 7 && !strings.Contains(xansi.Strip(m.renderFilterBar()), "7d (1 undated)") {
+			t.Fatalf("expected the undated count in the filter bar, got %q", xansi.Strip(m.renderFilterBar()))
+		}
+	}
+	if m.recencyDays != 0 {
+		t.Fatalf("expected window to wrap back to all ages, got %d", m.recencyDays)
+	}
+}
+
+func TestFilterMilesCyclesPickupDistance(t *testing.T) {
+	m := newTestModel()
+	m.resultFilter.Home = types.Location{City: "Dallas", State: "TX", Lat: 32.7767, Lon: -96.7970}
+	m.rawResults = []types.Listing{
+		{Platform: "Facebook", Price: 100, URL: "https://facebook.com/1", Location: types.Location{City: "Fort Worth", State: "TX", Lat: 32.7555, Lon: -97.3308}},
+		{Platform: "Facebook", Price: 90, URL: "https://facebook.com/2", Location: types.Location{City: "Austin", State: "TX", Lat: 30.2672, Lon: -97.7431}},
+		{Platform: "eBay", Price: 120, URL: "https://ebay.com/3"},
+	}
+	m.applySortAndFilter()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+
+	// Fort Worth is about 31 miles and Austin about 180 miles from Dallas;
+	// the shipped listing always stays.
+	wantCounts := []int{1, 2, 2, 3, 3}
+	for _, want := range wantCounts {
+		m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+		if len(m.results) != want {
+			t.Fatalf("window %d mi: expected %d results, got %d", m.distanceMiles, want, len(m.results))
+		}
+	}
+	if m.distanceMiles != 0 {
+		t.Fatalf("expected distance filter to wrap back to off, got %d", m.distanceMiles)
+	}
+
+	view := xansi.Strip(m.renderResultsPanel(100, 20))
+	if !strings.Contains(view, "Miles") || !strings.Contains(view, "182") {
+		t.Fatalf("expected distance column, got %q", view)
+	}
+}
+
+func TestFilterMilesNeedsHome(t *testing.T) {
+	m := newTestModel()
+	m.rawResults = []types.Listing{{Platform: "eBay", Price: 100, URL: "https://ebay.com/1"}}
+	m.applySortAndFilter()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	if m.distanceMiles != 0 || !strings.Contains(m.statusFlash, "MRKTR_HOME") {
+		t.Fatalf("expected hint to set a home location, got %d miles, flash %q", m.distanceMiles, m.statusFlash)
+	}
+}
+
+func TestFilterPartsTogglesPartsInStats(t *testing.T) {
+	m := newTestModel()
+	m.rawResults = []types.Listing{
+		{Platform: "eBay", Condition: "Used", Price: 400, URL: "https://ebay.com/1"},
+		{Platform: "eBay", Condition: "Certified Refurbished", Price: 380, URL: "https://ebay.com/2"},
+		{Platform: "eBay", Condition: "For Parts", Price: 50, URL: "https://ebay.com/3"},
+	}
+	m.applySortAndFilter()
+	if m.stats.Count != 2 || m.stats.Min != 380 {
+		t.Fatalf("expected for-parts listing out of stats by default, got %+v", m.stats)
+	}
+	if len(m.results) != 3 {
+		t.Fatalf("expected for-parts listing to stay in results, got %d", len(m.results))
+	}
+
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if !m.includeParts || m.stats.Count != 3 || m.stats.Min != 50 {
+		t.Fatalf("expected x to include parts in stats, got include=%v stats=%+v", m.includeParts, m.stats)
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if len(m.results) != 1 || m.results[0].Condition != "Certified Refurbished" {
+		t.Fatalf("expected r to filter to the refurbished family, got %+v", m.results)
+	}
+}
+
+func TestGroupByCyclesPresentVariantAttributes(t *testing.T) {
+	m := newTestModel()
+	m.rawResults = []types.Listing{
+		{Platform: "eBay", Price: 1100, URL: "https://ebay.com/1", Variant: types.Variant{Storage: "1TB", Color: "Blue"}},
+		{Platform: "eBay", Price: 600, URL: "https://ebay.com/2", Variant: types.Variant{Storage: "128GB"}},
+		{Platform: "eBay", Price: 650, URL: "https://ebay.com/3", Variant: types.Variant{Storage: "128GB"}},
+	}
+	m.applySortAndFilter()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	if m.groupBy != types.VariantStorage {
+		t.Fatalf("expected storage grouping, got %q", m.groupBy)
+	}
+	if m.results[0].Variant.Storage != "128GB" || m.results[2].Variant.Storage != "1TB" {
+		t.Fatalf("expected results ordered by variant bucket, got %+v", m.results)
+	}
+	if len(m.extendedStats.VariantStats) != 2 {
+		t.Fatalf("expected per-variant stats, got %+v", m.extendedStats.VariantStats)
+	}
+	if !strings.Contains(m.resultsPanelTitle(), "by Storage") {
+		t.Fatalf("expected grouping in title, got %q", m.resultsPanelTitle())
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	if m.groupBy != types.VariantColor {
+		t.Fatalf("expected color grouping next, got %q", m.groupBy)
+	}
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	if m.groupBy != types.VariantNone || m.extendedStats.VariantStats != nil {
+		t.Fatalf("expected grouping to switch off after the last present attribute, got %q", m.groupBy)
+	}
+}
+
+func TestParseCostInput(t *testing.T) {
+	tests := []struct {
+		raw   string
+		cost  float64
+		units int
+	}{
+		{raw: "60", cost: 60, units: 1},
+		{raw: "60/5", cost: 60, units: 5},
+		{raw: " 60 / 5 ", cost: 60, units: 5},
+		{raw: "60/0", cost: 60, units: 1},
+		{raw: "abc/5", cost: 0, units: 1},
+	}
+	for _, tc := range tests {
+		cost, units := parseCostInput(tc.raw)
+		if cost != tc.cost || units != tc.units {
+			t.Fatalf("parseCostInput(%q): expected (%.2f, %d), got (%.2f, %d)", tc.raw, tc.cost, tc.units, cost, units)
+		}
+	}
+}
+
+func TestCalculatorShowsLotResaleProfit(t *testing.T) {
+	m := newTestModel()
+	m.rawResults = []types.Listing{
+		{Platform: "Mercari", Price: 20, URL: "https://mercari.com/1"},
+		{Platform: "Mercari", Price: 100, Quantity: 5, URL: "https://mercari.com/2"},
+	}
+	m.applySortAndFilter()
+	if m.stats.Average != 20 {
+		t.Fatalf("expected unit-priced average of 20, got %.2f", m.stats.Average)
+	}
+
+	m.calcPlatform = "Mercari"
+	m.costInput.SetValue("60/5")
+	m.cost, m.lotUnits = parseCostInput(m.costInput.Value())
+	view := xansi.Strip(m.renderCalculatorPanel(60, 16))
+	if !strings.Contains(view, "Lot of 5 @ $12.00 each") {
+		t.Fatalf("expected lot breakdown, got %q", view)
+	}
+	// Each unit nets 20 - 12 - 2 (10% fee) = 6, so the lot nets 30.
+	if !strings.Contains(view, "Lot Net @ Avg:") || !strings.Contains(view, "30.00") {
+		t.Fatalf("expected lot net of $30.00, got %q", view)
+	}
+}
+
+func TestCalculatorAddsPickupCostForSelectedListing(t *testing.T) {
+	m := newTestModel()
+	m.resultFilter.Home = types.Location{City: "Dallas", State: "TX", Lat: 32.7767, Lon: -96.7970}
+	m.pickupPerMile = 0.5
+	m.rawResults = []types.Listing{
+		{Platform: "Facebook", Price: 100, URL: "https://facebook.com/1", Location: types.Location{City: "Fort Worth", State: "TX", Lat: 32.7555, Lon: -97.3308}},
+	}
+	m.applySortAndFilter()
+	m.calcPlatform = "Facebook"
+	m.costInput.SetValue("50")
+	m.cost, m.lotUnits = parseCostInput(m.costInput.Value())
+
+	miles, pickup, ok := m.selectedPickupCost()
+	if !ok || pickup != 2*miles*0.5 {
+		t.Fatalf("expected round-trip pickup cost, got %.2f for %.1f miles (%v)", pickup, miles, ok)
+	}
+	view := xansi.Strip(m.renderCalculatorPanel(70, 16))
+	if !strings.Contains(view, "Pickup 62 mi round trip @ $0.50/mi: +$31.05") {
+		t.Fatalf("expected pickup line, got %q", view)
+	}
+}
+
+func TestFilterLotsTogglesListedPrices(t *testing.T) {
+	m := newTestModel()
+	m.rawResults = []types.Listing{
+		{Platform: "eBay", Price: 60, Quantity: 5, URL: "https://ebay.com/1"},
+		{Platform: "eBay", Price: 520, Bundle: true, URL: "https://ebay.com/2"},
+	}
+	m.applySortAndFilter()
+	if m.stats.Count != 1 || m.stats.Average != 12 || m.extendedStats.BundleStats.Count != 1 {
+		t.Fatalf("expected unit prices with bundles apart by default, got %+v", m.extendedStats)
+	}
+
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if m.stats.Average != 60 {
+		t.Fatalf("expected listed lot price after l, got %.2f", m.stats.Average)
+	}
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	if m.bundleMode != idea.BundlesInclude || m.stats.Count != 2 {
+		t.Fatalf("expected b to cycle to including bundles, got mode=%v count=%d", m.bundleMode, m.stats.Count)
+	}
+}
+
+func TestFormatListingAge(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{date: time.Time{}, want: "-"},
+		{date: now.Add(-5 * time.Hour), want: "5h"},
+		{date: now.AddDate(0, 0, -3), want: "3d"},
+		{date: now.AddDate(0, 0, -21), want: "3w"},
+		{date: now.AddDate(0, 0, -95), want: "3mo"},
+		{date: now.AddDate(-2, 0, 0), want: "2y"},
+	}
+	for _, tc := range tests {
+		if got := formatListingAge(tc.date, now); got != tc.want {
+			t.Fatalf("formatListingAge(%v): expected %q, got %q", tc.date, tc.want, got)
+		}
+	}
+}
+
+type captureTrackedStore struct {
+	saved []TrackedListing
+}
+
+func (s *captureTrackedStore) Load() ([]TrackedListing, error) {
+	return nil, nil
+}
+
+func (s *captureTrackedStore) Save(listings []TrackedListing) error {
+	s.saved = append([]TrackedListing(nil), listings...)
+	return nil
+}
+
+func TestDetailTrackKeyTogglesTracking(t *testing.T) {
+	m := newTestModel()
+	m.trackedStore = nil
+	m.focusedPanel = panelResults
+	m.lastQuery = "switch oled"
+	m.results = []types.Listing{{Platform: "eBay", Price: 250, Status: "Active", ID: "ebay:42", URL: "https://www.ebay.com/itm/42"}}
+	m.detailOpen = true
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if len(m.tracked) != 1 || m.tracked[0].Key != "ebay:42" || m.tracked[0].Query != "switch oled" {
+		t.Fatalf("expected selected listing to be tracked, got %+v", m.tracked)
+	}
+	if !strings.Contains(xansi.Strip(m.renderDetailOverlay(80)), "[t] untrack") {
+		t.Fatal("expected detail overlay to offer untracking")
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if len(m.tracked) != 0 {
+		t.Fatalf("expected second press to untrack, got %+v", m.tracked)
+	}
+}
+
+func TestSearchResultsRecordTrackedPriceDrop(t *testing.T) {
+	store := &captureTrackedStore{}
+	m := newTestModel()
+	m.historyStore = nil
+	m.trackedStore = store
+	listing := types.Listing{Platform: "eBay", Price: 250, Status: "Active", ID: "ebay:42", Title: "Switch OLED"}
+	m.tracked = []TrackedListing{newTrackedListing(listing, "switch oled", time.Now().UTC().Add(-time.Hour))}
+
+	listing.Price = 220
+	updated, cmd := m.Update(SearchResultsMsg{Results: []types.Listing{listing}, Mode: api.SearchModeLive})
+	um := updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected save command for tracked change")
+	}
+	events := um.tracked[0].Events
+	if len(events) != 2 || events[1].Kind != TrackEventPriceDrop || um.tracked[0].Price != 220 {
+		t.Fatalf("expected price drop on timeline, got %+v", um.tracked[0])
+	}
+	if len(m.tracked[0].Events) != 1 {
+		t.Fatal("expected previous model value to keep its timeline")
+	}
+	if !strings.Contains(um.statusFlash, "price drop") {
+		t.Fatalf("expected price drop flash, got %q", um.statusFlash)
+	}
+}
+
+func TestTrackedRefreshRecordsSoldStatus(t *testing.T) {
+	m := newTestModel()
+	m.trackedStore = nil
+	listing := types.Listing{Platform: "eBay", Price: 250, Status: "Active", ID: "ebay:42", Title: "Switch OLED"}
+	m.tracked = []TrackedListing{newTrackedListing(listing, "switch oled", time.Now().UTC().Add(-time.Hour))}
+	m.trackRefreshing = true
+
+	listing.Status = "Sold"
+	updated, _ := m.Update(trackedRefreshMsg{Results: []types.Listing{listing}})
+	um := updated.(Model)
+	if um.trackRefreshing {
+		t.Fatal("expected refresh to settle")
+	}
+	if um.tracked[0].Status != "Sold" || um.tracked[0].Events[1].Kind != TrackEventSold {
+		t.Fatalf("expected sold event, got %+v", um.tracked[0])
+	}
+}
+
+func TestTrackedRefreshSuggestsRelistWithoutRekeying(t *testing.T) {
+	m := newTestModel()
+	m.trackedStore = nil
+	listing := types.Listing{Platform: "eBay", Price: 250, Status: "Sold", ID: "ebay:42", Title: "Switch OLED"}
+	m.tracked = []TrackedListing{newTrackedListing(listing, "switch oled", time.Now().UTC().Add(-time.Hour))}
+	m.trackRefreshing = true
+
+	relist := types.Listing{Platform: "eBay", Price: 245, Status: "Active", ID: "ebay:43", Title: "Switch OLED"}
+	updated, _ := m.Update(trackedRefreshMsg{Results: []types.Listing{relist}})
+	m = updated.(Model)
+	if m.tracked[0].Key != "ebay:42" || m.tracked[0].PossibleRelist == nil || m.tracked[0].PossibleRelist.Key != "ebay:43" {
+		t.Fatalf("expected a possible relist on the untouched sold listing, got %+v", m.tracked[0])
+	}
+
+	m.focusedPanel = panelResults
+	m.trackedOpen = true
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	if m.tracked[0].Key != "ebay:43" || m.tracked[0].Events[len(m.tracked[0].Events)-1].Kind != TrackEventRelisted {
+		t.Fatalf("expected F to follow the relist, got %+v", m.tracked[0])
+	}
+}
+
+func TestQuitCancelsTrackedRefresh(t *testing.T) {
+	provider := &cancelAwareProvider{
+		firstStartedCh: make(chan struct{}),
+		firstCancelCh:  make(chan struct{}),
+	}
+	m := newTestModel()
+	m.apiClient = api.NewClient(provider)
+	m.trackedStore = nil
+	listing := types.Listing{Platform: "eBay", Price: 250, Status: "Active", ID: "ebay:42", Title: "Switch OLED"}
+	m.tracked = []TrackedListing{newTrackedListing(listing, "switch oled", time.Now().UTC())}
+	m.focusedPanel = panelResults
+	m.trackedOpen = true
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(Model)
+	if !m.trackRefreshing || cmd == nil {
+		t.Fatal("expected R to start a tracked refresh")
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	<-provider.firstStartedCh
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	select {
+	case <-provider.firstCancelCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected quitting to cancel the tracked refresh")
+	}
+	if msg, ok := (<-done).(trackedRefreshMsg); !ok || msg.Err == nil {
+		t.Fatalf("expected the canceled refresh to report an error, got %#v", msg)
+	}
+}
+
+func TestTrackedPanelOpensAndRendersTimeline(t *testing.T) {
+	m := newTestModel()
+	m.trackedStore = nil
+	m.width = 120
+	m.height = 40
+	m.focusedPanel = panelStats
+	m = m.updateFocus()
+	listing := types.Listing{Platform: "eBay", Price: 250, Status: "Active", ID: "ebay:42", Title: "Switch OLED"}
+	tracked := newTrackedListing(listing, "switch oled", time.Now().UTC().Add(-time.Hour))
+	listing.Price = 230
+	tracked.Observe(listing, time.Now().UTC())
+	m.tracked = []TrackedListing{tracked}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	if !m.trackedOpen || m.focusedPanel != panelResults {
+		t.Fatalf("expected tracked panel in focused results, got open=%v panel=%d", m.trackedOpen, m.focusedPanel)
+	}
+	view := xansi.Strip(m.View())
+	for _, want := range []string{"Tracked (1)", "Switch OLED", "price drop", "-20.00"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected tracked panel to contain %q", want)
+		}
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.trackedOpen {
+		t.Fatal("expected esc to close tracked panel")
+	}
+}
+
+func TestDetailDigitPicksPriceCandidateAndAppliesToLaterSearches(t *testing.T) {
+	m := newTestModel()
+	m.correctionStore = nil
+	m.historyStore = nil
+	m.focusedPanel = panelResults
+	listing := types.Listing{
+		Platform: "eBay",
+		Price:    15,
+		Status:   "Active",
+		URL:      "https://www.ebay.com/itm/1",
+		ID:       "ebay:1",
+		PriceCandidates: []types.PriceCandidate{
+			{Amount: 15, Source: "snippet", Pattern: "symbol-prefix", Context: "shipping $15"},
+			{Amount: 120, Source: "snippet", Pattern: "context", Context: "price 120"},
+		},
+	}
+	m.rawResults = []types.Listing{listing}
+	m.applySortAndFilter()
+	m.detailOpen = true
+
+	out := xansi.Strip(m.renderDetailOverlay(100))
+	if !strings.Contains(out, "Price candidates:") || !strings.Contains(out, "shipping $15") {
+		t.Fatalf("expected candidates in detail view, got:\n%s", out)
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	if m.results[0].Price != 120 || !m.detailOpen {
+		t.Fatalf("expected picked price in open detail view, got %+v", m.results[0])
+	}
+	correction, ok := m.priceCorrections[listing.URL]
+	if !ok || correction.Parsed != 15 {
+		t.Fatalf("expected correction recording parsed price, got %+v", m.priceCorrections)
+	}
+
+	updated, _ := m.Update(SearchResultsMsg{Results: []types.Listing{listing}, Mode: api.SearchModeLive})
+	um := updated.(Model)
+	if um.results[0].Price != 120 {
+		t.Fatalf("expected correction applied to later search, got %v", um.results[0].Price)
+	}
+
+	um.detailOpen = true
+	um = sendKey(t, um, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	if _, ok := um.priceCorrections[listing.URL]; ok || um.results[0].Price != 15 {
+		t.Fatalf("expected picking the parsed price to clear the correction, got %+v", um.results[0])
+	}
+}
+
+func TestDetailSetsConditionAndStatusCorrections(t *testing.T) {
+	m := newTestModel()
+	m.correctionStore = nil
+	m.historyStore = nil
+	m.focusedPanel = panelResults
+	listing := types.Listing{
+		Platform:            "eBay",
+		Price:               15,
+		Condition:           types.ConditionUsed,
+		ConditionConfidence: 0.6,
+		Status:              "Active",
+		URL:                 "https://www.ebay.com/itm/1",
+		ID:                  "ebay:1",
+		PriceCandidates: []types.PriceCandidate{
+			{Amount: 15, Source: "snippet", Pattern: "symbol-prefix"},
+			{Amount: 120, Source: "snippet", Pattern: "context"},
+		},
+	}
+	m.rawResults = []types.Listing{listing}
+	m.applySortAndFilter()
+	m.detailOpen = true
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'O'}})
+	want := nextCondition(types.ConditionUsed)
+	if got := m.results[0]; got.Condition != want || got.Status != "Sold" || !m.detailOpen {
+		t.Fatalf("expected corrected condition and status in the open detail view, got %+v", got)
+	}
+	correction := m.priceCorrections[listing.URL]
+	if correction.Condition != want || correction.Status != "Sold" || correction.Price != 0 {
+		t.Fatalf("expected condition and status saved without a price, got %+v", correction)
+	}
+	if view := xansi.Strip(m.renderDetailOverlay(100)); !strings.Contains(view, want+" (corrected)") || !strings.Contains(view, "Sold (corrected)") {
+		t.Fatalf("expected corrected fields marked in the detail view, got:\n%s", view)
+	}
+
+	// Picking and resetting a price keeps the other corrections.
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	correction = m.priceCorrections[listing.URL]
+	if correction.Price != 0 || correction.Condition != want || m.results[0].Price != 15 {
+		t.Fatalf("expected the price reset to keep the condition, got %+v", correction)
+	}
+
+	updated, _ := m.Update(SearchResultsMsg{Results: []types.Listing{listing}, Mode: api.SearchModeLive})
+	um := updated.(Model)
+	if got := um.results[0]; got.Condition != want || got.Status != "Sold" || got.Price != 15 {
+		t.Fatalf("expected corrections applied to later search, got %+v", got)
+	}
+}
+
+func TestCatalogPromptAddsQueryAndReloadsIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	m := newTestModel()
+	m.userCatalogPath = path
+	m.catalogPaths = []string{path}
+	m.catalogIndexPath = filepath.Join(t.TempDir(), "catalog-index.bin")
+	m.lastQuery = "pyrex 443 primary bowl"
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	if !m.catalogPromptOpen || m.catalogQuery != "pyrex 443 primary bowl" {
+		t.Fatalf("expected catalog prompt for the last query, got open=%v query=%q", m.catalogPromptOpen, m.catalogQuery)
+	}
+	// Keys such as q go to the prompt instead of quitting.
+	for _, r := range "pyrex 443, pyrex primary q" {
+		m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if view := xansi.Strip(m.renderResultsPanel(80, 12)); !strings.Contains(view, "Add to Catalog") {
+		t.Fatalf("expected catalog prompt in results panel, got %q", view)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.catalogPromptOpen || cmd == nil {
+		t.Fatal("expected enter to close the prompt and save")
+	}
+	saved, ok := cmd().(catalogSavedMsg)
+	if !ok || saved.Err != nil {
+		t.Fatalf("expected catalog save, got %#v", saved)
+	}
+
+	entries, entryErrs, err := api.LoadCatalogFile(path)
+	if err != nil || len(entryErrs) != 0 || len(entries) != 1 {
+		t.Fatalf("expected one saved entry, got %+v %v %v", entries, entryErrs, err)
+	}
+	if got := entries[0].Synonyms; len(got) != 2 || got[1] != "pyrex primary q" {
+		t.Fatalf("expected synonyms from the prompt, got %v", got)
+	}
+
+	loaded, ok := loadCatalogCmd(m.catalogIndexPath, m.catalogPaths, true)().(catalogLoadedMsg)
+	if !ok {
+		t.Fatal("expected catalog reload message")
+	}
+	updated, _ = m.Update(loaded)
+	m = updated.(Model)
+	if got := m.productIndex.Expand("pyrex 443"); got != "pyrex 443 primary bowl" {
+		t.Fatalf("expected reloaded index to expand the new product, got %q", got)
+	}
+	if m.catalogStamp == "" {
+		t.Fatal("expected catalog stamp to be recorded")
+	}
+}
+
+func TestCatalogWatchReloadsOnChangeAndReportsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	m := newTestModel()
+	m.catalogPaths = []string{path}
+	m.catalogIndexPath = filepath.Join(t.TempDir(), "catalog-index.bin")
+	m.catalogStamp = catalogStamp(m.catalogPaths)
+
+	if _, cmd := m.Update(catalogWatchTickMsg{Stamp: m.catalogStamp}); cmd == nil {
+		t.Fatal("expected watch to keep polling")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"synonyms": ["nameless"]}]`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	stamp := catalogStamp(m.catalogPaths)
+	if stamp == m.catalogStamp {
+		t.Fatal("expected stamp to change after the edit")
+	}
+
+	loaded := loadCatalogCmd(m.catalogIndexPath, m.catalogPaths, true)().(catalogLoadedMsg)
+	updated, _ := m.Update(loaded)
+	m = updated.(Model)
+	if !strings.Contains(m.warning, "catalog.json[0]: name is required") {
+		t.Fatalf("expected entry error in warning, got %q", m.warning)
+	}
+
+	if err := os.WriteFile(path, []byte(`[]`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	updated, _ = m.Update(loadCatalogCmd(m.catalogIndexPath, m.catalogPaths, true)())
+	m = updated.(Model)
+	if m.warning != "" {
+		t.Fatalf("expected fixed catalog to clear the warning, got %q", m.warning)
+	}
+}
+
+func TestExpandedSearchShowsRunnerUpsAndLiteralRerun(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.expansionStore = nil
+	next, _ := m.startSearch("ps5", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+
+	if !m.lastExpansion.Changed() || m.lastExpansion.Expanded != "PlayStation 5 Console" {
+		t.Fatalf("expected ps5 to be expanded, got %+v", m.lastExpansion)
+	}
+	view := xansi.Strip(m.renderResultsPanel(160, 14))
+	for _, want := range []string{`Searched for "PlayStation 5 Console" (expanded from "ps5")`, "Also: ", "L literal"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in results header, got %q", want, view)
+		}
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m.cancelActiveSearch()
+	if !m.lastSearchLiteral || m.lastExpansion.Expanded != "ps5" {
+		t.Fatalf("expected literal re-run of ps5, got literal=%v %+v", m.lastSearchLiteral, m.lastExpansion)
+	}
+	if view := xansi.Strip(m.renderResultsPanel(160, 14)); !strings.Contains(view, `Searched literally for "ps5"`) {
+		t.Fatalf("expected literal search note, got %q", view)
+	}
+}
+
+func TestExpansionRulesNeverAndPin(t *testing.T) {
+	store := NewFileExpansionRuleStoreAt(filepath.Join(t.TempDir(), "expansions.json"))
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.expansionStore = store
+	next, _ := m.startSearch("ps5", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	m.focusedPanel = panelResults
+	m = m.updateFocus()
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'X'}})
+	m.cancelActiveSearch()
+	if got := m.productIndex.Expand("PS5"); got != "PS5" {
+		t.Fatalf("expected never rule to stop expansion, got %q", got)
+	}
+	if m.lastExpansion.Rule == nil || !m.lastExpansion.Rule.Never || m.lastExpansion.Changed() {
+		t.Fatalf("expected search re-run without expansion, got %+v", m.lastExpansion)
+	}
+	if view := xansi.Strip(m.renderResultsPanel(160, 14)); !strings.Contains(view, `"ps5" is never expanded`) {
+		t.Fatalf("expected never-expanded note, got %q", view)
+	}
+
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	if !m.expansionPromptOpen || m.expansionPrompt.Value() != m.lastExpansion.Candidates[0].Name {
+		t.Fatalf("expected pin prompt with the best candidate, got open=%v %q", m.expansionPromptOpen, m.expansionPrompt.Value())
+	}
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	target := m.lastExpansion.Candidates[1].Name
+	if m.expansionPrompt.Value() != target {
+		t.Fatalf("expected tab to pick the runner-up %q, got %q", target, m.expansionPrompt.Value())
+	}
+	m = sendKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m.cancelActiveSearch()
+	if got := m.productIndex.Expand("ps5"); got != target {
+		t.Fatalf("expected pinned expansion to %q, got %q", target, got)
+	}
+	if view := xansi.Strip(m.renderResultsPanel(160, 14)); !strings.Contains(view, "pinned") {
+		t.Fatalf("expected pinned expansion in header, got %q", view)
+	}
+
+	if err := store.Save(m.expansionRules); err != nil {
+		t.Fatalf("save rules: %v", err)
+	}
+	rules, err := store.Load()
+	if err != nil || len(rules) != 1 || rules[0].Never || rules[0].Target != target {
+		t.Fatalf("expected the pin to replace the never rule, got %+v %v", rules, err)
+	}
+
+	// Rules survive a catalog reload.
+	loaded := catalogLoadedMsg{Index: api.NewProductIndex(), Reload: true}
+	updated, _ := m.Update(loaded)
+	if got := updated.(Model).productIndex.Expand("ps5"); got != target {
+		t.Fatalf("expected rules on the reloaded index, got %q", got)
+	}
+}
+
+func TestCatalogWatchSkipsTicksWhileReloading(t *testing.T) {
+	m := newTestModel()
+	m.catalogPaths = []string{filepath.Join(t.TempDir(), "catalog.json")}
+	m.catalogStamp = "old"
+
+	updated, cmd := m.Update(catalogWatchTickMsg{Stamp: "new"})
+	m = updated.(Model)
+	if !m.catalogReloading || cmd == nil {
+		t.Fatal("expected a changed catalog to start a reload")
+	}
+	if reload := m.reloadCatalogCmd(); reload != nil {
+		t.Fatal("expected no second reload while one is in flight")
+	}
+
+	updated, _ = m.Update(catalogLoadedMsg{Index: api.NewProductIndex(), Stamp: "new", Reload: true})
+	m = updated.(Model)
+	if m.catalogReloading {
+		t.Fatal("expected the finished reload to clear the flag")
+	}
+	if reload := m.reloadCatalogCmd(); reload == nil {
+		t.Fatal("expected a later change to reload again")
+	}
+}
+
+func TestUnknownCodeIsSearchedAsIsAndLearned(t *testing.T) {
+	store := NewFileLearnedIdentifierStoreAt(filepath.Join(t.TempDir(), "identifiers.json"))
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.identifierStore = store
+	next, _ := m.startSearch("036000291452", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if m.lastExpansion.Changed() || m.pendingIdentifier != "036000291452" {
+		t.Fatalf("expected unknown code to be searched as-is, got %+v pending=%q", m.lastExpansion, m.pendingIdentifier)
+	}
+
+	updated, _ := m.Update(SearchResultsMsg{gen: m.searchGen, Results: []types.Listing{
+		{Platform: "eBay", Price: 9, Title: "Kleenex Tissues 3 Pack 036000291452", URL: "https://www.ebay.com/itm/1"},
+		{Platform: "eBay", Price: 11, Title: "Kleenex Ultra Soft Tissues - eBay", URL: "https://www.ebay.com/itm/2"},
+	}})
+	m = updated.(Model)
+	if len(m.learnedIdentifiers) != 1 || m.learnedIdentifiers[0].Title != "Kleenex Tissues" {
+		t.Fatalf("expected code to be learned from titles, got %+v", m.learnedIdentifiers)
+	}
+
+	next, _ = m.startSearch("0036000291452", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if !m.lastExpansion.ByCode || m.lastExpansion.Expanded != "Kleenex Tissues" || m.pendingIdentifier != "" {
+		t.Fatalf("expected learned code to resolve, got %+v", m.lastExpansion)
+	}
+	if view := xansi.Strip(m.renderResultsPanel(160, 14)); !strings.Contains(view, `(looked up from code "0036000291452")`) {
+		t.Fatalf("expected code lookup in results header, got %q", view)
+	}
+
+	if err := store.Save(m.learnedIdentifiers); err != nil {
+		t.Fatalf("save learned identifiers: %v", err)
+	}
+	loaded, err := store.Load()
+	if err != nil || len(loaded) != 1 || loaded[0].Code != "036000291452" {
+		t.Fatalf("expected learned code round trip, got %+v %v", loaded, err)
+	}
+}
+
+func TestStartupCodeSearchesOnceCatalogAndLearnedCodesLoad(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.startupCode = "WH-1000XM5"
+
+	updated, _ := m.Update(catalogLoadedMsg{Index: api.NewProductIndex()})
+	m = updated.(Model)
+	if m.loading || m.startupCode == "" {
+		t.Fatal("expected the startup code to wait for learned codes")
+	}
+	updated, _ = m.Update(storeLoadedMsg[api.LearnedIdentifier]{Items: []api.LearnedIdentifier{{Code: "wh1000xm5", Title: "Sony WH-1000XM5 Headphones"}}})
+	m = updated.(Model)
+	m.cancelActiveSearch()
+	if !m.loading || m.startupCode != "" || m.searchInput.Value() != "WH-1000XM5" {
+		t.Fatalf("expected the startup code to be searched, got loading=%v code=%q", m.loading, m.startupCode)
+	}
+	if !m.lastExpansion.ByCode || m.lastExpansion.Expanded != "Sony WH-1000XM5 Headphones" {
+		t.Fatalf("expected the learned MPN to resolve, got %+v", m.lastExpansion)
+	}
+}
+
+func TestUnknownMPNIsLearned(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.identifierStore = nil
+	next, _ := m.startSearch("CFI-1215A", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if m.pendingIdentifier != "CFI-1215A" {
+		t.Fatalf("expected the MPN to be learned from results, got pending=%q", m.pendingIdentifier)
+	}
+}
+
+func TestRetailStatsAndImplausiblePriceFlag(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.expansionStore = nil
+	next, _ := m.startSearch("switch oled", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if m.lastExpansion.Expanded != "Nintendo Switch OLED" {
+		t.Fatalf("expected switch oled to expand to the catalog product, got %+v", m.lastExpansion)
+	}
+
+	updated, _ := m.Update(SearchResultsMsg{gen: m.searchGen, Results: []types.Listing{
+		{Platform: "eBay", Price: 280, Title: "Nintendo Switch OLED White", URL: "https://www.ebay.com/itm/1"},
+		{Platform: "eBay", Price: 4.99, Title: "Nintendo Switch OLED screen protector", URL: "https://www.ebay.com/itm/2"},
+		{Platform: "Mercari", Price: 300, Title: "Switch OLED Neon", URL: "https://www.mercari.com/us/item/m3"},
+	}})
+	m = updated.(Model)
+
+	lines := xansi.Strip(strings.Join(m.renderStatsSummaryLines(m.extendedStats, "", 72, 8), "\n"))
+	for _, want := range []string{"MSRP $349.99", "Med 80% of retail", "1 implausible", "released 2021"} {
+		if !strings.Contains(lines, want) {
+			t.Fatalf("expected %q in stats summary, got %q", want, lines)
+		}
+	}
+	if got := m.feeClass(); got != types.FeeClassElectronics {
+		t.Fatalf("expected electronics fees for the product, got %q", got)
+	}
+	m.reveal.Rows = len(m.results)
+	m.reveal.Revealing = false
+	if view := xansi.Strip(m.renderResultsPanel(160, 14)); !strings.Contains(view, "!$4.99") {
+		t.Fatalf("expected implausible price to be flagged, got %q", view)
+	}
+
+	// MSRP is in USD, so it is not compared with prices in other markets.
+	m.market, _ = api.LookupMarket("JP")
+	m.applySortAndFilter()
+	lines = xansi.Strip(strings.Join(m.renderStatsSummaryLines(m.extendedStats, "", 72, 8), "\n"))
+	if strings.Contains(lines, "MSRP") || strings.Contains(lines, "implausible") {
+		t.Fatalf("expected no retail line in a JPY market, got %q", lines)
+	}
+	if m.implausiblePrice(types.Listing{Price: 45000}) {
+		t.Fatal("expected yen prices not to be checked against a USD MSRP")
+	}
+	if _, ok := m.detailRetailLine(types.Listing{Price: 45000}); ok {
+		t.Fatal("expected no percent of retail in a JPY market")
+	}
+	m.market = api.DefaultMarket()
+
+	next, _ = m.startSearch("pyrex bowl", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if m.implausiblePrice(types.Listing{Price: 1}) || m.feeClass() != types.FeeClassStandard {
+		t.Fatal("expected no retail bounds or fee class without a catalog product")
+	}
+}
+
+func TestFrequentFreeTextQueryIsOfferedForCatalog(t *testing.T) {
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.queryStatStore = nil
+	m.historyStore = nil
+	m.userCatalogPath = filepath.Join(t.TempDir(), "catalog.json")
+	query := "corningware blue cornflower"
+	results := []types.Listing{{Platform: "eBay", Price: 25, Title: "Corningware Blue Cornflower 2qt", URL: "https://www.ebay.com/itm/1"}}
+
+	for i := 1; i <= promoteAfterSearches; i++ {
+		m.statusFlash = ""
+		next, _ := m.startSearch(query, true)
+		m = next.(Model)
+		m.cancelActiveSearch()
+		if m.lastExpansion.Changed() {
+			t.Fatalf("expected %q to stay free text, got %+v", query, m.lastExpansion)
+		}
+		updated, _ := m.Update(SearchResultsMsg{gen: m.searchGen, Results: results})
+		m = updated.(Model)
+		if stat, ok := m.queryStat(query); !ok || stat.Searches != i {
+			t.Fatalf("expected search %d to be counted, got %+v", i, stat)
+		}
+		offered := strings.Contains(m.statusFlash, "A to add it")
+		if offered != (i == promoteAfterSearches) {
+			t.Fatalf("search %d: unexpected promotion flash %q", i, m.statusFlash)
+		}
+	}
+
+	m.statusFlash = ""
+	next, _ := m.startSearch(query, true)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	updated, _ := m.Update(SearchResultsMsg{gen: m.searchGen, Results: results})
+	m = updated.(Model)
+	if strings.Contains(m.statusFlash, "A to add it") {
+		t.Fatalf("expected the query to be offered once per session, got %q", m.statusFlash)
+	}
+
+	updated, _ = m.Update(SearchResultsMsg{gen: m.searchGen, Results: results})
+	m = updated.(Model)
+	if stat, _ := m.queryStat(query); stat.Searches != promoteAfterSearches+1 {
+		t.Fatalf("expected repeated results for one search to count once, got %+v", stat)
+	}
+
+	m.searchInput.SetValue("corn")
+	m.refreshSearchSuggestions()
+	if got := m.searchInput.AvailableSuggestions(); len(got) == 0 || got[0] != query {
+		t.Fatalf("expected frequent query to lead suggestions, got %v", got)
+	}
+}
+
+func TestCatalogProductTermsNarrowSearchAndCountExcluded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(`[
+  {"name": "Ember Handheld X", "category": "Gaming", "synonyms": ["ember x"], "require": ["ember"], "exclude": ["case", "skin"]}
+]`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	m := newTestModel()
+	m.apiClient = api.NewClient(&captureQueryProvider{})
+	m.historyStore = nil
+	m.queryStatStore = nil
+	m.productIndex = api.NewProductIndex(path)
+
+	next, _ := m.startSearch("ember x", true)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	if m.lastExpansion.Expanded != "Ember Handheld X" || !slices.Equal(m.productFilter.Exclude, []string{"case", "skin"}) {
+		t.Fatalf("expected the expanded product's terms to apply, got %+v filter %+v", m.lastExpansion, m.productFilter)
+	}
+	if req, ok := m.backgroundRequest("ember x"); !ok || !slices.Equal(req.Exclude, []string{"case", "skin"}) {
+		t.Fatalf("expected background searches to exclude the product's terms, got %+v", req)
+	}
+
+	results := []types.Listing{
+		{Platform: "eBay", Price: 180, Title: "Ember Handheld X 512GB", URL: "https://www.ebay.com/itm/1"},
+		{Platform: "eBay", Price: 15, Title: "Ember Handheld X carrying case", URL: "https://www.ebay.com/itm/2"},
+		{Platform: "eBay", Price: 9, Title: "Vinyl skin for Ember X", URL: "https://www.ebay.com/itm/3"},
+		{Platform: "eBay", Price: 12, Title: "Handheld X grip", URL: "https://www.ebay.com/itm/4"},
+	}
+	updated, _ := m.Update(SearchResultsMsg{gen: m.searchGen, Results: results})
+	m = updated.(Model)
+	if len(m.results) != 1 || m.results[0].Title != "Ember Handheld X 512GB" || m.productExcluded != 3 {
+		t.Fatalf("expected 3 listings excluded by the product's terms, got %d kept, %d excluded", len(m.results), m.productExcluded)
+	}
+	if view := xansi.Strip(m.renderResultsPanel(120, 14)); !strings.Contains(view, "Results (3 excluded)") {
+		t.Fatalf("expected the excluded count in the results header, got %q", view)
+	}
+
+	// A literal search ignores the catalog product.
+	next, _ = m.startSearch("Ember Handheld X", false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	m.queryStatStore = nil
+	next, _ = m.runSearch("Ember Handheld X", false, false)
+	m = next.(Model)
+	m.cancelActiveSearch()
+	updated, _ = m.Update(SearchResultsMsg{gen: m.searchGen, Results: results})
+	m = updated.(Model)
+	if len(m.results) != 4 || m.productExcluded != 0 {
+		t.Fatalf("expected a literal search to keep every listing, got %d kept, %d excluded", len(m.results), m.productExcluded)
+	}
+}
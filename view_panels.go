@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"mrktr/api"
 	"mrktr/idea"
 	"mrktr/types"
 	"strings"
@@ -17,9 +18,89 @@ func (m Model) renderSearchPanel(width, height int) string {
 
 	content := m.searchInput.View()
 	if m.loading {
-		content += " " + m.spinner.View() + " Searching" + strings.Repeat(".", m.loadingDots)
+		content += " " + m.spinner.View() + " " + m.searchProgressText()
 	}
-	return renderPanel("/", "Search", content, width, height, active, flashActive)
+	if syntax := m.searchSyntaxLine(); syntax != "" {
+		content += "\n" + syntax
+	}
+	title := "Search"
+	if m.market.Code != "" && m.market.Code != api.DefaultMarketCode {
+		title = fmt.Sprintf("Search (%s · %s)", m.market.Code, m.market.Currency)
+	}
+	return renderPanel("/", title, content, width, height, active, flashActive)
+}
+
+// searchSyntaxLine echoes the query with its syntax highlighted, followed by
+// the first validation error. Plain keyword queries render nothing.
+func (m Model) searchSyntaxLine() string {
+	raw := m.searchInput.Value()
+	tokens := api.TokenizeQuery(raw)
+	plain := true
+	for _, token := range tokens {
+		if token.Kind != api.QueryTokenSpace && token.Kind != api.QueryTokenTerm {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, token := range tokens {
+		switch token.Kind {
+		case api.QueryTokenPhrase:
+			b.WriteString(successStyle.Render(token.Text))
+		case api.QueryTokenExclude:
+			b.WriteString(warningStyle.Render(token.Text))
+		case api.QueryTokenField, api.QueryTokenPrice:
+			b.WriteString(keyStyle.Render(token.Text))
+		case api.QueryTokenInvalid:
+			b.WriteString(dangerStyle.Underline(true).Render(token.Text))
+		default:
+			b.WriteString(token.Text)
+		}
+	}
+	if _, err := api.ParseQuery(raw); err != nil {
+		b.WriteString("  " + dangerStyle.Render("✗ "+err.Error()))
+	}
+	return b.String()
+}
+
+func (m Model) searchProgressText() string {
+	listings, page := 0, 0
+	for _, status := range m.searchProviders {
+		listings += status.Listings
+		page = max(page, status.Page)
+	}
+	if page == 0 {
+		return "Searching" + strings.Repeat(".", m.loadingDots)
+	}
+	noun := "listings"
+	if listings == 1 {
+		noun = "listing"
+	}
+	return fmt.Sprintf("%d %s · page %d", listings, noun, page)
+}
+
+// providerStatusLine summarizes each provider of the current search, e.g.
+// "Brave ✓18 · Tavily … · Firecrawl ✗".
+func (m Model) providerStatusLine() string {
+	if len(m.searchProviders) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(m.searchProviders))
+	for _, status := range m.searchProviders {
+		switch status.State {
+		case providerFinished:
+			parts = append(parts, status.Name+" "+successStyle.Render(fmt.Sprintf("✓%d", status.Listings)))
+		case providerFailed:
+			parts = append(parts, status.Name+" "+dangerStyle.Render("✗"))
+		default:
+			parts = append(parts, status.Name+" "+mutedStyle.Render("…"))
+		}
+	}
+	return strings.Join(parts, " · ")
 }
 
 func (m Model) renderResultsPanel(width, height int) string {
@@ -27,6 +108,23 @@ func (m Model) renderResultsPanel(width, height int) string {
 	flashActive := active && m.focusFlash.Active
 	title := m.resultsPanelTitle()
 
+	if m.trackedOpen {
+		content := m.renderTrackedOverlay(width, height)
+		return renderPanel("#", fmt.Sprintf("Tracked (%d)", len(m.tracked)), content, width, height, active, flashActive)
+	}
+
+	if m.browse.Open {
+		return renderPanel("#", m.categoryBrowserTitle(), m.renderCategoryOverlay(width, height), width, height, active, flashActive)
+	}
+
+	if m.catalogPromptOpen {
+		return renderPanel("#", title, m.renderCatalogPrompt(), width, height, active, flashActive)
+	}
+
+	if m.expansionPromptOpen {
+		return renderPanel("#", title, m.renderExpansionPrompt(), width, height, active, flashActive)
+	}
+
 	if m.detailOpen {
 		content := m.renderDetailOverlay(width)
 		return renderPanel("#", title, content, width, height, active, flashActive)
@@ -34,6 +132,9 @@ func (m Model) renderResultsPanel(width, height int) string {
 
 	if len(m.results) == 0 {
 		content := emptyStyle.Render("~ No results yet ~") + "\n" + keyStyle.Render("/") + keyDescStyle.Render(" search")
+		if expansion := m.renderExpansionLines(width); len(expansion) > 0 {
+			content = strings.Join(expansion, "\n") + "\n" + content
+		}
 		if m.filterBarActive {
 			content = m.renderFilterBar() + "\n" + content
 		}
@@ -65,14 +166,24 @@ func (m Model) renderResultsPanel(width, height int) string {
 		colPrice     = 10
 		colCondition = 10
 		colStatus    = 8
+		colAge       = 4
+		colVariant   = 10
+		colLot       = 4
+		colMiles     = 6
 	)
 	showCondition := width >= 90
 	showStatus := width >= 80
+	showAge := width >= 70 && resultsHaveDates(m.results)
+	showVariant := width >= 60 && m.groupBy != types.VariantNone
+	showLot := width >= 64 && resultsHaveLots(m.results)
+	showMiles := width >= 74 && resultsHaveDistances(m.results, m.resultFilter.Home)
+	now := time.Now()
 
 	var lines []string
 	if m.filterBarActive {
 		lines = append(lines, m.renderFilterBar())
 	}
+	lines = append(lines, m.renderExpansionLines(width)...)
 
 	header := fmt.Sprintf("%-*s %-*s %-*s %*s",
 		colCursor, "",
@@ -80,6 +191,18 @@ func (m Model) renderResultsPanel(width, height int) string {
 		colPlatform, m.sortColumnLabel("Platform", types.SortFieldPlatform),
 		colPrice, m.sortColumnLabel("Price", types.SortFieldPrice),
 	)
+	if showLot {
+		header += fmt.Sprintf(" %-*s", colLot, "Lot")
+	}
+	if showAge {
+		header += fmt.Sprintf(" %*s", colAge, "Age")
+	}
+	if showMiles {
+		header += fmt.Sprintf(" %*s", colMiles, "Miles")
+	}
+	if showVariant {
+		header += fmt.Sprintf("  %-*s", colVariant, truncate(m.groupBy.Label(), colVariant))
+	}
 	if showCondition {
 		header += fmt.Sprintf("  %-*s", colCondition, m.sortColumnLabel("Condition", types.SortFieldCondition))
 	}
@@ -91,6 +214,10 @@ func (m Model) renderResultsPanel(width, height int) string {
 	for i := start; i < end; i++ {
 		r := m.results[i]
 		price := fmt.Sprintf("$%.2f", r.Price)
+		implausible := m.implausiblePrice(r)
+		if implausible {
+			price = "!" + price
+		}
 		cond := truncate(r.Condition, colCondition)
 		status := r.Status
 		platformRaw := truncate(r.Platform, colPlatform)
@@ -102,12 +229,27 @@ func (m Model) renderResultsPanel(width, height int) string {
 
 		platformCell := platformStyleFor(r.Platform).Render(fmt.Sprintf("%-*s", colPlatform, platformRaw))
 		priceCell := priceStyle.Render(fmt.Sprintf("%*s", colPrice, price))
+		if implausible {
+			priceCell = warningStyle.Render(fmt.Sprintf("%*s", colPrice, price))
+		}
 		row := fmt.Sprintf("%-*s %*d %s %s",
 			colCursor, cursor,
 			colNum, i+1,
 			platformCell,
 			priceCell,
 		)
+		if showLot {
+			row += mutedStyle.Render(fmt.Sprintf(" %-*s", colLot, formatLotMarker(r)))
+		}
+		if showAge {
+			row += mutedStyle.Render(fmt.Sprintf(" %*s", colAge, formatListingAge(r.Date, now)))
+		}
+		if showMiles {
+			row += mutedStyle.Render(fmt.Sprintf(" %*s", colMiles, formatDistance(r, m.resultFilter.Home)))
+		}
+		if showVariant {
+			row += fmt.Sprintf("  %-*s", colVariant, truncate(types.VariantKey(r, m.groupBy), colVariant))
+		}
 		if showCondition {
 			conditionCell := fmt.Sprintf("  %-*s", colCondition, cond)
 			row += conditionCell
@@ -270,10 +412,7 @@ func (m Model) renderStatsSummaryLines(stats idea.ExtendedStatistics, sparkline
 			fmt.Sprintf("Avg: %s  Med: %s", avgValue, medianValue),
 			fmt.Sprintf("P25: %s  P75: %s", p25Value, p75Value),
 		}
-		if maxRows >= 6 {
-			lines = append(lines, fmt.Sprintf("StdDev: $%.2f  CoV: %.2f", stats.StdDev, stats.CoV))
-		}
-		return lines
+		return m.appendOptionalStatsLines(lines, stats, width, maxRows)
 	}
 
 	lines := []string{
@@ -283,12 +422,132 @@ func (m Model) renderStatsSummaryLines(stats idea.ExtendedStatistics, sparkline
 		fmt.Sprintf("Max: %s   P75: %s", maxValue, p75Value),
 		fmt.Sprintf("Avg: %s  Med: %s", avgValue, medianValue),
 	}
-	if maxRows >= 6 {
-		lines = append(lines, fmt.Sprintf("StdDev: $%.2f  CoV: %.2f", stats.StdDev, stats.CoV))
+	return m.appendOptionalStatsLines(lines, stats, width, maxRows)
+}
+
+// appendOptionalStatsLines adds the retail, spread and recency lines, in that
+// order, while rows remain.
+func (m Model) appendOptionalStatsLines(lines []string, stats idea.ExtendedStatistics, width, maxRows int) []string {
+	var optional []string
+	if retail, ok := m.retailStatsLine(stats, width); ok {
+		optional = append(optional, retail)
+	}
+	optional = append(optional, fmt.Sprintf("StdDev: $%.2f  CoV: %.2f", stats.StdDev, stats.CoV))
+	if stats.DatedCount > 0 {
+		optional = append(optional, recentStatsLine(stats))
+	}
+	for _, line := range optional {
+		if len(lines) >= maxRows {
+			break
+		}
+		lines = append(lines, line)
 	}
 	return lines
 }
 
+// retailStatsLine compares the median to the searched product's MSRP and
+// counts results priced outside its plausible range.
+func (m Model) retailStatsLine(stats idea.ExtendedStatistics, width int) (string, bool) {
+	product, ok := m.retailProduct()
+	if !ok {
+		return "", false
+	}
+	parts := []string{fmt.Sprintf("MSRP $%.2f", product.MSRP)}
+	if pct, ok := product.PercentOfRetail(stats.Median); ok {
+		parts = append(parts, fmt.Sprintf("Med %.0f%% of retail", pct))
+	}
+	if outliers := m.implausibleCount(); outliers > 0 {
+		parts = append(parts, fmt.Sprintf("%d implausible", outliers))
+	}
+	if released, ok := product.ReleaseDate(); ok {
+		parts = append(parts, fmt.Sprintf("released %d", released.Year()))
+	}
+	if product.Discontinued {
+		parts = append(parts, "discontinued")
+	}
+	return truncate(strings.Join(parts, " · "), width), true
+}
+
+// recentStatsLine summarizes recency-weighted prices for dated results.
+func recentStatsLine(stats idea.ExtendedStatistics) string {
+	return fmt.Sprintf("Recent: $%.2f avg  $%.2f med (%d dated)", stats.RecentAverage, stats.RecentMedian, stats.DatedCount)
+}
+
+func resultsHaveDates(listings []types.Listing) bool {
+	for _, listing := range listings {
+		if !listing.Date.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func resultsHaveDistances(listings []types.Listing, home types.Location) bool {
+	for _, listing := range listings {
+		if _, ok := listing.DistanceFrom(home); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDistance renders miles from home, or "-" when unknown.
+func formatDistance(listing types.Listing, home types.Location) string {
+	miles, ok := listing.DistanceFrom(home)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", miles)
+}
+
+func resultsHaveLots(listings []types.Listing) bool {
+	for _, listing := range listings {
+		if listing.Units() > 1 || listing.Bundle {
+			return true
+		}
+	}
+	return false
+}
+
+// formatLotMarker renders "×5" for a lot of five and "+" for a bundle.
+func formatLotMarker(listing types.Listing) string {
+	switch {
+	case listing.Units() > 1:
+		return fmt.Sprintf("×%d", listing.Units())
+	case listing.Bundle:
+		return "+"
+	default:
+		return ""
+	}
+}
+
+// formatListingAge renders a compact age such as "5h", "3d", "2w", "4mo" or "1y".
+func formatListingAge(date, now time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	age := now.Sub(date)
+	switch {
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", max(0, int(age.Hours())))
+	case age < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age < 60*24*time.Hour:
+		return fmt.Sprintf("%dw", int(age.Hours()/(24*7)))
+	case age < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo", int(age.Hours()/(24*30)))
+	default:
+		return fmt.Sprintf("%dy", int(age.Hours()/(24*365)))
+	}
+}
+
+func formatListingDate(date time.Time) string {
+	if date.IsZero() {
+		return "unknown"
+	}
+	return date.Format("Jan 2, 2006")
+}
+
 func (m Model) renderStatsDelta(delta float64) string {
 	if m.statsAnim.DeltaTicks <= 0 || math.Abs(delta) < 0.005 {
 		return ""
@@ -369,13 +628,26 @@ func (m Model) renderCalculatorPanel(width, height int) string {
 		labelStyle.Render("Your Cost:") + " $" + m.costInput.View(),
 		labelStyle.Render("Platform:") + " " + valueStyle.Render(m.calcPlatform) + " " + mutedStyle.Render("[p cycle]"),
 	}
+	if class := m.feeClass(); class != types.FeeClassStandard {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("Fees for %s", class)))
+	}
+	units := max(1, m.lotUnits)
+	totalCost := m.cost
+	if miles, pickup, ok := m.selectedPickupCost(); ok && m.cost > 0 {
+		totalCost += pickup
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("Pickup %.0f mi round trip @ $%.2f/mi: +$%.2f", 2*miles, m.pickupPerMile, pickup)))
+	}
+	unitCost := totalCost / float64(units)
+	if units > 1 {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("Lot of %d @ $%.2f each, sold singly", units, unitCost)))
+	}
 
 	if m.cost > 0 && len(m.results) > 0 {
 		lines = append(lines, separatorStyle.Render(strings.Repeat("╌", max(12, width-8))))
 
-		avgNet, avgFee, avgPct := types.CalculateNetProfit(m.cost, m.stats.Average, m.calcPlatform)
-		minNet, minFee, minPct := types.CalculateNetProfit(m.cost, m.stats.Min, m.calcPlatform)
-		maxNet, maxFee, maxPct := types.CalculateNetProfit(m.cost, m.stats.Max, m.calcPlatform)
+		avgNet, avgFee, avgPct := types.CalculateNetProfitFor(m.market.Code, m.feeClass(), unitCost, m.stats.Average, m.calcPlatform)
+		minNet, minFee, minPct := types.CalculateNetProfitFor(m.market.Code, m.feeClass(), unitCost, m.stats.Min, m.calcPlatform)
+		maxNet, maxFee, maxPct := types.CalculateNetProfitFor(m.market.Code, m.feeClass(), unitCost, m.stats.Max, m.calcPlatform)
 		maxProfitMagnitude := maxAbs(avgNet, minNet, maxNet)
 		barWidth := max(8, min(18, width/3))
 
@@ -401,12 +673,18 @@ func (m Model) renderCalculatorPanel(width, height int) string {
 		))
 		lines = append(lines, mutedStyle.Render(fmt.Sprintf("  after %s fees: -$%.2f", m.calcPlatform, maxFee)))
 
-		bestPlatform, bestNet := m.bestNetPlatform(m.cost, m.stats.Average)
+		bestPlatform, bestNet := m.bestNetPlatform(unitCost, m.stats.Average)
 		lines = append(lines, fmt.Sprintf("%s %s %s",
 			labelStyle.Render("Best Net @ Avg:"),
 			valueStyle.Render(bestPlatform),
 			formatProfit(bestNet),
 		))
+		if units > 1 {
+			lines = append(lines, fmt.Sprintf("%s %s",
+				labelStyle.Render("Lot Net @ Avg:"),
+				formatProfit(avgNet*float64(units)),
+			))
+		}
 	} else {
 		lines = append(lines, emptyStyle.Render("~ Enter cost to see profits ~"))
 	}
@@ -483,6 +761,9 @@ func (m Model) renderHelpBar() string {
 	if m.dataMode != "" {
 		help = renderModeBadge(m.dataMode) + "  " + help
 	}
+	if providers := m.providerStatusLine(); providers != "" {
+		help = providers + "  " + help
+	}
 	if m.warning != "" {
 		help = warningStyle.Render(m.warning) + "  " + help
 	}
@@ -505,6 +786,15 @@ func (m Model) resultsPanelTitle() string {
 	if strings.TrimSpace(m.resultFilter.Status) != "" {
 		parts = append(parts, m.resultFilter.Status)
 	}
+	if m.recencyDays > 0 {
+		parts = append(parts, fmt.Sprintf("last %dd", m.recencyDays))
+	}
+	if m.groupBy != types.VariantNone {
+		parts = append(parts, "by "+m.groupBy.Label())
+	}
+	if m.productExcluded > 0 {
+		parts = append(parts, fmt.Sprintf("%d excluded", m.productExcluded))
+	}
 	if len(parts) == 0 {
 		return "Results"
 	}
@@ -524,16 +814,55 @@ func (m Model) renderFilterBar() string {
 	if strings.TrimSpace(status) == "" {
 		status = "All"
 	}
+	age := "Any age"
+	if m.recencyDays > 0 {
+		age = fmt.Sprintf("%dd", m.recencyDays)
+		if undated := undatedCount(m.results); undated > 0 {
+			age += fmt.Sprintf(" (%d undated)", undated)
+		}
+	}
+	distance := "Any distance"
+	if m.distanceMiles > 0 {
+		distance = fmt.Sprintf("%d mi", m.distanceMiles)
+	}
+	prices := "Unit"
+	if m.listedPrices {
+		prices = "Listed"
+	}
+	bundles := "Bundles " + m.bundleMode.String()
+	parts := "Parts out"
+	if m.includeParts {
+		parts = "Parts in"
+	} else if m.extendedStats.PartsCount > 0 {
+		parts = fmt.Sprintf("Parts out (%d)", m.extendedStats.PartsCount)
+	}
 	return mutedStyle.Render(
 		fmt.Sprintf(
-			"[f] Filters  [p] %s  [n/u] %s  [a] %s  [esc] close",
+			"[f] Filters  [p] %s  [n/r/u] %s  [a] %s  [d] %s  [D] %s  [x] %s  [l] %s  [b] %s  [esc] close",
 			platform,
 			condition,
 			status,
+			age,
+			distance,
+			parts,
+			prices,
+			bundles,
 		),
 	)
 }
 
+// undatedCount counts listings the age filter kept because their date is
+// unknown.
+func undatedCount(listings []types.Listing) int {
+	count := 0
+	for _, listing := range listings {
+		if listing.Date.IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
 func (m Model) sortColumnLabel(label string, field types.SortField) string {
 	if m.sortField != field {
 		return label
@@ -544,6 +873,76 @@ func (m Model) sortColumnLabel(label string, field types.SortField) string {
 	return label + " ▲"
 }
 
+// expansionSummary describes how the last search was expanded: the query
+// searched, then the runner-up products with their scores and the keys that
+// undo or pin the expansion. It is empty when nothing was expanded.
+func (m Model) expansionSummary() []string {
+	e := m.lastExpansion
+	switch {
+	case m.lastQuery == "" || e.Query == "":
+		return nil
+	case m.lastSearchLiteral:
+		return []string{fmt.Sprintf("Searched literally for %q · L to expand", e.Query)}
+	case e.Rule != nil && e.Rule.Never:
+		return []string{fmt.Sprintf("%q is never expanded · X to allow", e.Query)}
+	case !e.Changed():
+		return nil
+	}
+
+	first := fmt.Sprintf("Searched for %q (expanded from %q)", e.Expanded, e.Query)
+	if e.ByCode {
+		first = fmt.Sprintf("Searched for %q (looked up from code %q)", e.Expanded, e.Query)
+	}
+	if e.Rule != nil {
+		first += " · pinned"
+	}
+	var others []string
+	for _, candidate := range e.Candidates {
+		if candidate.Name != e.Expanded {
+			others = append(others, fmt.Sprintf("%s %.2f", candidate.Name, candidate.Score))
+		}
+	}
+	second := "L literal · X never expand · P pin"
+	if len(others) > 0 {
+		second = "Also: " + strings.Join(others, ", ") + " · " + second
+	}
+	return []string{first, second}
+}
+
+func (m Model) renderExpansionLines(width int) []string {
+	summary := m.expansionSummary()
+	lines := make([]string, 0, len(summary))
+	for i, line := range summary {
+		line = truncate(sanitizeDisplayText(line), max(10, width-4))
+		if i == 0 {
+			lines = append(lines, labelStyle.Render(line))
+			continue
+		}
+		lines = append(lines, mutedStyle.Render(line))
+	}
+	return lines
+}
+
+func (m Model) renderExpansionPrompt() string {
+	lines := []string{
+		activeTitleStyle.Render("Pin Expansion"),
+		fmt.Sprintf("%s %s", labelStyle.Render("Query:"), sanitizeDisplayText(m.lastExpansion.Query)),
+		fmt.Sprintf("%s %s", labelStyle.Render("Always expand to:"), m.expansionPrompt.View()),
+		mutedStyle.Render("[tab] next candidate  [enter] save  [esc] cancel"),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderCatalogPrompt() string {
+	lines := []string{
+		activeTitleStyle.Render("Add to Catalog"),
+		fmt.Sprintf("%s %s", labelStyle.Render("Product:"), sanitizeDisplayText(m.catalogQuery)),
+		fmt.Sprintf("%s %s", labelStyle.Render("Synonyms:"), m.catalogPrompt.View()),
+		mutedStyle.Render("[enter] save  [esc] cancel"),
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m Model) renderDetailOverlay(width int) string {
 	selected, ok := m.selectedListing()
 	if !ok {
@@ -552,8 +951,17 @@ func (m Model) renderDetailOverlay(width int) string {
 
 	title := sanitizeDisplayText(selected.Title)
 	platform := sanitizeDisplayText(selected.Platform)
+	correction := m.priceCorrections[strings.TrimSpace(selected.URL)]
 	condition := sanitizeDisplayText(selected.Condition)
+	if correction.Condition != "" {
+		condition += mutedStyle.Render(" (corrected)")
+	} else if selected.ConditionConfidence > 0 {
+		condition += mutedStyle.Render(fmt.Sprintf(" (%.0f%% confidence)", selected.ConditionConfidence*100))
+	}
 	status := sanitizeDisplayText(selected.Status)
+	if correction.Status != "" {
+		status += mutedStyle.Render(" (corrected)")
+	}
 	urlText := sanitizeDisplayText(selected.URL)
 
 	urlWidth := max(16, width-14)
@@ -562,22 +970,352 @@ func (m Model) renderDetailOverlay(width int) string {
 		separatorStyle.Render(strings.Repeat("╌", max(12, width-8))),
 		fmt.Sprintf("%s %s", labelStyle.Render("Title:"), title),
 		fmt.Sprintf("%s %s", labelStyle.Render("Platform:"), platform),
-		fmt.Sprintf("%s $%.2f", labelStyle.Render("Price:"), selected.Price),
+		fmt.Sprintf("%s %s", labelStyle.Render("Price:"), m.detailPriceText(selected)),
 		fmt.Sprintf("%s %s", labelStyle.Render("Condition:"), condition),
+	}
+	if line, ok := m.detailRetailLine(selected); ok {
+		lines = append(lines, line)
+	}
+	if selected.Units() > 1 {
+		lines = append(lines, fmt.Sprintf("%s %d units @ $%.2f each", labelStyle.Render("Lot:"), selected.Units(), selected.UnitPrice()))
+	}
+	if selected.Bundle {
+		lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render("Bundle:"), "sold with extras"))
+	}
+	if !selected.Variant.IsZero() {
+		lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render("Variant:"), sanitizeDisplayText(selected.Variant.String())))
+	}
+	if !selected.Location.IsZero() {
+		location := sanitizeDisplayText(selected.Location.String())
+		if miles, ok := selected.DistanceFrom(m.resultFilter.Home); ok {
+			location += mutedStyle.Render(fmt.Sprintf(" (%.0f mi from home)", miles))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render("Location:"), location))
+	}
+	lines = append(lines,
 		fmt.Sprintf("%s %s", labelStyle.Render("Status:"), status),
+		fmt.Sprintf("%s %s", labelStyle.Render("Date:"), formatListingDate(selected.Date)),
 		fmt.Sprintf("%s %s", labelStyle.Render("URL:"), truncate(urlText, urlWidth)),
+	)
+	lines = append(lines, m.renderPriceCandidates(selected, width)...)
+	trackHint := "[t] track"
+	if i := trackedIndexOf(m.tracked, selected.Key()); i >= 0 {
+		tracked := m.tracked[i]
+		since := tracked.Events[0].Time
+		lines = append(lines, fmt.Sprintf("%s since %s, %d changes", labelStyle.Render("Tracked:"), formatListingDate(since), len(tracked.Events)-1))
+		trackHint = "[t] untrack"
+	}
+	lines = append(lines,
+		separatorStyle.Render(strings.Repeat("╌", max(12, width-8))),
+		mutedStyle.Render("[enter] open in browser  "+pickHint(selected)+trackHint+"  [C/O] condition/status  [w] save to corpus  [esc] back"),
+	)
+	return strings.Join(lines, "\n")
+}
+
+// maxPriceCandidateRows is how many candidates the detail view lists; one
+// digit key picks each.
+const maxPriceCandidateRows = 9
+
+func (m Model) detailPriceText(listing types.Listing) string {
+	text := fmt.Sprintf("$%.2f", listing.Price)
+	if correction, ok := m.priceCorrections[strings.TrimSpace(listing.URL)]; ok && correction.Price > 0 {
+		text += mutedStyle.Render(fmt.Sprintf(" (corrected, parsed $%.2f)", correction.Parsed))
+	}
+	return text
+}
+
+// detailRetailLine shows the listing's price as a share of the searched
+// product's MSRP and warns when it falls outside the plausible range.
+func (m Model) detailRetailLine(listing types.Listing) (string, bool) {
+	product, ok := m.retailProduct()
+	if !ok {
+		return "", false
+	}
+	pct, ok := product.PercentOfRetail(listing.UnitPrice())
+	if !ok {
+		return "", false
+	}
+	text := fmt.Sprintf("%.0f%% of $%.2f MSRP", pct, product.MSRP)
+	if m.implausiblePrice(listing) {
+		low, high, _ := product.PlausiblePriceRange()
+		text += " " + warningStyle.Render(fmt.Sprintf("(outside plausible $%.2f-$%.2f; check the parsed price)", low, high))
+	}
+	return fmt.Sprintf("%s %s", labelStyle.Render("Retail:"), text), true
+}
+
+// renderPriceCandidates lists the amounts the parser considered, marking the
+// one used as the listing's price.
+func (m Model) renderPriceCandidates(listing types.Listing, width int) []string {
+	if len(listing.PriceCandidates) < 2 {
+		return nil
+	}
+
+	lines := []string{labelStyle.Render("Price candidates:")}
+	for i, candidate := range listing.PriceCandidates {
+		if i == maxPriceCandidateRows {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("  +%d more", len(listing.PriceCandidates)-i)))
+			break
+		}
+		marker := " "
+		if candidate.Amount == listing.Price {
+			marker = "●"
+		}
+		head := fmt.Sprintf("  %d %s %10s  %-7s %-13s ", i+1, marker, fmt.Sprintf("$%.2f", candidate.Amount), candidate.Source, candidate.Pattern)
+		context := truncate(sanitizeDisplayText(candidate.Context), max(8, width-lipgloss.Width(head)-6))
+		line := head + mutedStyle.Render(context)
+		if marker != " " {
+			line = priceStyle.Render(head) + mutedStyle.Render(context)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func pickHint(listing types.Listing) string {
+	if len(listing.PriceCandidates) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("[1-%d] pick price  ", min(len(listing.PriceCandidates), maxPriceCandidateRows))
+}
+
+// renderTrackedOverlay lists tracked listings with the selected one's timeline.
+func (m Model) renderTrackedOverlay(width, height int) string {
+	hint := "[j/k] select  [enter] open  [t] untrack  [R] refresh  [esc] back"
+	if m.trackRefreshing {
+		hint = "refreshing tracked listings…  " + hint
+	}
+	if len(m.tracked) == 0 {
+		return emptyStyle.Render("~ No tracked listings ~") + "\n" +
+			mutedStyle.Render("Press [t] in a listing's detail view to track it")
+	}
+
+	const (
+		colPlatform = 9
+		colPrice    = 10
+		colStatus   = 7
+	)
+	titleWidth := max(8, width-(colPlatform+colPrice+colStatus+10))
+	listRows := min(len(m.tracked), max(1, height/3))
+	start := 0
+	if m.trackedIndex >= listRows {
+		start = m.trackedIndex - listRows + 1
+	}
+
+	lines := make([]string, 0, height)
+	for i := start; i < min(len(m.tracked), start+listRows); i++ {
+		t := m.tracked[i]
+		cursor := " "
+		if i == m.trackedIndex {
+			cursor = "▸"
+		}
+		row := fmt.Sprintf("%s %-*s %-*s %*s %-*s",
+			cursor,
+			colPlatform, truncate(sanitizeDisplayText(t.Platform), colPlatform),
+			titleWidth, truncate(sanitizeDisplayText(t.Title), titleWidth),
+			colPrice, fmt.Sprintf("$%.2f", t.Price),
+			colStatus, truncate(sanitizeDisplayText(t.Status), colStatus),
+		)
+		if i == m.trackedIndex {
+			row = selectedStyle.Render(row)
+		} else {
+			row = rowStyle.Render(row)
+		}
+		lines = append(lines, row)
+	}
+
+	selected := m.tracked[m.trackedIndex]
+	lines = append(lines,
 		separatorStyle.Render(strings.Repeat("╌", max(12, width-8))),
-		mutedStyle.Render("[enter] open in browser  [esc] back"),
+		fmt.Sprintf("%s last seen %s", labelStyle.Render("Timeline:"), formatRelativeTime(selected.LastSeen, time.Now())),
+	)
+	if relist := selected.PossibleRelist; relist != nil {
+		lines = append(lines, warningStyle.Render(fmt.Sprintf("Possible relist: $%.2f %s, seen %s  [F] follow  [I] ignore",
+			relist.Price, sanitizeDisplayText(relist.Status), formatRelativeTime(relist.Seen, time.Now()))))
+	}
+	timelineRows := max(1, height-len(lines)-1)
+	firstEvent := max(0, len(selected.Events)-timelineRows)
+	for i := firstEvent; i < len(selected.Events); i++ {
+		prevPrice := 0.0
+		if i > 0 {
+			prevPrice = selected.Events[i-1].Price
+		}
+		line := "  " + formatTrackEvent(selected.Events[i], prevPrice)
+		switch selected.Events[i].Kind {
+		case TrackEventPriceDrop:
+			line = successStyle.Render(line)
+		case TrackEventSold:
+			line = soldStyle.Render(line)
+		default:
+			line = mutedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, mutedStyle.Render(hint))
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) categoryBrowserTitle() string {
+	if m.browse.Category == "" {
+		return fmt.Sprintf("Categories (%d)", len(m.productIndex.Categories()))
+	}
+	return fmt.Sprintf("%s (%d)", m.browse.Category, len(m.productIndex.CategoryProducts(m.browse.Category)))
+}
+
+// renderCategoryOverlay lists the catalog categories, or the products of the
+// open category; once the category is ranked, its sourcing shortlist.
+func (m Model) renderCategoryOverlay(width, height int) string {
+	batch := m.browse.Batch
+	hint := "[j/k] select  [enter] open  [esc] close"
+	if m.browse.Category != "" {
+		hint = fmt.Sprintf("[enter] search  [R] rank  [s] by %s  [esc] back", m.browse.Order.Label())
+	}
+	if batch.Running {
+		hint = strings.Replace(hint, "[R] rank", "[R] stop", 1)
+		if m.browse.Category == "" {
+			hint += "  [R] stop"
+		}
+		hint = fmt.Sprintf("ranking %s %d/%d…  ", batch.Category, len(batch.Rows), len(batch.Products)) + hint
+	}
+
+	var header string
+	var rows []string
+	switch ranking := m.categoryRanking(); {
+	case m.browse.Category == "":
+		categories := m.productIndex.Categories()
+		if len(categories) == 0 {
+			return emptyStyle.Render("~ The catalog is empty ~")
+		}
+		for _, category := range categories {
+			rows = append(rows, fmt.Sprintf("%-*s %s",
+				max(8, width-22), truncate(sanitizeDisplayText(category.Name), max(8, width-22)),
+				mutedStyle.Render(fmt.Sprintf("%d products", category.Products))))
+		}
+	case len(ranking) > 0:
+		const (
+			colPrice = 9
+			colSell  = 6
+			colNet   = 10
+		)
+		nameWidth := max(8, width-(2*colPrice+colSell+colNet+12))
+		header = fmt.Sprintf("   %-*s %*s %*s %*s %*s", nameWidth, "Product", colPrice, "Median", colPrice, "Buy@P25", colSell, "Sold/A", colNet, "Net")
+		for _, row := range ranking {
+			name := truncate(sanitizeDisplayText(row.Product), nameWidth)
+			if row.Listings == 0 {
+				note := "no listings"
+				if row.Err != nil {
+					note = "search failed"
+				}
+				rows = append(rows, fmt.Sprintf("%-*s %s", nameWidth, name, mutedStyle.Render(note)))
+				continue
+			}
+			net := fmt.Sprintf("%*s", colNet, fmt.Sprintf("%+.2f", row.NetProfit))
+			if row.NetProfit >= 0 {
+				net = successStyle.Render(net)
+			} else {
+				net = dangerStyle.Render(net)
+			}
+			rows = append(rows, fmt.Sprintf("%-*s %*s %*s %*s %s",
+				nameWidth, name,
+				colPrice, fmt.Sprintf("$%.2f", row.Median),
+				colPrice, fmt.Sprintf("$%.2f", row.BuyAt),
+				colSell, fmt.Sprintf("%.1f", row.SellThrough()),
+				net,
+			))
+		}
+	default:
+		for _, product := range m.productIndex.CategoryProducts(m.browse.Category) {
+			msrp := ""
+			if product.MSRP > 0 {
+				msrp = mutedStyle.Render(fmt.Sprintf("MSRP $%.2f", product.MSRP))
+			}
+			rows = append(rows, fmt.Sprintf("%-*s %s", max(8, width-24), truncate(sanitizeDisplayText(product.Name), max(8, width-24)), msrp))
+		}
+	}
+
+	lines := make([]string, 0, height)
+	if header != "" {
+		lines = append(lines, labelStyle.Render(header))
 	}
+	listRows := max(1, height-len(lines)-2)
+	index := min(m.browse.Index, len(rows)-1)
+	start := 0
+	if index >= listRows {
+		start = index - listRows + 1
+	}
+	for i := start; i < min(len(rows), start+listRows); i++ {
+		if i == index {
+			lines = append(lines, selectedStyle.Render("▸ "+rows[i]))
+		} else {
+			lines = append(lines, rowStyle.Render("   "+rows[i]))
+		}
+	}
+	lines = append(lines, mutedStyle.Render(hint))
 	return strings.Join(lines, "\n")
 }
 
+// currentProduct returns the catalog product the last search was for.
+func (m Model) currentProduct() (api.ProductEntry, bool) {
+	return m.productIndex.Product(m.lastExpansion.Expanded)
+}
+
+// retailProduct returns the searched product when it has an MSRP that
+// listing prices can be compared with, which needs the market to price
+// listings in the MSRP's currency.
+func (m Model) retailProduct() (api.ProductEntry, bool) {
+	product, ok := m.currentProduct()
+	if !ok || product.MSRP <= 0 || m.market.Currency != api.MSRPCurrency {
+		return api.ProductEntry{}, false
+	}
+	return product, true
+}
+
+// feeClass is the fee class of the searched product, standard when the
+// search was not for a catalog product.
+func (m Model) feeClass() types.FeeClass {
+	product, ok := m.currentProduct()
+	if !ok {
+		return types.FeeClassStandard
+	}
+	return product.Fees()
+}
+
+// implausiblePrice reports whether listing is priced outside the range the
+// searched product plausibly sells for, which usually means a misparse.
+func (m Model) implausiblePrice(listing types.Listing) bool {
+	product, ok := m.retailProduct()
+	if !ok {
+		return false
+	}
+	low, high, ok := product.PlausiblePriceRange()
+	if !ok {
+		return false
+	}
+	price := listing.UnitPrice()
+	return price > 0 && (price < low || price > high)
+}
+
+func (m Model) implausibleCount() int {
+	count := 0
+	for _, listing := range m.results {
+		if m.implausiblePrice(listing) {
+			count++
+		}
+	}
+	return count
+}
+
 func (m Model) bestNetPlatform(cost, sell float64) (string, float64) {
-	platforms := []string{"eBay", "Mercari", "Amazon", "Facebook"}
+	return m.bestNetPlatformFor(m.feeClass(), cost, sell)
+}
+
+// bestNetPlatformFor returns the platform of the market netting the most
+// from selling a product of fee class class at sell after buying at cost.
+func (m Model) bestNetPlatformFor(class types.FeeClass, cost, sell float64) (string, float64) {
+	platforms := m.calcPlatforms()
 	bestPlatform := platforms[0]
-	bestNet, _, _ := types.CalculateNetProfit(cost, sell, bestPlatform)
+	bestNet, _, _ := types.CalculateNetProfitFor(m.market.Code, class, cost, sell, bestPlatform)
 	for _, platform := range platforms[1:] {
-		net, _, _ := types.CalculateNetProfit(cost, sell, platform)
+		net, _, _ := types.CalculateNetProfitFor(m.market.Code, class, cost, sell, platform)
 		if net > bestNet {
 			bestNet = net
 			bestPlatform = platform